@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// labelableControls are the form controls a label can be associated with
+var labelableControls = map[string]bool{"input": true, "select": true, "textarea": true, "meter": true, "output": true, "progress": true}
+
+// fixAccessibility applies safe accessibility fixes, reporting each of them:
+// decorative images get an empty alt, buttons in forms get their implied
+// submit type made explicit and labels are associated with the control that follows them
+func (c *Converter) fixAccessibility(nodes []*html.Node) {
+	if !c.opts.A11yFix {
+		return
+	}
+	var decorative selector
+	if c.opts.DecorativeImages != "" {
+		sel, err := parseSelector(c.opts.DecorativeImages)
+		if err != nil {
+			c.report(nil, SeverityWarning, "a11y-fix-invalid", "decorative image selector: %v", err)
+		}
+		decorative = sel
+	}
+
+	ids := make(map[string]bool)
+	forEachElement(nodes, func(n *html.Node) {
+		if id := attrValue(n, "id"); id != "" {
+			ids[id] = true
+		}
+	})
+
+	forEachElement(nodes, func(n *html.Node) {
+		switch n.Data {
+		case "img":
+			if hasAttr(n, "alt") {
+				return
+			}
+			if attrValue(n, "role") == "presentation" || attrValue(n, "aria-hidden") == "true" || (decorative != nil && decorative.match(n)) {
+				setAttr(n, "alt", "")
+				c.report(n, SeverityInfo, "a11y-fix", `added alt="" to a decorative image`)
+			}
+		case "button":
+			if hasAttr(n, "type") || !insideForm(n) {
+				return
+			}
+			// A button driven by a script may not be meant to submit, but it
+			// does today, so it is left for the author to decide
+			if hasScriptBehaviour(n) {
+				c.report(n, SeverityWarning, "a11y-button-type", `button inside a form has script behaviour and submits the form; add type="button" if it should not`)
+				return
+			}
+			setAttr(n, "type", "submit")
+			c.report(n, SeverityInfo, "a11y-fix", `added type="submit" to a button inside a form`)
+		case "label":
+			if hasAttr(n, "for") || containsControl(n) {
+				return
+			}
+			control := nextElementSibling(n)
+			if control == nil || !labelableControls[control.Data] || attrValue(control, "type") == "hidden" {
+				return
+			}
+			id := attrValue(control, "id")
+			if id == "" {
+				id = uniqueID(ids, controlIDBase(n, control))
+				setAttr(control, "id", id)
+			}
+			setAttr(n, "for", id)
+			c.report(n, SeverityInfo, "a11y-fix", "associated the label with the %s that follows it through id %q", control.Data, id)
+		}
+	})
+}
+
+// setAttr sets an attribute, adding it when missing
+func setAttr(n *html.Node, key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// insideForm reports whether n has a form ancestor or a form attribute
+func insideForm(n *html.Node) bool {
+	if hasAttr(n, "form") {
+		return true
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "form" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasScriptBehaviour reports whether an element has inline, Alpine or htmx behaviour of its own
+func hasScriptBehaviour(n *html.Node) bool {
+	for _, attr := range n.Attr {
+		if isEventHandlerAttr(attr.Key) || strings.HasPrefix(attr.Key, "@") || strings.HasPrefix(attr.Key, "x-on:") ||
+			strings.HasPrefix(attr.Key, "hx-") || attr.Key == "aria-controls" || attr.Key == "aria-expanded" {
+			return true
+		}
+	}
+	return false
+}
+
+// containsControl reports whether a label wraps its control
+func containsControl(n *html.Node) bool {
+	found := false
+	forEachElement([]*html.Node{n}, func(el *html.Node) {
+		found = found || labelableControls[el.Data]
+	})
+	return found
+}
+
+// nextElementSibling returns the next sibling element, skipping text and comments
+func nextElementSibling(n *html.Node) *html.Node {
+	for s := n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+// controlIDBase derives an id for a control from its name or its label
+func controlIDBase(label, control *html.Node) string {
+	base := attrValue(control, "name")
+	if base == "" {
+		base = textContent(label)
+	}
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(base), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		return "field"
+	}
+	return strings.Join(words, "-")
+}
+
+// uniqueID returns base, suffixed with a counter if the document already uses it
+func uniqueID(ids map[string]bool, base string) string {
+	id := base
+	for i := 2; ids[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	ids[id] = true
+	return id
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// checkDetails reports details elements whose summary is missing or misplaced,
+// and summary elements outside of details
+func (c *Converter) checkDetails(nodes []*html.Node) {
+	forEachElement(nodes, func(n *html.Node) {
+		switch n.Data {
+		case "details":
+			first := firstElementChild(n)
+			if first == nil || first.Data != "summary" {
+				if summary := childElement(n, "summary"); summary != nil {
+					c.report(summary, SeverityWarning, "summary-position", "summary must be the first child of details")
+				} else {
+					c.report(n, SeverityInfo, "summary-missing", "details has no summary; browsers show a default label")
+				}
+			}
+		case "summary":
+			if n.Parent == nil || n.Parent.Type != html.ElementNode || n.Parent.Data != "details" {
+				c.report(n, SeverityWarning, "summary-orphan", "summary outside of details is rendered as a plain element")
+			}
+		}
+	})
+}
+
+// firstElementChild returns the first element child of n
+func firstElementChild(n *html.Node) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			return child
+		}
+	}
+	return nil
+}
+
+// childElement returns the first element child of n with the given tag
+func childElement(n *html.Node, tag string) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == tag {
+			return child
+		}
+	}
+	return nil
+}
+
+// findAccordions groups runs of at least two sibling details elements that
+// only differ in their summary text, content and open state. In parameterize
+// mode each group is converted into a call to a shared Accordion helper.
+func (c *Converter) findAccordions(nodes []*html.Node) {
+	c.accordions = make(map[*html.Node][]*html.Node)
+	c.accordionMembers = make(map[*html.Node]bool)
+	if !c.opts.Parameterize {
+		return
+	}
+
+	group := func(siblings []*html.Node) {
+		var run []*html.Node
+		flush := func() {
+			if len(run) >= 2 {
+				c.accordions[run[0]] = run
+				for _, n := range run[1:] {
+					c.accordionMembers[n] = true
+				}
+			}
+			run = nil
+		}
+		for _, n := range siblings {
+			switch {
+			case n.Type == html.TextNode && strings.TrimSpace(n.Data) == "":
+			case isAccordionItem(n):
+				run = append(run, n)
+			default:
+				flush()
+			}
+		}
+		flush()
+	}
+
+	group(nodes)
+	forEachElement(nodes, func(n *html.Node) {
+		var children []*html.Node
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			children = append(children, child)
+		}
+		group(children)
+	})
+}
+
+// isAccordionItem reports whether n is a details element with a text summary
+// and no attributes other than open
+func isAccordionItem(n *html.Node) bool {
+	if n.Type != html.ElementNode || n.Data != "details" {
+		return false
+	}
+	for _, attr := range n.Attr {
+		if attr.Key != "open" {
+			return false
+		}
+	}
+	summary := firstElementChild(n)
+	if summary == nil || summary.Data != "summary" || len(summary.Attr) > 0 {
+		return false
+	}
+	for child := summary.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.TextNode {
+			return false
+		}
+	}
+	return strings.TrimSpace(textContent(summary)) != ""
+}
+
+// accordionCall converts a group of details elements into a call to the shared Accordion helper
+func (c *Converter) accordionCall(group []*html.Node, depth int) string {
+	if c.accordionFunc == nil {
+		name := c.uniqueFuncName("Accordion")
+		c.accordionFunc = &funcDecl{name: name, result: "Node", raw: accordionHelper(name)}
+		c.accordionFunc.addParam("items", "[]"+name+"Item")
+		c.funcs = append(c.funcs, c.accordionFunc)
+	}
+	itemType := c.accordionFunc.name + "Item"
+
+	indent := strings.Repeat("\t", depth+1)
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s([]%s{\n", c.accordionFunc.name, itemType)
+	for _, n := range group {
+		summary := firstElementChild(n)
+		var body []*html.Node
+		for child := summary.NextSibling; child != nil; child = child.NextSibling {
+			body = append(body, child)
+		}
+		codes := c.convertNodeList(body, depth+2)
+
+		fields := []string{"Summary: " + c.quoteValue(strings.TrimSpace(textContent(summary)))}
+		switch len(codes) {
+		case 0:
+			fields = append(fields, "Body: Fragment()")
+		case 1:
+			fields = append(fields, "Body: "+codes[0])
+		default:
+			fields = append(fields, "Body: Fragment("+strings.Join(codes, ", ")+")")
+		}
+		if hasAttr(n, "open") {
+			fields = append(fields, "Open: true")
+		}
+		fmt.Fprintf(&buf, "%s{%s},\n", indent, strings.Join(fields, ", "))
+	}
+	fmt.Fprintf(&buf, "%s})", strings.Repeat("\t", depth))
+	return buf.String()
+}
+
+// accordionHelper returns the declarations of the Accordion helper and its item type
+func accordionHelper(name string) string {
+	return fmt.Sprintf(`// %[1]sItem is an entry of %[1]s
+type %[1]sItem struct {
+	Summary string
+	Body    Node
+	Open    bool
+}
+
+func %[1]s(items []%[1]sItem) Node {
+	nodes := make([]Node, 0, len(items))
+	for _, item := range items {
+		if item.Open {
+			nodes = append(nodes, Details(Open(), Summary(T(item.Summary)), item.Body))
+			continue
+		}
+		nodes = append(nodes, Details(Summary(T(item.Summary)), item.Body))
+	}
+	return Fragment(nodes...)
+}
+`, name)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// alpineDataPattern matches an x-data value naming a component registered
+// with Alpine.data(), called or not: dropdown, dropdown() or dropdown(true)
+var alpineDataPattern = regexp.MustCompile(`(?s)^([A-Za-z_$][\w$]*)(?:\s*\((.*)\))?$`)
+
+// parseAlpineData returns the component an x-data value refers to and the
+// script arguments passed to it. Object literals, empty values and
+// expressions other than a single call are no component reference.
+func parseAlpineData(val string) (name string, args []string, ok bool) {
+	m := alpineDataPattern.FindStringSubmatch(strings.TrimSpace(val))
+	if m == nil || checkJS(m[0]) != nil {
+		return "", nil, false
+	}
+	if strings.TrimSpace(m[2]) == "" {
+		return m[1], nil, true
+	}
+	// The arguments are nil when the call closes before the end, as in
+	// dropdown(a) || other(b)
+	parts := splitTopLevel(m[2], ',')
+	if parts == nil {
+		return "", nil, false
+	}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", nil, false
+		}
+		args = append(args, part)
+	}
+	return m[1], args, true
+}
+
+// dataValue converts an x-data value with Options.AlpineData: a reference to
+// a registered component becomes a call of the alpineData helper, keeping it
+// apart from inline state objects
+func (c *Converter) dataValue(val string) string {
+	if !c.opts.AlpineData {
+		return c.quoteValue(val)
+	}
+	name, args, ok := parseAlpineData(val)
+	if !ok {
+		return c.quoteValue(val)
+	}
+
+	if c.alpineDataFunc == nil {
+		helper := c.uniqueFuncName("alpineData")
+		c.alpineDataFunc = &funcDecl{name: helper, result: "string", raw: alpineDataHelper(helper)}
+		c.funcs = append(c.funcs, c.alpineDataFunc)
+		c.stdImports["strings"] = true
+	}
+	callArgs := []string{c.quoteValue(name)}
+	for _, arg := range args {
+		callArgs = append(callArgs, c.quoteValue(arg))
+	}
+	return fmt.Sprintf("%s(%s)", c.alpineDataFunc.name, strings.Join(callArgs, ", "))
+}
+
+// alpineDataHelper returns the declaration of the helper referring to a
+// component registered with Alpine.data()
+func alpineDataHelper(name string) string {
+	return fmt.Sprintf(`// %[1]s refers to the Alpine component registered as name with
+// Alpine.data(), passing it the script expressions args
+func %[1]s(name string, args ...string) string {
+	if len(args) == 0 {
+		return name
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+`, name)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// alpineStorePattern matches $store.name and $store['name']
+	alpineStorePattern = regexp.MustCompile(`\$store(?:\.([A-Za-z_$][\w$]*)|\[\s*['"]([^'"]+)['"]\s*\])`)
+	// alpineDispatchPattern matches $dispatch('name') with a literal name
+	alpineDispatchPattern = regexp.MustCompile("\\$dispatch\\(\\s*(?:'([^']+)'|\"([^\"]+)\"|`([^`$]+)`)")
+	// alpineRefPattern matches $refs.name and $refs['name']
+	alpineRefPattern = regexp.MustCompile(`\$refs(?:\.([A-Za-z_$][\w$]*)|\[\s*['"]([^'"]+)['"]\s*\])`)
+)
+
+// AlpineUsage is the client state an input depends on through its Alpine
+// expressions, each list sorted
+type AlpineUsage struct {
+	// Stores are the global stores read or written through $store
+	Stores []string `json:"stores,omitempty"`
+	// Dispatched are the events sent with $dispatch, and Listened the
+	// events handled with @ or x-on: attributes
+	Dispatched []string `json:"dispatched,omitempty"`
+	Listened   []string `json:"listened,omitempty"`
+	// Refs are the elements named with x-ref, and RefUses the names used
+	// through $refs
+	Refs    []string `json:"refs,omitempty"`
+	RefUses []string `json:"refUses,omitempty"`
+	// Components are the components registered with Alpine.data() that
+	// x-data attributes refer to by name
+	Components []string `json:"components,omitempty"`
+}
+
+// AlpineUsage returns the stores, events, refs and components used by the
+// last converted input
+func (c *Converter) AlpineUsage() AlpineUsage {
+	return c.alpineUsage
+}
+
+// isAlpineAttr reports whether an attribute holds an Alpine expression
+func isAlpineAttr(key string) bool {
+	return strings.HasPrefix(key, "x-") || strings.HasPrefix(key, "@") || strings.HasPrefix(key, ":")
+}
+
+// alpineEventName returns the event an @ or x-on: attribute listens to,
+// without its modifiers
+func alpineEventName(key string) string {
+	var event string
+	switch {
+	case strings.HasPrefix(key, "@"):
+		event = key[1:]
+	case strings.HasPrefix(key, "x-on:"):
+		event = strings.TrimPrefix(key, "x-on:")
+	default:
+		return ""
+	}
+	if i := strings.Index(event, "."); i >= 0 {
+		event = event[:i]
+	}
+	return event
+}
+
+// patternNames returns the names matched by the groups of a pattern in s
+func patternNames(re *regexp.Regexp, s string) []string {
+	var names []string
+	for _, m := range re.FindAllStringSubmatch(s, -1) {
+		for _, name := range m[1:] {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// collectAlpineUsage records the Alpine stores, events, refs and components
+// of the input for AlpineUsage. $refs names that no x-ref of the input declares are
+// reported, as the ref may belong to markup the input does not hold.
+func (c *Converter) collectAlpineUsage(nodes []*html.Node) {
+	stores := make(map[string]bool)
+	dispatched := make(map[string]bool)
+	listened := make(map[string]bool)
+	refs := make(map[string]bool)
+	refUses := make(map[string]*html.Node)
+	components := make(map[string]bool)
+	forEachElement(nodes, func(n *html.Node) {
+		for _, attr := range n.Attr {
+			if !isAlpineAttr(attr.Key) {
+				continue
+			}
+			if event := alpineEventName(attr.Key); event != "" {
+				listened[event] = true
+			}
+			if attr.Key == "x-ref" {
+				if name := strings.TrimSpace(attr.Val); name != "" {
+					refs[name] = true
+				}
+				continue
+			}
+			if attr.Key == "x-data" {
+				if name, _, ok := parseAlpineData(attr.Val); ok {
+					components[name] = true
+				}
+			}
+			for _, name := range patternNames(alpineStorePattern, attr.Val) {
+				stores[name] = true
+			}
+			for _, name := range patternNames(alpineDispatchPattern, attr.Val) {
+				dispatched[name] = true
+			}
+			for _, name := range patternNames(alpineRefPattern, attr.Val) {
+				if refUses[name] == nil {
+					refUses[name] = n
+				}
+			}
+		}
+	})
+
+	for _, name := range sortedKeys(refUses) {
+		if !refs[name] {
+			c.report(refUses[name], SeverityInfo, "undeclared-ref", "$refs.%s has no x-ref=%q in this input", name, name)
+		}
+	}
+	c.alpineUsage = AlpineUsage{
+		Stores:     sortedKeys(stores),
+		Dispatched: sortedKeys(dispatched),
+		Listened:   sortedKeys(listened),
+		Refs:       sortedKeys(refs),
+		RefUses:    sortedKeys(refUses),
+		Components: sortedKeys(components),
+	}
+}
+
+// alpineStore is a store of the Alpine report with the inputs using it
+type alpineStore struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// alpineComponent is a component of the Alpine report with the inputs
+// expecting it to be registered
+type alpineComponent struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// alpineEvent is an event of the Alpine report with the inputs sending and
+// handling it
+type alpineEvent struct {
+	Name         string   `json:"name"`
+	DispatchedBy []string `json:"dispatchedBy,omitempty"`
+	ListenedBy   []string `json:"listenedBy,omitempty"`
+}
+
+// alpineRef is a ref of the Alpine report with the inputs naming and using it
+type alpineRef struct {
+	Name       string   `json:"name"`
+	DeclaredIn []string `json:"declaredIn,omitempty"`
+	UsedIn     []string `json:"usedIn,omitempty"`
+}
+
+// alpineReport is the client state contract of a batch: the stores, events,
+// refs and registered components its components depend on
+type alpineReport struct {
+	Stores     []alpineStore     `json:"stores"`
+	Events     []alpineEvent     `json:"events"`
+	Refs       []alpineRef       `json:"refs"`
+	Components []alpineComponent `json:"components"`
+}
+
+// buildAlpineReport gathers the Alpine usage of the converted components by
+// store, event, ref and registered component name. Events are listed when a component dispatches
+// them, leaving out the listeners of browser events, and refs when one is
+// used through $refs.
+func buildAlpineReport(components []convertedComponent) alpineReport {
+	stores := make(map[string]*alpineStore)
+	events := make(map[string]*alpineEvent)
+	refs := make(map[string]*alpineRef)
+	registered := make(map[string]*alpineComponent)
+	event := func(name string) *alpineEvent {
+		if events[name] == nil {
+			events[name] = &alpineEvent{Name: name}
+		}
+		return events[name]
+	}
+	ref := func(name string) *alpineRef {
+		if refs[name] == nil {
+			refs[name] = &alpineRef{Name: name}
+		}
+		return refs[name]
+	}
+
+	for _, comp := range components {
+		file := strings.ReplaceAll(comp.File, "\\", "/")
+		usage := comp.Alpine
+		for _, name := range usage.Stores {
+			if stores[name] == nil {
+				stores[name] = &alpineStore{Name: name}
+			}
+			stores[name].Files = append(stores[name].Files, file)
+		}
+		for _, name := range usage.Dispatched {
+			e := event(name)
+			e.DispatchedBy = append(e.DispatchedBy, file)
+		}
+		for _, name := range usage.Refs {
+			r := ref(name)
+			r.DeclaredIn = append(r.DeclaredIn, file)
+		}
+		for _, name := range usage.RefUses {
+			r := ref(name)
+			r.UsedIn = append(r.UsedIn, file)
+		}
+		for _, name := range usage.Components {
+			if registered[name] == nil {
+				registered[name] = &alpineComponent{Name: name}
+			}
+			registered[name].Files = append(registered[name].Files, file)
+		}
+	}
+	for _, comp := range components {
+		for _, name := range comp.Alpine.Listened {
+			if e := events[name]; e != nil {
+				e.ListenedBy = append(e.ListenedBy, strings.ReplaceAll(comp.File, "\\", "/"))
+			}
+		}
+	}
+
+	report := alpineReport{Stores: []alpineStore{}, Events: []alpineEvent{}, Refs: []alpineRef{}, Components: []alpineComponent{}}
+	for _, name := range sortedKeys(stores) {
+		report.Stores = append(report.Stores, *stores[name])
+	}
+	for _, name := range sortedKeys(events) {
+		report.Events = append(report.Events, *events[name])
+	}
+	for _, name := range sortedKeys(refs) {
+		r := *refs[name]
+		if len(r.UsedIn) > 0 {
+			report.Refs = append(report.Refs, r)
+		}
+	}
+	for _, name := range sortedKeys(registered) {
+		report.Components = append(report.Components, *registered[name])
+	}
+	return report
+}
+
+// writeAlpineReport writes the Alpine report of the converted components as
+// JSON
+func writeAlpineReport(filename string, components []convertedComponent) error {
+	data, err := json.MarshalIndent(buildAlpineReport(components), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode Alpine report: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write Alpine report: %w", err)
+	}
+	return nil
+}
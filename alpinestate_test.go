@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildAlpineReport(t *testing.T) {
+	pages := []struct{ file, input string }{
+		{"cart.html", `<div x-data="{}" @cart-updated.window="count = $store.cart.items.length"><div x-data="menu()"></div><form x-data="checkout({ step: 1 }, 'eur')"></form><input x-ref="qty"><button @click="$store['cart'].add($refs.qty.value); $dispatch('cart-updated')">Add</button></div>`},
+		{"header.html", `<header x-data="menu" @click.outside="$store.menu.open = false"><span x-text="$store.cart.items.length"></span><button @click="$dispatch(&quot;notify&quot;, {text: 'hi'}); $refs.panel.focus()">Menu</button></header>`},
+	}
+	var components []convertedComponent
+	var undeclared []Diagnostic
+	for _, page := range pages {
+		converter := NewConverterWithOptions(Options{Alpine: true})
+		if _, err := converter.Convert(page.input); err != nil {
+			t.Fatalf("Conversion of %s failed: %v", page.file, err)
+		}
+		for _, d := range converter.Diagnostics() {
+			if d.Code == "undeclared-ref" {
+				undeclared = append(undeclared, d)
+			}
+		}
+		components = append(components, convertedComponent{File: page.file, Alpine: converter.AlpineUsage()})
+	}
+
+	if len(undeclared) != 1 || undeclared[0].Message != `$refs.panel has no x-ref="panel" in this input` {
+		t.Errorf("Expected one undeclared-ref for panel, got %v", undeclared)
+	}
+	report := buildAlpineReport(components)
+	want := alpineReport{
+		Stores: []alpineStore{
+			{Name: "cart", Files: []string{"cart.html", "header.html"}},
+			{Name: "menu", Files: []string{"header.html"}},
+		},
+		Events: []alpineEvent{
+			{Name: "cart-updated", DispatchedBy: []string{"cart.html"}, ListenedBy: []string{"cart.html"}},
+			{Name: "notify", DispatchedBy: []string{"header.html"}},
+		},
+		Refs: []alpineRef{
+			{Name: "panel", UsedIn: []string{"header.html"}},
+			{Name: "qty", DeclaredIn: []string{"cart.html"}, UsedIn: []string{"cart.html"}},
+		},
+		Components: []alpineComponent{
+			{Name: "checkout", Files: []string{"cart.html"}},
+			{Name: "menu", Files: []string{"cart.html", "header.html"}},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Unexpected report:\n got %+v\nwant %+v", report, want)
+	}
+}
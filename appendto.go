@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// appendToFile merges generated code into the Go file at path, which need not exist yet
+func appendToFile(path, goCode string) (string, error) {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return goCode, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	// The generated file uses LF line endings whatever the existing file had
+	merged, err := mergeGoFiles(normalizeInput(string(existing)), goCode)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return merged, nil
+}
+
+// sourceEdit replaces the bytes between start and end of a file with text
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// mergeGoFiles adds the imports and declarations of generated to existing.
+// Declarations with the name of an existing one replace it, others are
+// appended, imports only the replaced code used are removed, and the result
+// is formatted with go/format.
+func mergeGoFiles(existing, generated string) (string, error) {
+	fset := token.NewFileSet()
+	dst, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse: %w", err)
+	}
+	src, err := parser.ParseFile(fset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse generated code: %w", err)
+	}
+
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	declRange := func(d ast.Decl) (int, int) {
+		start := d.Pos()
+		if doc := declDoc(d); doc != nil {
+			start = doc.Pos()
+		}
+		return offset(start), offset(d.End())
+	}
+
+	var edits []sourceEdit
+	if imports := missingImports(dst, src, generated, offset); len(imports) > 0 {
+		edits = append(edits, importEdit(dst, existing, imports, offset))
+	}
+
+	// Index the top-level names of the existing file
+	funcs := make(map[string]*ast.FuncDecl)
+	specs := make(map[string]*ast.GenDecl)
+	for _, d := range dst.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				funcs[d.Name.Name] = d
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				for _, name := range specNames(spec) {
+					specs[name] = d
+				}
+			}
+		}
+	}
+
+	var appended []string
+	replaced := make(map[*ast.GenDecl][]ast.Spec)
+	for _, d := range src.Decls {
+		start, end := declRange(d)
+		text := generated[start:end]
+
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if old, ok := funcs[d.Name.Name]; ok && d.Recv == nil {
+				oldStart, oldEnd := declRange(old)
+				if d.Doc == nil {
+					// Keep the documentation written for the existing function
+					oldStart = offset(old.Pos())
+				}
+				edits = append(edits, sourceEdit{start: oldStart, end: oldEnd, text: text})
+				continue
+			}
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				for _, name := range specNames(spec) {
+					if old, ok := specs[name]; ok {
+						replaced[old] = appendSpec(replaced[old], old, name)
+					}
+				}
+			}
+		}
+		appended = append(appended, text)
+	}
+
+	// Drop the existing declarations of names the generated code declares again
+	for old, removed := range replaced {
+		if len(removed) == len(old.Specs) {
+			start, end := declRange(old)
+			edits = append(edits, sourceEdit{start: start, end: end})
+			continue
+		}
+		for _, spec := range removed {
+			edits = append(edits, sourceEdit{start: offset(spec.Pos()), end: offset(spec.End())})
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	merged := existing
+	for _, e := range edits {
+		merged = merged[:e.start] + e.text + merged[e.end:]
+	}
+	if len(appended) > 0 {
+		merged = strings.TrimRight(merged, "\n") + "\n\n" + strings.Join(appended, "\n\n") + "\n"
+	}
+	if merged, err = pruneImports(merged, usedPackages(dst)); err != nil {
+		return "", err
+	}
+
+	formatted, err := format.Source([]byte(merged))
+	if err != nil {
+		return "", fmt.Errorf("failed to format merged code: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// declDoc returns the doc comment of a top-level declaration
+func declDoc(d ast.Decl) *ast.CommentGroup {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// specNames returns the names declared by a const, var or type spec
+func specNames(spec ast.Spec) []string {
+	switch s := spec.(type) {
+	case *ast.ValueSpec:
+		names := make([]string, len(s.Names))
+		for i, n := range s.Names {
+			names[i] = n.Name
+		}
+		return names
+	case *ast.TypeSpec:
+		return []string{s.Name.Name}
+	}
+	return nil
+}
+
+// appendSpec adds the spec of decl declaring name to specs, once
+func appendSpec(specs []ast.Spec, decl *ast.GenDecl, name string) []ast.Spec {
+	for _, spec := range decl.Specs {
+		if !containsString(specNames(spec), name) {
+			continue
+		}
+		for _, s := range specs {
+			if s == spec {
+				return specs
+			}
+		}
+		return append(specs, spec)
+	}
+	return specs
+}
+
+// missingImports returns the import specs of src, as source text, that dst lacks
+func missingImports(dst, src *ast.File, generated string, offset func(token.Pos) int) []string {
+	have := make(map[string]bool)
+	for _, imp := range dst.Imports {
+		have[importKey(imp)] = true
+	}
+
+	var missing []string
+	for _, imp := range src.Imports {
+		if !have[importKey(imp)] {
+			missing = append(missing, generated[offset(imp.Pos()):offset(imp.End())])
+		}
+	}
+	return missing
+}
+
+// usedPackages returns the names of the packages f refers to
+func usedPackages(f *ast.File) map[string]bool {
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// pruneImports removes the imports of code that were used before the merge,
+// according to usedBefore, and are no longer referenced. Imports the existing
+// file did not use by their assumed name are left alone, as their name may
+// differ from the one guessed from the path.
+func pruneImports(code string, usedBefore map[string]bool) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "merged.go", code, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse merged code: %w", err)
+	}
+	used := usedPackages(f)
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	var edits []sourceEdit
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var unused []ast.Spec
+		for _, spec := range gen.Specs {
+			name := specName(spec.(*ast.ImportSpec))
+			if usedBefore[name] && !used[name] {
+				unused = append(unused, spec)
+			}
+		}
+		if len(unused) == len(gen.Specs) {
+			edits = append(edits, sourceEdit{start: offset(gen.Pos()), end: offset(gen.End())})
+			continue
+		}
+		for _, spec := range unused {
+			start, end := offset(spec.Pos()), offset(spec.End())
+			// Take the whole line so no blank line splits the group
+			lineStart := strings.LastIndex(code[:start], "\n") + 1
+			lineEnd := len(code)
+			if i := strings.Index(code[end:], "\n"); i >= 0 {
+				lineEnd = end + i + 1
+			}
+			if strings.TrimSpace(code[lineStart:start]) == "" && strings.TrimSpace(code[end:lineEnd]) == "" {
+				start, end = lineStart, lineEnd
+			}
+			edits = append(edits, sourceEdit{start: start, end: end})
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		code = code[:e.start] + e.text + code[e.end:]
+	}
+	return code, nil
+}
+
+// specName returns the name an import spec is referred to by, as goimports
+// assumes it from the path when the import is not named. Blank and dot
+// imports have no name.
+func specName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		if imp.Name.Name == "_" || imp.Name.Name == "." {
+			return ""
+		}
+		return imp.Name.Name
+	}
+	p, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return ""
+	}
+	base := path.Base(p)
+	// Major version suffixes such as /v2 are not part of the name
+	if len(base) > 1 && base[0] == 'v' {
+		if _, err := strconv.Atoi(base[1:]); err == nil && path.Dir(p) != "." {
+			base = path.Base(path.Dir(p))
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// importKey identifies an import by its name and path
+func importKey(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name + " " + imp.Path.Value
+	}
+	return imp.Path.Value
+}
+
+// importEdit returns the edit adding imports to the first import declaration
+// of f, or a new declaration after the package clause
+func importEdit(f *ast.File, existing string, imports []string, offset func(token.Pos) int) sourceEdit {
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			pos := offset(gen.Rparen)
+			return sourceEdit{start: pos, end: pos, text: "\t" + strings.Join(imports, "\n\t") + "\n"}
+		}
+		// Turn a single import into a group
+		spec := existing[offset(gen.Specs[0].Pos()):offset(gen.Specs[0].End())]
+		imports = append([]string{spec}, imports...)
+		return sourceEdit{start: offset(gen.Pos()), end: offset(gen.End()), text: "import (\n\t" + strings.Join(imports, "\n\t") + "\n)"}
+	}
+	pos := offset(f.Name.End())
+	return sourceEdit{start: pos, end: pos, text: "\n\nimport (\n\t" + strings.Join(imports, "\n\t") + "\n)"}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeGoFiles(t *testing.T) {
+	existing := `package views
+
+import "fmt"
+
+// Card renders a card
+func Card() Node {
+	return Div(T(fmt.Sprint("old")))
+}
+
+const (
+	cardClass = "old"
+	keep      = "kept"
+)
+
+func Other() Node { return Span() }
+`
+	generated := `package main
+
+import (
+	. "github.com/plainkit/html"
+	"github.com/plainkit/htmx"
+)
+
+const cardClass = "card"
+
+func Card() Node {
+	return Div(Class(cardClass), Button(htmx.HxGet("/x")))
+}
+
+func Footer() Node {
+	return P(T("Hi"))
+}
+`
+	merged, err := mergeGoFiles(existing, generated)
+	if err != nil {
+		t.Fatalf("mergeGoFiles failed: %v", err)
+	}
+
+	expected := []string{
+		"package views",
+		"import (\n\t. \"github.com/plainkit/html\"\n\t\"github.com/plainkit/htmx\"\n)",
+		"// Card renders a card\nfunc Card() Node {\n\treturn Div(Class(cardClass), Button(htmx.HxGet(\"/x\")))\n}",
+		`keep = "kept"`,
+		"func Other() Node { return Span() }",
+		"const cardClass = \"card\"\n\nfunc Footer() Node {",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(merged, exp) {
+			t.Errorf("Expected merged file to contain %q.\nOutput:\n%s", exp, merged)
+		}
+	}
+	// Only the replaced Card used fmt
+	if strings.Contains(merged, `"fmt"`) {
+		t.Errorf("Expected the unused fmt import to be removed.\nOutput:\n%s", merged)
+	}
+	if strings.Contains(merged, `"old"`) || strings.Count(merged, "func Card()") != 1 {
+		t.Errorf("Expected the old Card and cardClass to be replaced.\nOutput:\n%s", merged)
+	}
+
+	if _, err := mergeGoFiles("package views\n\nfunc {", generated); err == nil {
+		t.Error("Expected an error for an unparsable file")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// designArtifactPrefixes are attribute prefixes left behind by design tool and site builder exports
+var designArtifactPrefixes = []string{
+	"data-wf-",     // Webflow page and site ids
+	"wf-",          // Webflow runtime hooks
+	"data-w-id",    // Webflow interactions
+	"data-figma-",  // Figma exports
+	"data-framer-", // Framer exports
+	"data-node-id", // Figma/Anima node references
+	"data-anima-",  // Anima exports
+}
+
+// isDesignArtifact reports whether an attribute was added by a design tool export
+func isDesignArtifact(key string) bool {
+	for _, prefix := range designArtifactPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripsArtifacts reports whether design tool attributes are removed, either
+// with Options.StripDesignArtifacts or by the cleanup profile
+func (c *Converter) stripsArtifacts() bool {
+	return c.opts.StripDesignArtifacts || c.profile != nil && c.profile.stripArtifacts
+}
+
+// stripDesignArtifacts removes design tool attributes that are not mapped in
+// the config, or just reports them when stripping is disabled
+func (c *Converter) stripDesignArtifacts(nodes []*html.Node) {
+	found := make(map[string]int)
+	forEachElement(nodes, func(n *html.Node) {
+		kept := n.Attr[:0]
+		for _, attr := range n.Attr {
+			_, mapped := c.opts.DataAttributes[attr.Key]
+			if !isDesignArtifact(attr.Key) || mapped {
+				kept = append(kept, attr)
+				continue
+			}
+			found[attr.Key]++
+			if !c.stripsArtifacts() {
+				kept = append(kept, attr)
+			}
+		}
+		n.Attr = kept
+	})
+	if len(found) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(found))
+	total := 0
+	for key, count := range found {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Strings(keys)
+
+	if c.stripsArtifacts() {
+		c.report(nil, SeverityInfo, "design-artifact", "stripped %d design tool attributes: %s", total, strings.Join(keys, ", "))
+	} else {
+		c.report(nil, SeverityWarning, "design-artifact", "found %d design tool attributes (%s); use --strip-design-artifacts or map them in the config", total, strings.Join(keys, ", "))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+// attributeFuncs maps standard attributes to the Plain function taking their
+// value. data-* and aria-* attributes are converted with Data and Aria, and
+// other attributes with Custom.
+var attributeFuncs = map[string]string{
+	"class":        "Class",
+	"id":           "Id",
+	"style":        "Style",
+	"href":         "Href",
+	"src":          "Src",
+	"type":         "Type",
+	"value":        "Value",
+	"name":         "Name",
+	"placeholder":  "Placeholder",
+	"charset":      "Charset",
+	"content":      "Content",
+	"method":       "Method",
+	"action":       "Action",
+	"target":       "Target",
+	"rel":          "Rel",
+	"alt":          "Alt",
+	"title":        "Title",
+	"width":        "Width",
+	"height":       "Height",
+	"colspan":      "ColSpan",
+	"rowspan":      "RowSpan",
+	"for":          "For",
+	"maxlength":    "MaxLength",
+	"minlength":    "MinLength",
+	"min":          "Min",
+	"max":          "Max",
+	"step":         "Step",
+	"pattern":      "Pattern",
+	"rows":         "Rows",
+	"cols":         "Cols",
+	"autocomplete": "AutoComplete",
+	"role":         "Role",
+	"tabindex":     "TabIndex",
+}
+
+// elementAttributeFuncs maps attributes whose function depends on the element
+// they are set on, by attribute and then element
+var elementAttributeFuncs = map[string]map[string]string{
+	"src":   {"script": "ScriptSrc"},
+	"type":  {"input": "InputType", "button": "ButtonType"},
+	"value": {"input": "InputValue"},
+	"name":  {"input": "InputName"},
+}
+
+// booleanAttributeFuncs maps boolean attributes to their Plain functions,
+// which take no argument
+var booleanAttributeFuncs = map[string]string{
+	"disabled":  "Disabled",
+	"checked":   "Checked",
+	"readonly":  "ReadOnly",
+	"required":  "Required",
+	"multiple":  "Multiple",
+	"selected":  "Selected",
+	"defer":     "Defer",
+	"async":     "Async",
+	"autofocus": "Autofocus",
+	"open":      "Open",
+}
+
+// attributeFunc returns the Plain function taking the value of an attribute
+// on the given element, or "" when it has none
+func attributeFunc(key, tagName string) string {
+	if funcName, ok := elementAttributeFuncs[key][tagName]; ok {
+		return funcName
+	}
+	return attributeFuncs[key]
+}
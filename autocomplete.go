@@ -0,0 +1,124 @@
+package main
+
+import "strings"
+
+// autofillFields are the WHATWG autofill field names
+var autofillFields = map[string]bool{
+	"name": true, "honorific-prefix": true, "given-name": true, "additional-name": true,
+	"family-name": true, "honorific-suffix": true, "nickname": true, "username": true,
+	"new-password": true, "current-password": true, "one-time-code": true,
+	"organization-title": true, "organization": true, "street-address": true,
+	"address-line1": true, "address-line2": true, "address-line3": true,
+	"address-level4": true, "address-level3": true, "address-level2": true, "address-level1": true,
+	"country": true, "country-name": true, "postal-code": true,
+	"cc-name": true, "cc-given-name": true, "cc-additional-name": true, "cc-family-name": true,
+	"cc-number": true, "cc-exp": true, "cc-exp-month": true, "cc-exp-year": true,
+	"cc-csc": true, "cc-type": true, "transaction-currency": true, "transaction-amount": true,
+	"language": true, "bday": true, "bday-day": true, "bday-month": true, "bday-year": true,
+	"sex": true, "url": true, "photo": true,
+}
+
+// autofillContactFields are the field names that accept a home/work/mobile/fax/pager hint
+var autofillContactFields = map[string]bool{
+	"tel": true, "tel-country-code": true, "tel-national": true, "tel-area-code": true,
+	"tel-local": true, "tel-local-prefix": true, "tel-local-suffix": true, "tel-extension": true,
+	"email": true, "impp": true,
+}
+
+// autofillContactHints are the hints allowed before a contact field name
+var autofillContactHints = map[string]bool{
+	"home": true, "work": true, "mobile": true, "fax": true, "pager": true,
+}
+
+// validateAutocomplete checks an autocomplete value against the WHATWG grammar
+// and returns a description of the problem, or "" if it is valid
+func validateAutocomplete(tag, val string) string {
+	tokens := strings.Fields(strings.ToLower(val))
+	if len(tokens) == 0 {
+		return ""
+	}
+	if tag == "form" || len(tokens) == 1 && (tokens[0] == "on" || tokens[0] == "off") {
+		if len(tokens) != 1 || tokens[0] != "on" && tokens[0] != "off" {
+			return `must be "on" or "off" on ` + tag
+		}
+		return ""
+	}
+
+	if tokens[len(tokens)-1] == "webauthn" {
+		tokens = tokens[:len(tokens)-1]
+		if len(tokens) == 0 {
+			return `"webauthn" must follow a field name`
+		}
+	}
+	if strings.HasPrefix(tokens[0], "section-") {
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && (tokens[0] == "shipping" || tokens[0] == "billing") {
+		tokens = tokens[1:]
+	}
+	contactHint := ""
+	if len(tokens) > 0 && autofillContactHints[tokens[0]] {
+		contactHint = tokens[0]
+		tokens = tokens[1:]
+	}
+
+	switch {
+	case len(tokens) == 0:
+		return "is missing a field name"
+	case len(tokens) > 1:
+		return "has unexpected tokens " + strings.Join(tokens, " ")
+	}
+	field := tokens[0]
+	switch {
+	case autofillContactFields[field]:
+		return ""
+	case autofillFields[field]:
+		if contactHint != "" {
+			return `"` + contactHint + `" can only be used with contact fields such as tel and email`
+		}
+		return ""
+	}
+
+	msg := `has unknown field name "` + field + `"`
+	if suggestion := closestAutofillField(field); suggestion != "" {
+		msg += `; did you mean "` + suggestion + `"?`
+	}
+	return msg
+}
+
+// closestAutofillField returns the field name closest to a misspelled one, if any is close enough
+func closestAutofillField(field string) string {
+	best, bestDistance := "", 3
+	consider := func(candidates map[string]bool) {
+		for candidate := range candidates {
+			d := editDistance(field, candidate)
+			if d < bestDistance || d == bestDistance && best != "" && candidate < best {
+				best, bestDistance = candidate, d
+			}
+		}
+	}
+	consider(autofillFields)
+	consider(autofillContactFields)
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// baselineEntry is a diagnostic recorded in a baseline file. Lines are left
+// out so that edits elsewhere in an input don't invalidate its entries.
+type baselineEntry struct {
+	File     string   `json:"file"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Node     string   `json:"node,omitempty"`
+}
+
+// diagnosticBaseline holds the known diagnostics of a codebase, counting
+// identical findings so that each entry suppresses one of them
+type diagnosticBaseline struct {
+	remaining map[baselineEntry]int
+}
+
+// newBaselineEntry returns the baseline entry matching a diagnostic
+func newBaselineEntry(d fileDiagnostic) baselineEntry {
+	return baselineEntry{
+		File:     filepath.ToSlash(d.File),
+		Severity: d.Severity,
+		Code:     d.Code,
+		Message:  d.Message,
+		Node:     d.Node,
+	}
+}
+
+// loadBaseline reads a baseline file, returning nil if it doesn't exist yet
+func loadBaseline(path string) (*diagnosticBaseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var file struct {
+		Diagnostics []baselineEntry `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	b := &diagnosticBaseline{remaining: make(map[baselineEntry]int)}
+	for _, e := range file.Diagnostics {
+		b.remaining[e]++
+	}
+	return b, nil
+}
+
+// known reports whether the diagnostic is recorded in the baseline, using up
+// the matching entry
+func (b *diagnosticBaseline) known(d fileDiagnostic) bool {
+	if b == nil {
+		return false
+	}
+	e := newBaselineEntry(d)
+	if b.remaining[e] == 0 {
+		return false
+	}
+	b.remaining[e]--
+	return true
+}
+
+// writeBaseline records diagnostics as the baseline, sorted so that the file
+// diffs cleanly when it is updated
+func writeBaseline(path string, diags []fileDiagnostic) error {
+	entries := make([]baselineEntry, 0, len(diags))
+	for _, d := range diags {
+		entries = append(entries, newBaselineEntry(d))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		if a.Node != b.Node {
+			return a.Node < b.Node
+		}
+		return a.Message < b.Message
+	})
+
+	// Node paths keep their > instead of \u003e so that the file reads well in reviews
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Diagnostics []baselineEntry `json:"diagnostics"`
+	}{entries}); err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// newFindings counts the warnings and errors that are not in the baseline
+func newFindings(diags []fileDiagnostic) int {
+	count := 0
+	for _, d := range diags {
+		if d.Severity != SeverityInfo {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plainkit-baseline.json")
+	if b, err := loadBaseline(path); err != nil || b != nil {
+		t.Fatalf("Expected a missing baseline to load as nil, got %v, %v", b, err)
+	}
+
+	handler := fileDiagnostic{File: "pages/index.html", Diagnostic: Diagnostic{Severity: SeverityWarning, Code: "csp-inline-handler", Message: "inline onclick handler", Node: "body > div", Line: 4}}
+	if err := writeBaseline(path, []fileDiagnostic{handler, handler}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines are ignored, and each entry suppresses a single finding
+	moved := handler
+	moved.Line = 9
+	if !b.known(handler) || !b.known(moved) {
+		t.Error("Expected both recorded findings to be known")
+	}
+	if b.known(handler) {
+		t.Error("Expected a third identical finding to be new")
+	}
+	other := handler
+	other.File = "pages/about.html"
+	if b.known(other) {
+		t.Error("Expected a finding in another file to be new")
+	}
+
+	info := fileDiagnostic{File: "pages/index.html", Diagnostic: Diagnostic{Severity: SeverityInfo, Code: "mime-inlined"}}
+	if n := newFindings([]fileDiagnostic{handler, info}); n != 1 {
+		t.Errorf("Expected info diagnostics not to count as new findings, got %d", n)
+	}
+}
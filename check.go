@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// outputUpToDate reports whether the file at path already holds the generated
+// code. In semantic mode the functions of both are compared by the HTML they
+// render, which ignores formatting, quoting style, comments, declaration
+// order, imports and hoisted constants.
+func outputUpToDate(path, goCode string, semantic bool) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !semantic {
+		return string(existing) == goCode, nil
+	}
+
+	oldForm, err := canonicalGo(string(existing))
+	if err != nil {
+		// An unparsable file is never up to date
+		return false, nil
+	}
+	newForm, err := canonicalGo(goCode)
+	if err != nil {
+		return false, fmt.Errorf("generated code for %s does not parse: %w", path, err)
+	}
+	return oldForm == newForm, nil
+}
+
+// canonicalGo reduces generated Go source to a canonical form: the HTML each
+// function renders, or for functions doing more than returning Plain calls,
+// their normalised code
+func canonicalGo(src string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", src, 0)
+	if err != nil {
+		return "", err
+	}
+
+	canon := &canonicalizer{fset: fset, consts: make(map[string]string), funcs: newRenderFuncs()}
+	for _, imp := range file.Imports {
+		if imp.Path.Value == strconv.Quote(htmlImportPath) {
+			canon.qualifier = specName(imp)
+		}
+	}
+	var funcs []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i < len(vs.Values) {
+					if val, ok := canon.stringValue(vs.Values[i]); ok {
+						canon.consts[name.Name] = val
+					}
+				}
+			}
+		}
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if rendered, ok := canon.renderFunc(d); ok {
+				funcs = append(funcs, rendered)
+			} else {
+				funcs = append(funcs, canon.funcDecl(d))
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.CONST && d.Tok != token.IMPORT {
+				funcs = append(funcs, canon.node(d))
+			}
+		}
+	}
+	sort.Strings(funcs)
+	return "package " + file.Name.Name + "\n" + strings.Join(funcs, "\n"), nil
+}
+
+// canonicalizer renders expressions in a formatting-independent form
+type canonicalizer struct {
+	fset   *token.FileSet
+	consts map[string]string
+	funcs  *renderFuncs
+	// qualifier is the package name of plainkit/html when it is not dot-imported
+	qualifier string
+}
+
+// signature renders the name, parameters and result of a function
+func (c *canonicalizer) signature(d *ast.FuncDecl) string {
+	var params []string
+	for _, field := range d.Type.Params.List {
+		typ := c.typeName(field.Type)
+		for _, name := range field.Names {
+			params = append(params, name.Name+" "+typ)
+		}
+	}
+	var results []string
+	if d.Type.Results != nil {
+		for _, field := range d.Type.Results.List {
+			results = append(results, c.typeName(field.Type))
+		}
+	}
+	result := strings.Join(results, ", ")
+	if len(results) > 1 {
+		result = "(" + result + ")"
+	}
+	return fmt.Sprintf("func %s(%s) %s", d.Name.Name, strings.Join(params, ", "), result)
+}
+
+// typeName renders a type, leaving out the package name of plainkit/html
+func (c *canonicalizer) typeName(e ast.Expr) string {
+	name := c.node(e)
+	if c.qualifier != "" {
+		name = strings.ReplaceAll(name, c.qualifier+".", "")
+	}
+	return name
+}
+
+// funcDecl renders a function signature and the statements of its body
+func (c *canonicalizer) funcDecl(d *ast.FuncDecl) string {
+	var body []string
+	if d.Body != nil {
+		for _, stmt := range d.Body.List {
+			if ret, ok := stmt.(*ast.ReturnStmt); ok {
+				for _, r := range ret.Results {
+					body = append(body, "return "+c.expr(r))
+				}
+				continue
+			}
+			body = append(body, c.node(stmt))
+		}
+	}
+	return fmt.Sprintf("%s {%s}", c.signature(d), strings.Join(body, "; "))
+}
+
+// expr renders an expression, folding string constants to their values
+func (c *canonicalizer) expr(e ast.Expr) string {
+	if val, ok := c.stringValue(e); ok {
+		return strconv.Quote(val)
+	}
+	switch e := e.(type) {
+	case *ast.CallExpr:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = c.expr(arg)
+		}
+		if e.Ellipsis.IsValid() {
+			args[len(args)-1] += "..."
+		}
+		return c.expr(e.Fun) + "(" + strings.Join(args, ", ") + ")"
+	case *ast.CompositeLit:
+		elts := make([]string, len(e.Elts))
+		for i, elt := range e.Elts {
+			elts[i] = c.expr(elt)
+		}
+		return c.node(e.Type) + "{" + strings.Join(elts, ", ") + "}"
+	case *ast.ParenExpr:
+		return c.expr(e.X)
+	}
+	return c.node(e)
+}
+
+// stringValue evaluates an expression built from string literals, constants and +
+func (c *canonicalizer) stringValue(e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		return constant.StringVal(constant.MakeFromLiteral(e.Value, e.Kind, 0)), true
+	case *ast.Ident:
+		val, ok := c.consts[e.Name]
+		return val, ok
+	case *ast.ParenExpr:
+		return c.stringValue(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		left, ok := c.stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		right, ok := c.stringValue(e.Y)
+		if !ok {
+			return "", false
+		}
+		return left + right, true
+	}
+	return "", false
+}
+
+// node prints any other syntax node on a single line
+func (c *canonicalizer) node(n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, c.fset, n); err != nil {
+		return fmt.Sprintf("%T", n)
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
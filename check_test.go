@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputUpToDateSemantic(t *testing.T) {
+	generated := "package main\n\nimport (\n\t. \"github.com/plainkit/html\"\n)\n\nfunc Component(title string) Node {\n\treturn Div(Class(\"card\"), H1(T(title)), P(T(\"a `b`\")))\n}\n"
+	cosmetic := "package main\n\nimport . \"github.com/plainkit/html\"\n\nconst classCard = \"card\"\n\n// Component renders a card\nfunc Component(title string) Node {\n\treturn Div(\n\t\tClass(classCard),\n\t\tH1(T(title)),\n\t\tP(T(`a ` + \"`\" + `b` + \"`\")),\n\t)\n}\n"
+	// Renders the same markup through other calls and a qualified import
+	rendered := "package main\n\nimport html \"github.com/plainkit/html\"\n\nfunc Component(title string) html.Node {\n\treturn html.Element(\"div\", html.Class(\"card\"), html.Fragment(html.H1(html.T(title))), html.P(html.Raw(\"a `b`\")))\n}\n"
+	changed := "package main\n\nimport . \"github.com/plainkit/html\"\n\nfunc Component(title string) Node {\n\treturn Div(Class(\"card big\"), H1(T(title)))\n}\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		semantic bool
+		expected bool
+	}{
+		{"Identical", generated, false, true},
+		{"Cosmetic difference fails exact check", cosmetic, false, false},
+		{"Cosmetic difference passes semantic check", cosmetic, true, true},
+		{"Same markup passes semantic check", rendered, true, true},
+		{"Real change fails semantic check", changed, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "component.go")
+			if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+			upToDate, err := outputUpToDate(path, generated, tt.semantic)
+			if err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+			if upToDate != tt.expected {
+				t.Errorf("Expected up to date = %v, got %v", tt.expected, upToDate)
+			}
+		})
+	}
+}
+
+func TestCanonicalGo(t *testing.T) {
+	src := "package main\n\nimport . \"github.com/plainkit/html\"\n\nconst label = \"Go\"\n\n" +
+		"func Page(name string) Node {\n\treturn Div(Id(\"x\"), Title(\"tip\"), Input(Disabled()), Data(\"role\", name), Title(T(label+\" & more\")), Card(name))\n}\n\n" +
+		"func Card(name string) Node {\n\tnodes := []Node{T(name)}\n\treturn Fragment(nodes...)\n}\n"
+	canonical, err := canonicalGo(src)
+	if err != nil {
+		t.Fatalf("canonicalGo failed: %v", err)
+	}
+	for _, expected := range []string{
+		"func Page(name string) Node\n" +
+			`<div id="x" title="tip" data-role="{{name}}"><input disabled><title>Go &amp; more</title>{{Card({{name}})}}</div>`,
+		"func Card(name string) Node {nodes := []Node{T(name)}; return Fragment(nodes...)}",
+	} {
+		if !strings.Contains(canonical, expected) {
+			t.Errorf("Expected canonical form to contain %q.\nOutput:\n%s", expected, canonical)
+		}
+	}
+}
+
+func TestOutputUpToDateMissingFile(t *testing.T) {
+	upToDate, err := outputUpToDate(filepath.Join(t.TempDir(), "missing.go"), "package main\n", true)
+	if err != nil || upToDate {
+		t.Errorf("Expected missing file to be out of date, got %v, %v", upToDate, err)
+	}
+}
+
+func TestCheckModeLeavesManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	checkMode, manifestFile = true, manifest
+	defer func() { checkMode, manifestFile = false, "" }()
+
+	if err := writeReports(); err != nil {
+		t.Fatalf("Writing reports failed: %v", err)
+	}
+	if _, err := os.Stat(manifest); !os.IsNotExist(err) {
+		t.Errorf("Expected no manifest in check mode, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// classCondition is an entry of an Alpine :class object: classes applied
+// while a JavaScript condition holds
+type classCondition struct {
+	class string
+	when  string
+}
+
+// jsIdentPattern matches an identifier used as an object key
+var jsIdentPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// splitTopLevel splits JavaScript source at the separator bytes that are
+// outside brackets, strings and template literals, returning nil when a
+// bracket closes one opened before src
+func splitTopLevel(src string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(src); i++ {
+		switch ch := src[i]; ch {
+		case '\'', '"', '`':
+			for i++; i < len(src) && src[i] != ch; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth--; depth < 0 {
+				return nil
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, src[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, src[start:])
+}
+
+// parseClassObject parses a :class value holding an object literal of class
+// names and conditions, such as { 'text-red-500': error, active }. Values
+// that are no plain object, or have computed keys, spreads or methods, are
+// not parsed.
+func parseClassObject(val string) ([]classCondition, bool) {
+	src := strings.TrimSpace(val)
+	if !strings.HasPrefix(src, "{") || !strings.HasSuffix(src, "}") || checkJS("("+src+")") != nil {
+		return nil, false
+	}
+	// The entries are nil when the first brace closes before the end, as in
+	// { a: x } || { b: y }
+	entries := splitTopLevel(src[1:len(src)-1], ',')
+	if entries == nil {
+		return nil, false
+	}
+
+	var conds []classCondition
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pair := splitTopLevel(entry, ':')
+		key := strings.TrimSpace(pair[0])
+		when := key
+		if len(pair) > 1 {
+			// A ternary condition holds colons of its own
+			when = strings.TrimSpace(strings.Join(pair[1:], ":"))
+		} else if !jsIdentPattern.MatchString(key) {
+			return nil, false
+		}
+
+		switch {
+		case jsIdentPattern.MatchString(key):
+		case len(key) >= 2 && (key[0] == '\'' || key[0] == '"') && key[len(key)-1] == key[0] &&
+			!strings.ContainsAny(key[1:len(key)-1], "\\'\""):
+			key = key[1 : len(key)-1]
+		default:
+			return nil, false
+		}
+		if when == "" || strings.Contains(when, "\n") {
+			return nil, false
+		}
+		conds = append(conds, classCondition{class: key, when: when})
+	}
+	return conds, len(conds) > 0
+}
+
+// bindValue converts the value of an Alpine class binding with
+// Options.ClassConditions: an object literal becomes a call of the
+// classConds helper listing each class with its condition
+func (c *Converter) bindValue(attr, val string) string {
+	if attr != "class" || !c.opts.ClassConditions {
+		return c.quoteValue(val)
+	}
+	conds, ok := parseClassObject(val)
+	if !ok {
+		return c.quoteValue(val)
+	}
+
+	if c.classCondsFunc == nil {
+		name := c.uniqueFuncName("classConds")
+		typeName := strings.Replace(name, "classConds", "classCond", 1)
+		c.classCondsFunc = &funcDecl{name: name, result: "string", raw: classCondsHelper(name, typeName)}
+		c.funcs = append(c.funcs, c.classCondsFunc)
+		c.stdImports["strconv"] = true
+		c.stdImports["strings"] = true
+	}
+	typeName := strings.Replace(c.classCondsFunc.name, "classConds", "classCond", 1)
+	args := make([]string, len(conds))
+	for i, cond := range conds {
+		args[i] = fmt.Sprintf("%s{%s, %s}", typeName, c.quoteValue(cond.class), c.quoteValue(cond.when))
+	}
+	if len(args) > 1 {
+		return fmt.Sprintf("%s(\n%s,\n)", c.classCondsFunc.name, strings.Join(args, ",\n"))
+	}
+	return fmt.Sprintf("%s(%s)", c.classCondsFunc.name, args[0])
+}
+
+// classCondsHelper returns the declarations of the type pairing classes with
+// their conditions and of the helper building the :class object from them
+func classCondsHelper(name, typeName string) string {
+	return fmt.Sprintf(`// %[2]s is a class Alpine applies while the JavaScript condition When holds
+type %[2]s struct {
+	Class string
+	When  string
+}
+
+// %[1]s returns the Alpine :class object applying each class while its
+// condition holds
+func %[1]s(conds ...%[2]s) string {
+	entries := make([]string, len(conds))
+	for i, cond := range conds {
+		entries[i] = strconv.Quote(cond.Class) + ": " + cond.When
+	}
+	return "{ " + strings.Join(entries, ", ") + " }"
+}
+`, name, typeName)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Comment modes select what becomes of the HTML comments of the input
+const (
+	// CommentsDrop leaves comments out of the generated code
+	CommentsDrop = "drop"
+	// CommentsCode turns comments into Go line comments above the call of
+	// the node following them
+	CommentsCode = "code"
+	// CommentsNode turns comments into Comment("...") nodes, rendering them
+	// into the page again
+	CommentsNode = "node"
+)
+
+// validateComments checks a comment mode name
+func validateComments(mode string) error {
+	switch mode {
+	case "", CommentsDrop, CommentsCode, CommentsNode:
+		return nil
+	}
+	return fmt.Errorf("unknown comment mode %q (available: drop, code, node)", mode)
+}
+
+// keepsComment reports whether a comment is converted in the configured
+// mode; directives and conditional comments for old browsers never are
+func (c *Converter) keepsComment(n *html.Node) bool {
+	if n.Type != html.CommentNode || isDirective(n) || strings.TrimSpace(n.Data) == "" {
+		return false
+	}
+	if strings.HasPrefix(strings.TrimSpace(n.Data), "[if ") {
+		return false
+	}
+	return c.opts.Comments == CommentsCode || c.opts.Comments == CommentsNode
+}
+
+// commentNode converts a comment into a Comment node with CommentsNode
+func (c *Converter) commentNode(n *html.Node) string {
+	if !c.keepsComment(n) || c.opts.Comments != CommentsNode {
+		return ""
+	}
+	return fmt.Sprintf("Comment(%s)", c.quoteValue(n.Data))
+}
+
+// lineComments renders the text of comments as Go line comments, each
+// followed by a line break and the indentation of the code they precede
+func lineComments(comments []string, depth int) string {
+	indent := strings.Repeat("\t", depth)
+	var b strings.Builder
+	for _, text := range comments {
+		for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				b.WriteString("// " + line + "\n" + indent)
+			}
+		}
+	}
+	return b.String()
+}
+
+// blockComment renders the text of comments as a Go block comment, for
+// comments that no code follows
+func blockComment(comments []string) string {
+	var lines []string
+	for _, text := range comments {
+		for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, strings.ReplaceAll(line, "*/", "* /"))
+			}
+		}
+	}
+	return "/* " + strings.Join(lines, " ") + " */"
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// bootstrapComponents maps Bootstrap structure classes to the helpers of a
+// user-provided component package imported as ui
+var bootstrapComponents = map[string]string{
+	"navbar":             "ui.Navbar",
+	"navbar-brand":       "ui.NavbarBrand",
+	"navbar-nav":         "ui.NavbarNav",
+	"nav-item":           "ui.NavItem",
+	"nav-link":           "ui.NavLink",
+	"modal":              "ui.Modal",
+	"modal-dialog":       "ui.ModalDialog",
+	"modal-content":      "ui.ModalContent",
+	"modal-header":       "ui.ModalHeader",
+	"modal-title":        "ui.ModalTitle",
+	"modal-body":         "ui.ModalBody",
+	"modal-footer":       "ui.ModalFooter",
+	"card":               "ui.Card",
+	"card-header":        "ui.CardHeader",
+	"card-body":          "ui.CardBody",
+	"card-title":         "ui.CardTitle",
+	"card-text":          "ui.CardText",
+	"card-footer":        "ui.CardFooter",
+	"accordion":          "ui.Accordion",
+	"accordion-item":     "ui.AccordionItem",
+	"accordion-header":   "ui.AccordionHeader",
+	"accordion-button":   "ui.AccordionButton",
+	"accordion-collapse": "ui.AccordionCollapse",
+	"accordion-body":     "ui.AccordionBody",
+}
+
+// componentMatch is an element converted through a component helper
+type componentMatch struct {
+	helper string
+	class  string
+}
+
+// matchComponents marks the elements whose classes map to component helpers,
+// merging the profile's defaults with the configured mapping
+func (c *Converter) matchComponents(nodes []*html.Node) {
+	c.componentNodes = make(map[*html.Node]componentMatch)
+
+	mapping := make(map[string]string)
+	if c.profile != nil {
+		for class, helper := range c.profile.components {
+			mapping[class] = helper
+		}
+	}
+	for class, helper := range c.opts.Components {
+		mapping[class] = helper
+	}
+	if len(mapping) == 0 {
+		return
+	}
+
+	unresolved := make(map[string]bool)
+	forEachElement(nodes, func(n *html.Node) {
+		for _, class := range strings.Fields(attrValue(n, "class")) {
+			helper, ok := mapping[class]
+			if !ok {
+				continue
+			}
+			c.componentNodes[n] = componentMatch{helper: helper, class: class}
+			if !c.hasQualifier(helper) {
+				unresolved[helper] = true
+			}
+			return
+		}
+	})
+
+	if len(unresolved) > 0 {
+		helpers := make([]string, 0, len(unresolved))
+		for helper := range unresolved {
+			helpers = append(helpers, helper)
+		}
+		sort.Strings(helpers)
+		c.report(nil, SeverityWarning, "component-import", "no import provides %s; add the component package to the config imports", strings.Join(helpers, ", "))
+	}
+}
+
+// hasQualifier reports whether the package qualifier of a helper is one of the configured imports
+func (c *Converter) hasQualifier(code string) bool {
+	m := qualifierPattern.FindStringSubmatch(code)
+	if m == nil {
+		return true
+	}
+	for _, imp := range c.opts.Imports {
+		if importName(imp) == m[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutClass returns a class list without one of its classes
+func withoutClass(classes, class string) string {
+	var kept []string
+	for _, field := range strings.Fields(classes) {
+		if field != class {
+			kept = append(kept, field)
+		}
+	}
+	return strings.Join(kept, " ")
+}
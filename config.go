@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the project configuration read from a YAML (or JSON) file
+type Config struct {
+	// Routes map input paths to output paths, e.g.
+	// "pages/**.html -> internal/views/{dir}/{base}.go"
+	Routes []string `yaml:"routes"`
+	// Data maps data-* attributes to typed helpers, e.g.
+	// data-user-id: "int -> views.DataUserId(%d)"
+	Data map[string]string `yaml:"data"`
+	// Imports lists packages referenced by mapped helpers, as "path" or "alias path"
+	Imports []string `yaml:"imports"`
+	// Components maps classes to component helpers, e.g. card: ui.Card
+	Components map[string]string `yaml:"components"`
+	// Replace swaps known regions for existing components, e.g.
+	// ".site-footer -> views.Footer()"
+	Replace []string `yaml:"replace"`
+	// Strip lists attributes to drop, e.g. data-qa or the preset testing
+	Strip []string `yaml:"strip"`
+	// Snippets maps recognized third-party snippets to helpers, e.g.
+	// hotjar: tracking.Hotjar
+	Snippets map[string]string `yaml:"snippets"`
+}
+
+// apply copies the conversion settings of the config into opts
+func (cfg *Config) apply(opts *Options) error {
+	if len(cfg.Data) > 0 {
+		opts.DataAttributes = make(map[string]TypedAttr, len(cfg.Data))
+		for key, spec := range cfg.Data {
+			mapping, err := parseTypedAttr(spec)
+			if err != nil {
+				return fmt.Errorf("data mapping for %s: %w", key, err)
+			}
+			opts.DataAttributes[key] = mapping
+		}
+	}
+	opts.Imports = append(opts.Imports, cfg.Imports...)
+	opts.Components = cfg.Components
+	opts.StripAttributes = append(opts.StripAttributes, cfg.Strip...)
+	opts.Snippets = cfg.Snippets
+	for _, rule := range cfg.Replace {
+		patch, err := parseReplacement(rule)
+		if err != nil {
+			return err
+		}
+		opts.Patches = append(opts.Patches, patch)
+	}
+	return nil
+}
+
+// loadConfig reads a configuration file
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
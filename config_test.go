@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigTypedDataAttributes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plainkit.yaml")
+	config := `imports:
+  - example.com/app/views
+  - ui example.com/app/components
+data:
+  data-user-id: "int -> views.DataUserId(%d)"
+  data-label: "string -> ui.Label(%s)"
+  data-role: "string -> ui.Role(%q)"
+`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	var opts Options
+	if err := cfg.apply(&opts); err != nil {
+		t.Fatalf("Failed to apply config: %v", err)
+	}
+
+	converter := NewConverterWithOptions(opts)
+	result, err := converter.Convert(`<div data-user-id="42" data-label="Admin" data-role="owner"><span data-user-id="abc"></span></div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`"example.com/app/views"`,
+		`ui "example.com/app/components"`,
+		"views.DataUserId(42)",
+		`ui.Label("Admin")`,
+		`ui.Role("owner")`,
+		`Data("user-id", "abc")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "typed-attr-mismatch" {
+		t.Errorf("Expected a typed-attr-mismatch diagnostic, got %v", diags)
+	}
+}
+
+func TestParseTypedAttrInvalid(t *testing.T) {
+	for _, spec := range []string{"int DataId(%d)", "uuid -> DataId(%s)", "int -> DataId()", "int -> DataId(%s)", "bool -> DataOn(%d)", "string -> DataName(%5.2f)"} {
+		if _, err := parseTypedAttr(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+
+	if _, err := parseTypedAttr("int -> DataId(%s)"); err == nil || !strings.Contains(err.Error(), "%s cannot format int values; use one of %d, %v") {
+		t.Errorf("Expected the accepted verbs in the error, got %v", err)
+	}
+	for _, spec := range []string{"int -> DataId(%v)", "float -> DataRatio(%.2f)", "string -> DataName(%q)", "bool -> DataOn(%t) // 100%%"} {
+		if _, err := parseTypedAttr(spec); err != nil {
+			t.Errorf("Unexpected error for %q: %v", spec, err)
+		}
+	}
+}
+
+func TestConfigReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plainkit.yaml")
+	config := `imports:
+  - example.com/app/views
+replace:
+  - ".site-footer -> views.Footer()"
+  - "nav > .brand -> views.Logo()"
+`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	var opts Options
+	if err := cfg.apply(&opts); err != nil {
+		t.Fatalf("Failed to apply config: %v", err)
+	}
+
+	converter := NewConverterWithOptions(opts)
+	result, err := converter.Convert(`<div><nav><a class="brand" href="/"><img src="logo.svg"></a></nav><main>Hi</main><div class="site-footer"><p>Links</p></div></div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`"example.com/app/views"`,
+		"Nav(views.Logo())",
+		"views.Footer())",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "logo.svg") || strings.Contains(result, "Links") {
+		t.Errorf("Expected replaced regions to be omitted.\nOutput:\n%s", result)
+	}
+
+	for _, rule := range []string{".footer views.Footer()", ".footer ->", "-> views.Footer()"} {
+		if _, err := parseReplacement(rule); err == nil {
+			t.Errorf("Expected error for %q", rule)
+		}
+	}
+}
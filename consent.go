@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// consentRule detects the markup of a consent management platform
+type consentRule struct {
+	vendor   string
+	selector selector
+}
+
+// mustParseSelector parses a built-in selector, panicking when it is invalid
+func mustParseSelector(s string) selector {
+	sel, err := parseSelector(s)
+	if err != nil {
+		panic(fmt.Sprintf("selector %q: %v", s, err))
+	}
+	return sel
+}
+
+// consentRules are the banners, dialogs and loader scripts of common consent
+// management platforms
+var consentRules = []consentRule{
+	{"OneTrust", mustParseSelector(`#onetrust-consent-sdk, #onetrust-banner-sdk, script[src*="cookielaw.org"], script[src*="otSDKStub"]`)},
+	{"Cookiebot", mustParseSelector(`#CybotCookiebotDialog, #Cookiebot, script[src*="consent.cookiebot.com"]`)},
+	{"CookieYes", mustParseSelector(`.cky-consent-container, script[src*="cdn-cookieyes.com"]`)},
+	{"Osano", mustParseSelector(`.osano-cm-window, script[src*="cmp.osano.com"]`)},
+	{"Quantcast Choice", mustParseSelector(`#qc-cmp2-container, script[src*="quantcast.mgr.consensu.org"], script[src*="cmp.quantcast.com"]`)},
+	{"TrustArc", mustParseSelector(`#truste-consent-track, #consent_blackbar, script[src*="consent.trustarc.com"]`)},
+	{"Usercentrics", mustParseSelector(`#usercentrics-root, #usercentrics-cmp, script[src*="usercentrics.eu"]`)},
+	{"Didomi", mustParseSelector(`#didomi-host, script[src*="sdk.privacy-center.org"]`)},
+	{"Complianz", mustParseSelector(`#cmplz-cookiebanner-container, .cmplz-cookiebanner`)},
+	{"iubenda", mustParseSelector(`#iubenda-cs-banner, script[src*="cdn.iubenda.com/cs"]`)},
+	{"Termly", mustParseSelector(`script[src*="app.termly.io"]`)},
+	{"Cookie Consent", mustParseSelector(`.cc-window, .cc-banner, script[src*="cookieconsent"]`)},
+	{"cookie banner", mustParseSelector(`#cookie-banner, #cookie-consent, #cookie-notice, .cookie-banner, .cookie-consent, .cookie-notice`)},
+}
+
+// matchConsent returns the vendor whose consent markup n is
+func matchConsent(n *html.Node) (string, bool) {
+	for _, rule := range consentRules {
+		if rule.selector.match(n) {
+			return rule.vendor, true
+		}
+	}
+	return "", false
+}
+
+// stripConsent removes consent banners and their scripts. With a ConsentStub
+// call the first banner is replaced by the call instead.
+func (c *Converter) stripConsent(nodes []*html.Node) {
+	c.consentStub = nil
+	c.strippedRoots = make(map[*html.Node]bool)
+	if !c.opts.StripConsent {
+		return
+	}
+
+	var removals []*html.Node
+	removalVendors := make(map[*html.Node]string)
+	vendors := make(map[string]int)
+	var order []string
+	stripped := func(vendor string) {
+		if vendors[vendor] == 0 {
+			order = append(order, vendor)
+		}
+		vendors[vendor]++
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if vendor, ok := matchConsent(n); ok {
+				if c.opts.ConsentStub != "" && c.consentStub == nil && n.Data != "script" {
+					c.consentStub = n
+					stripped(vendor)
+				} else {
+					removals = append(removals, n)
+					removalVendors[n] = vendor
+				}
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	// Top-level banners of a fragment are left out of the fragment list
+	roots := make(map[*html.Node]bool)
+	for _, n := range nodes {
+		roots[n] = true
+	}
+	for _, n := range removals {
+		switch {
+		case roots[n]:
+			c.strippedRoots[n] = true
+			if n.Parent != nil {
+				n.Parent.RemoveChild(n)
+			}
+		case n.Parent != nil:
+			n.Parent.RemoveChild(n)
+		default:
+			continue
+		}
+		stripped(removalVendors[n])
+	}
+
+	for _, vendor := range order {
+		c.report(nil, SeverityInfo, "consent-stripped", "removed %d %s consent element(s)", vendors[vendor], vendor)
+	}
+	if c.consentStub != nil && !c.hasQualifier(c.opts.ConsentStub) && strings.Contains(c.opts.ConsentStub, ".") {
+		c.report(nil, SeverityWarning, "consent-import", "no import provides %s; add its package to the config imports", c.opts.ConsentStub)
+	}
+}
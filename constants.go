@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// hoistableAttrs lists the attributes whose repeated values can be hoisted into constants
+var hoistableAttrs = map[string]bool{
+	"class":  true,
+	"href":   true,
+	"src":    true,
+	"action": true,
+	"style":  true,
+}
+
+// constDecl is a package-level constant holding a repeated attribute value
+type constDecl struct {
+	name  string
+	value string
+}
+
+// constKey identifies a hoisted value by the attribute holding it, so that
+// other attributes with the same value keep their literal
+type constKey struct {
+	attr  string
+	value string
+}
+
+// collectConstants counts hoistable attribute values and assigns a constant to
+// every value repeated at least Options.HoistConstants times
+func (c *Converter) collectConstants(nodes []*html.Node) {
+	c.consts = nil
+	c.constNames = make(map[constKey]string)
+	if c.opts.HoistConstants <= 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	keys := make(map[string][]string)
+	var order []html.Attribute
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if !hoistableAttrs[attr.Key] || strings.TrimSpace(attr.Val) == "" || c.hasProps(attr.Val) {
+					continue
+				}
+				if counts[attr.Val] == 0 {
+					order = append(order, attr)
+				}
+				if !slices.Contains(keys[attr.Val], attr.Key) {
+					keys[attr.Val] = append(keys[attr.Val], attr.Key)
+				}
+				counts[attr.Val]++
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	used := make(map[string]bool)
+	for _, attr := range order {
+		if counts[attr.Val] < c.opts.HoistConstants {
+			continue
+		}
+		name := constName(attr.Key, attr.Val)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", constName(attr.Key, attr.Val), i)
+		}
+		used[name] = true
+		for _, key := range keys[attr.Val] {
+			c.constNames[constKey{key, attr.Val}] = name
+		}
+		c.consts = append(c.consts, constDecl{name: name, value: attr.Val})
+	}
+}
+
+// constName derives a constant name from an attribute key and value
+func constName(key, val string) string {
+	words := strings.FieldsFunc(val, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if len(words) > 4 {
+		words = words[:4]
+	}
+	return goIdentifier(key+" "+strings.Join(words, " "), false)
+}
+
+// attrValue returns the Go expression for the value of attribute key,
+// referencing a hoisted constant when there is one
+func (c *Converter) attrValue(key, val string) string {
+	if name, ok := c.constNames[constKey{key, val}]; ok {
+		return name
+	}
+	if expr, ok := c.propExpr(key, val); ok {
+		return expr
+	}
+	if expr, ok := c.placeholderExpr(key, val); ok {
+		return expr
+	}
+	return c.quoteValue(val)
+}
+
+// writeConsts renders the hoisted constants block
+func (c *Converter) writeConsts(buf codeWriter) {
+	consts := append(c.defineConsts, c.consts...)
+	if len(consts) == 0 {
+		return
+	}
+	buf.WriteString("const (\n")
+	for _, decl := range consts {
+		fmt.Fprintf(buf, "\t%s = %s\n", decl.name, c.quoteValue(decl.value))
+	}
+	buf.WriteString(")\n\n")
+}
@@ -1,28 +1,344 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/net/html"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
+// Options configures the behaviour of a Converter
+type Options struct {
+	HTMX   bool
+	Alpine bool
+	// Package is the package of the generated code; empty means main
+	Package string
+	// FuncName replaces the default name of the main function, such as Page
+	// or Component or the name derived from the input file
+	FuncName string
+	// Unexported starts the name of the main function with a lower case letter
+	Unexported bool
+	// Filename is the name of the input. It is used to derive the generated
+	// function name and to detect JSX input.
+	Filename string
+	// HoistConstants moves attribute values repeated at least this many
+	// times into package-level constants; zero disables hoisting
+	HoistConstants int
+	// StripNonce replaces nonce attribute values with a nonce parameter
+	StripNonce bool
+	// CSP is a Content-Security-Policy that inline scripts and styles are checked against
+	CSP string
+	// Email checks the markup against email-client constraints. Styles are
+	// always kept inline in this mode.
+	Email bool
+	// FragmentWrapper wraps multiple root nodes in a Fragment so the
+	// generated function returns a single Node instead of []Node
+	FragmentWrapper bool
+	// FragmentFuncs converts each root element of multi-fragment input into
+	// a function of its own, named after its id, first class or tag, which
+	// the main function then references
+	FragmentFuncs bool
+	// NoFormat leaves the generated code as rendered instead of running it
+	// through go/format
+	NoFormat bool
+	// Qualified imports the Plain html package by name instead of with a dot
+	// import and qualifies its identifiers, e.g. html.Div(html.Class("card"))
+	Qualified bool
+	// HTMLAlias is the package name used in qualified mode, "html" by default
+	HTMLAlias string
+	// Standalone replaces the Plain imports by local stubs so that the
+	// generated file compiles on its own; the output is not for production
+	Standalone bool
+	// SuggestHandlers reports inline on* event handlers with suggested
+	// Alpine or htmx replacements
+	SuggestHandlers bool
+	// RewriteHandlers converts inline on* event handlers into Alpine @ attributes
+	RewriteHandlers bool
+	// ClassConditions converts Alpine :class object literals into a helper
+	// call listing each class with its condition
+	ClassConditions bool
+	// AlpineData converts x-data values naming a component registered with
+	// Alpine.data() into a helper call, apart from inline state objects
+	AlpineData bool
+	// Events converts inline on* event handlers with the Plain event helpers,
+	// such as OnClick, instead of Custom
+	Events bool
+	// DataAttributes maps data-* attributes to typed helper calls
+	DataAttributes map[string]TypedAttr
+	// Imports are extra packages that generated code may reference, as
+	// "path" or "alias path"; only the ones used are emitted
+	Imports []string
+	// NormalizeIndicators converts htmx loading indicators through a shared
+	// LoadingIndicator helper with consistent aria-live attributes
+	NormalizeIndicators bool
+	// Parameterize turns values that vary per page, such as the document
+	// title and meta description, into function parameters
+	Parameterize bool
+	// ParamNames is the naming strategy of the parameters extracted by
+	// Parameterize: merge (the default), unique or source
+	ParamNames string
+	// StripDesignArtifacts removes attributes left by design tool exports
+	// (Webflow, Figma, Framer) unless they are mapped in DataAttributes
+	StripDesignArtifacts bool
+	// Profile names a cleanup profile for site builder exports, such as
+	// "webflow"; it also strips design tool attributes
+	Profile string
+	// Defines resolves ${NAME} placeholders in text and attribute values;
+	// placeholders without a definition become string parameters
+	Defines map[string]string
+	// DefineConsts references defined values through constants instead of inlining them
+	DefineConsts bool
+	// ClassVariants extracts class bundles repeated at least this many times
+	// into per-tag class constants or variants maps (0 disables)
+	ClassVariants int
+	// Patches override the generated code for elements matching CSS selectors
+	Patches []Patch
+	// ComponentPer is a CSS selector, such as "#hero, #faq"; every matching
+	// region becomes a function of its own named after its id
+	ComponentPer string
+	// Comments selects what becomes of HTML comments: CommentsDrop (the
+	// default), CommentsCode or CommentsNode
+	Comments string
+	// HeadingIDs gives headings without an id one derived from their text,
+	// numbered to keep ids unique
+	HeadingIDs bool
+	// TOC adds a TableOfContents helper linking to the headings of the
+	// input, giving headings without an id one derived from their text
+	TOC bool
+	// Props turns {{name}} in text and attribute values, and the data-prop
+	// attributes naming the prop of an attribute or of the content of their
+	// element, into string parameters of the generated function
+	Props bool
+	// Dedupe converts sibling subtrees that are identical apart from their
+	// texts and attribute values, and occur at least this many times, through
+	// one parameterized helper; zero disables it
+	Dedupe int
+	// Split gives the header, nav, main, aside and footer of a page, and
+	// sections with an id, functions of their own composed by the page
+	Split bool
+	// EditableRegions emits plainkit:editable regions for user code in the
+	// imports, at the top of every function and at the end of the file
+	EditableRegions bool
+	// NormalizeEnums lowercases enumerated attribute values such as
+	// method="POST" that only differ from a keyword in case
+	NormalizeEnums bool
+	// Validate reports invalid nesting and duplicate elements in the source
+	// that the HTML parser would silently repair
+	Validate bool
+	// CheckExpressions reports syntax errors in the scripts of Alpine
+	// directives and hx-on handlers and in the JSON of hx-vals and hx-headers
+	CheckExpressions bool
+	// ReportMutations reports the elements the HTML parser moved, inserted or
+	// dropped compared to the source
+	ReportMutations bool
+	// TextMode selects how text nodes are converted: TextTrim (the
+	// default), TextCollapse or TextVerbatim. Patches can override it for
+	// the content of matching elements.
+	TextMode string
+	// Mode forces conversion as a full page (ModePage) or a fragment
+	// (ModeFragment); ModeAuto, the default, detects full pages
+	Mode string
+	// Components maps classes to helpers of a component package, e.g.
+	// "card" -> "ui.Card"; elements with the class are converted to helper calls
+	Components map[string]string
+	// Theme holds the CSS custom properties a theme defines, name to value;
+	// var() references to other properties are reported when it is set
+	Theme map[string]string
+	// ThemeTokens emits a map of the custom properties the component references
+	ThemeTokens bool
+	// ThemeCoverage reports how many elements setting Tailwind colors have
+	// dark: variants or sit in a data-theme scope, per component
+	ThemeCoverage bool
+	// DarkVariants keeps dark: classes apart from the base classes of an
+	// element through a withDark helper
+	DarkVariants bool
+	// StripAttributes names attributes to drop, such as test hooks; entries
+	// may end in * to match a prefix or name a preset ("testing", "analytics")
+	StripAttributes []string
+	// IconsImport is the import path of an icons package; when set, SVG
+	// sprite symbols and repeated inline icons become functions of it
+	IconsImport string
+	// HoistSVG moves every inline svg into a function of its own, named
+	// like IconLogo or IllustrationHero
+	HoistSVG bool
+	// SeparateSVG leaves the hoisted SVG functions out of the generated
+	// code; SVGCode returns them as a file of their own
+	SeparateSVG bool
+	// GroupFavicons replaces the icon, manifest and theme-color tags of the
+	// head with a Favicons helper taking their base path
+	GroupFavicons bool
+	// StubSnippets replaces recognized analytics and chat snippets (Google
+	// tag, Tag Manager, Hotjar, Intercom) with helper calls taking the account id
+	StubSnippets bool
+	// Snippets overrides the helper of a snippet, e.g. "hotjar" ->
+	// "tracking.Hotjar"; an empty helper keeps the snippet
+	Snippets map[string]string
+	// StripConsent removes the banners and scripts of common consent
+	// management platforms (OneTrust, Cookiebot, Usercentrics, ...)
+	StripConsent bool
+	// ConsentStub is a call, such as "views.CookieConsent()", that takes
+	// the place of the first consent banner instead of removing it
+	ConsentStub string
+	// CSRFHelper is a call, such as "views.CSRFField()", replacing hidden
+	// CSRF token inputs; without it their value becomes a csrfToken parameter
+	CSRFHelper string
+	// A11yFix applies safe accessibility fixes: empty alt on decorative
+	// images, explicit button types in forms and label associations
+	A11yFix bool
+	// DecorativeImages is a CSS selector for images to mark as decorative
+	DecorativeImages string
+	// HeadingLevel shifts the headings so the highest one is at this level
+	// (1-6), keeping their relative structure; zero leaves headings alone
+	HeadingLevel int
+	// PageMeta declares the title, description, OpenGraph image and other
+	// SEO metadata of a full page in a variable next to its function
+	PageMeta bool
+	// PinScripts moves scripts the parser put in the body, because content
+	// before them started it, back to the head where the source wrote them
+	PinScripts bool
+	// MetaHelpers replaces the standard viewport meta and theme-color metas,
+	// including light and dark pairs, with Viewport and ThemeColor helpers,
+	// and warns about full pages without a viewport
+	MetaHelpers bool
+	// FormStructs generates a struct for every form, with a field per named
+	// control, and a Bind helper reading it from an *http.Request
+	FormStructs bool
+	// Mappings add to or replace the built-in element and attribute mappings
+	Mappings MappingOverrides
+	// Flatten removes div and span wrappers without attributes around a single element
+	Flatten bool
+	// AnnotateLang appends a lang/dir comment to text nodes whose language
+	// context differs from the document's
+	AnnotateLang bool
+}
+
 // Converter handles HTML to Plain conversion
 type Converter struct {
+	opts      Options
 	useHTMX   bool
 	useAlpine bool
 	imports   map[string]bool
 	indent    int
+
+	// stdImports are the standard library packages generated helpers use
+	stdImports map[string]bool
+	// doctype is the doctype declaration of a converted page
+	doctype string
+	// pageMeta holds the scraped metadata of a converted page by field
+	pageMeta map[string]string
+	// paramValues holds the value each parameter extracted from the function
+	// being converted was named for
+	paramValues map[string]string
+
+	// mainFunc is the function being generated for the input, scope is the
+	// function currently receiving parameters and funcs are extracted helpers
+	mainFunc    *funcDecl
+	scope       *funcDecl
+	funcs       []*funcDecl
+	diagnostics []Diagnostic
+
+	consts     []constDecl
+	constNames map[constKey]string
+	variants   []variantsDecl
+
+	themeTokens []themeToken
+
+	defineConsts []constDecl
+
+	indicators     map[*html.Node]bool
+	indicatorFuncs map[string]*funcDecl
+
+	profile        *cleanupProfile
+	componentNodes map[*html.Node]componentMatch
+	patches        map[*html.Node]*Patch
+
+	accordions       map[*html.Node][]*html.Node
+	accordionMembers map[*html.Node]bool
+	accordionFunc    *funcDecl
+	darkFunc         *funcDecl
+	classCondsFunc   *funcDecl
+	alpineDataFunc   *funcDecl
+
+	icons       []*iconDecl
+	iconCalls   map[*html.Node]*iconDecl
+	iconContent map[*html.Node]*iconDecl
+	svgFuncs    []*funcDecl
+
+	favicons       []*html.Node
+	faviconMembers map[*html.Node]bool
+	faviconBase    string
+	faviconFunc    *funcDecl
+
+	snippetCalls map[*html.Node]string
+	consentStub  *html.Node
+	// strippedRoots are the top-level nodes of a fragment that cleanup
+	// removed, which convertFragment leaves out
+	strippedRoots map[*html.Node]bool
+
+	// metaCalls are the helper calls replacing viewport and theme-color metas
+	metaCalls      map[*html.Node]string
+	viewportFunc   *funcDecl
+	themeColorFunc *funcDecl
+
+	// paginations and breadcrumbs are the navigation controls generated by
+	// helpers in parameterize mode
+	paginations    map[*html.Node]*pagination
+	breadcrumbs    map[*html.Node]*breadcrumb
+	paginationFunc *funcDecl
+	breadcrumbFunc *funcDecl
+
+	// csrfInputs are the hidden inputs carrying a CSRF token
+	csrfInputs map[*html.Node]bool
+
+	// propSamples are the values data-prop attributes replaced, which type
+	// their props in parameterize mode
+	propSamples map[propKey]string
+
+	// links are the internal links of the input, and hxRequests the
+	// requests sent through its htmx attributes
+	links      []LinkInfo
+	hxRequests []HxRequest
+
+	// alpineUsage is the Alpine client state the input depends on
+	alpineUsage AlpineUsage
+
+	// repeats are the subtrees converted through a shared helper with
+	// Dedupe, and slots the parameters of the helper being declared
+	repeats map[*html.Node]*repeatGroup
+	slots   map[*html.Node]map[string]string
+
+	// forms are the structs generated for forms with FormStructs
+	forms []*formModel
+
+	// source is the HTML being converted and lineOffset the number of
+	// lines trimmed from its start
+	source     string
+	lineOffset int
+
+	// ctx cancels the conversion in progress
+	ctx context.Context
 }
 
 // NewConverter creates a new HTML to Plain converter
 func NewConverter(useHTMX, useAlpine bool) *Converter {
+	return NewConverterWithOptions(Options{HTMX: useHTMX, Alpine: useAlpine})
+}
+
+// NewConverterWithOptions creates a new HTML to Plain converter from Options
+func NewConverterWithOptions(opts Options) *Converter {
 	return &Converter{
-		useHTMX:   useHTMX,
-		useAlpine: useAlpine,
+		opts:      opts,
+		useHTMX:   opts.HTMX,
+		useAlpine: opts.Alpine,
 		imports:   make(map[string]bool),
 		indent:    0,
 	}
@@ -30,29 +346,275 @@ func NewConverter(useHTMX, useAlpine bool) *Converter {
 
 // Convert converts HTML string to Plain Go code
 func (c *Converter) Convert(htmlContent string) (string, error) {
-	// Clean up the content
+	return c.ConvertContext(context.Background(), htmlContent)
+}
+
+// ConvertContext is like Convert but stops early once ctx is cancelled
+func (c *Converter) ConvertContext(ctx context.Context, htmlContent string) (string, error) {
+	if err := c.convert(ctx, htmlContent); err != nil {
+		return "", err
+	}
+	return c.render(), nil
+}
+
+// ConvertTo reads HTML from r and writes the generated Plain Go code to w.
+// Only fragments converted with Options.Mode set to ModeFragment are parsed as
+// they are read, as detecting pages and documents, and the MIME, JSX,
+// Validate and ReportMutations handling need the whole input. Formatting
+// needs the whole file, so only with Options.NoFormat is the code streamed
+// to w as it is generated, without building the file in memory.
+func (c *Converter) ConvertTo(w io.Writer, r io.Reader) error {
+	return c.ConvertToContext(context.Background(), w, r)
+}
+
+// ConvertToContext is like ConvertTo but stops early once ctx is cancelled
+func (c *Converter) ConvertToContext(ctx context.Context, w io.Writer, r io.Reader) error {
+	if err := c.convertReader(ctx, r); err != nil {
+		return err
+	}
+	if !c.opts.NoFormat || c.opts.Qualified {
+		// Formatting and qualifying need the whole file
+		_, err := io.WriteString(w, c.render())
+		return err
+	}
+	out := bufio.NewWriter(w)
+	c.renderTo(out)
+	return out.Flush()
+}
+
+// ConvertTo converts the HTML read from r with the given options, writing the
+// generated code to w, see Converter.ConvertTo
+func ConvertTo(w io.Writer, r io.Reader, opts Options) error {
+	return NewConverterWithOptions(opts).ConvertTo(w, r)
+}
+
+// ConvertToContext is like ConvertTo but stops early once ctx is cancelled
+func ConvertToContext(ctx context.Context, w io.Writer, r io.Reader, opts Options) error {
+	return NewConverterWithOptions(opts).ConvertToContext(ctx, w, r)
+}
+
+// normalizeInput drops a UTF-8 byte order mark and turns CRLF and lone CR
+// line endings into LF, so neither reaches text literals or line numbers
+func normalizeInput(s string) string {
+	s = strings.TrimPrefix(s, "\uFEFF")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
+// inputReader applies normalizeInput and strings.TrimSpace to HTML as it is
+// read: it drops a byte order mark and the space after it, turns CRLF and
+// lone CR into LF, and holds back space until more content follows it
+type inputReader struct {
+	src *bufio.Reader
+	// first is set until the first rune, which may be a byte order mark,
+	// and leading until the first rune that is not space
+	first, leading bool
+	// space is held back, and out ready to be read
+	space, out []byte
+	err        error
+}
+
+// newInputReader returns a reader normalizing and trimming the HTML of r
+func newInputReader(r io.Reader) io.Reader {
+	return &inputReader{src: bufio.NewReader(r), first: true, leading: true}
+}
+
+// Read implements io.Reader
+func (r *inputReader) Read(p []byte) (int, error) {
+	for len(r.out) < len(p) && r.err == nil {
+		r.next()
+	}
+	if len(r.out) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// next moves the next rune of the source to the output or the held space.
+// Space still held at the end of the input is dropped.
+func (r *inputReader) next() {
+	ch, size, err := r.src.ReadRune()
+	if err != nil {
+		r.err = err
+		return
+	}
+	first := r.first
+	r.first = false
+	if first && ch == '\uFEFF' {
+		return
+	}
+	var encoded []byte
+	switch {
+	case ch == utf8.RuneError && size == 1:
+		// Invalid UTF-8 is passed on as it is
+		_ = r.src.UnreadRune()
+		b, _ := r.src.ReadByte()
+		encoded = []byte{b}
+	case ch == '\r':
+		if next, err := r.src.Peek(1); err == nil && next[0] == '\n' {
+			_, _ = r.src.ReadByte()
+		}
+		encoded = []byte{'\n'}
+	default:
+		encoded = utf8.AppendRune(nil, ch)
+	}
+	if unicode.IsSpace(ch) {
+		if !r.leading {
+			r.space = append(r.space, encoded...)
+		}
+		return
+	}
+	r.leading = false
+	r.out = append(append(r.out, r.space...), encoded...)
+	r.space = r.space[:0]
+}
+
+// convert parses and converts HTML, leaving the generated declarations on the converter
+func (c *Converter) convert(ctx context.Context, htmlContent string) error {
+	if err := c.start(ctx); err != nil {
+		return err
+	}
+	if detectSyntax(c.opts.Filename) == syntaxMIME {
+		extracted, err := c.extractMIMEHTML(htmlContent)
+		if err != nil {
+			return err
+		}
+		htmlContent = extracted
+	}
+	htmlContent = normalizeInput(htmlContent)
+	if c.opts.Validate {
+		c.validateStructure(htmlContent)
+	}
+
+	// Clean up the content, keeping track of the lines trimmed from the start
+	trimmed := strings.TrimLeftFunc(htmlContent, unicode.IsSpace)
+	c.lineOffset = strings.Count(htmlContent[:len(htmlContent)-len(trimmed)], "\n")
 	htmlContent = strings.TrimSpace(htmlContent)
 
-	// Check if this looks like a full HTML document
-	isFullPage := strings.Contains(htmlContent, "<!DOCTYPE") ||
-		strings.Contains(htmlContent, "<html") ||
-		(strings.Contains(htmlContent, "<head") && strings.Contains(htmlContent, "<body"))
+	if detectSyntax(c.opts.Filename) == syntaxJSX {
+		htmlContent = c.normalizeJSX(htmlContent)
+	}
+	c.source = htmlContent
+
+	var err error
+	if docs := splitDocuments(htmlContent); len(docs) > 1 && c.opts.Mode != ModeFragment {
+		err = c.convertDocuments(htmlContent, docs)
+	} else if c.isPage(htmlContent) {
+		err = c.convertFullPage(htmlContent)
+	} else {
+		// Handle as snippet/fragment
+		err = c.convertFragment(strings.NewReader(htmlContent))
+	}
+	return c.finish(ctx, err)
+}
 
-	if isFullPage {
-		return c.convertFullPage(htmlContent)
+// convertReader converts HTML read from r. A fragment whose conversion needs
+// nothing but the parsed tree is parsed as it is read; any other input is
+// read whole first.
+func (c *Converter) convertReader(ctx context.Context, r io.Reader) error {
+	if !c.streamsInput() {
+		src, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		return c.convert(ctx, string(src))
+	}
+	if err := c.start(ctx); err != nil {
+		return err
+	}
+	c.source, c.lineOffset = "", 0
+	return c.finish(ctx, c.convertFragment(newInputReader(r)))
+}
+
+// streamsInput reports whether the input can be parsed as it is read: it is
+// converted as a fragment, and neither the syntax of the file nor the
+// validation and mutation reports need the source text
+func (c *Converter) streamsInput() bool {
+	return c.opts.Mode == ModeFragment && detectSyntax(c.opts.Filename) == syntaxHTML &&
+		!c.opts.Validate && !c.opts.ReportMutations
+}
+
+// start resets the state of the previous conversion and checks the options
+func (c *Converter) start(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("conversion canceled: %w", err)
+	}
+	c.ctx = ctx
+	c.funcs = nil
+	c.diagnostics = nil
+	c.accordionFunc = nil
+	c.darkFunc = nil
+	c.classCondsFunc = nil
+	c.alpineDataFunc = nil
+	c.stdImports = make(map[string]bool)
+	c.svgFuncs = nil
+	c.doctype = ""
+	c.pageMeta = nil
+	c.paramValues = make(map[string]string)
+	// Helpers are shared by the documents of an input
+	c.paginationFunc, c.breadcrumbFunc = nil, nil
+	c.viewportFunc, c.themeColorFunc = nil, nil
+
+	if err := validateTextMode(c.opts.TextMode); err != nil {
+		return err
+	}
+	if err := validateNames(c.opts.Package, c.opts.FuncName); err != nil {
+		return err
+	}
+	if err := validateAlias(c.opts.HTMLAlias); err != nil {
+		return err
 	}
+	if c.opts.Standalone && c.opts.Qualified {
+		return fmt.Errorf("standalone output cannot be qualified")
+	}
+	if err := validateParamNames(c.opts.ParamNames); err != nil {
+		return err
+	}
+	if err := validateComments(c.opts.Comments); err != nil {
+		return err
+	}
+	if err := validateMode(c.opts.Mode); err != nil {
+		return err
+	}
+	c.profile = nil
+	if c.opts.Profile != "" {
+		profile, err := lookupProfile(c.opts.Profile)
+		if err != nil {
+			return err
+		}
+		c.profile = profile
+	}
+	return nil
+}
 
-	// Handle as snippet/fragment
-	return c.convertFragment(htmlContent)
+// finish returns the error of a conversion, or the cancellation of ctx
+func (c *Converter) finish(ctx context.Context, err error) error {
+	// A cancelled walk leaves holes in the generated code, so it is never returned
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("conversion canceled: %w", ctxErr)
+	}
+	return err
 }
 
 // convertFullPage handles complete HTML documents
-func (c *Converter) convertFullPage(htmlContent string) (string, error) {
+func (c *Converter) convertFullPage(htmlContent string) error {
+	return c.convertDocument(htmlContent, c.mainFuncName("Page"), func(decl *funcDecl) {
+		c.mainFunc = decl
+	})
+}
+
+// convertDocument converts a complete HTML document into a function named
+// after a root patch or name, which declare adds to the generated functions
+func (c *Converter) convertDocument(htmlContent, name string, declare func(*funcDecl)) error {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %w", err)
+		return fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	c.checkDoctype(doc)
+
 	// Find the html element
 	var htmlNode *html.Node
 	var findHTML func(*html.Node)
@@ -68,31 +630,33 @@ func (c *Converter) convertFullPage(htmlContent string) (string, error) {
 	findHTML(doc)
 
 	if htmlNode == nil {
-		return "", fmt.Errorf("no html element found")
+		return fmt.Errorf("no html element found")
 	}
 
-	var buf bytes.Buffer
 	c.collectImports(htmlNode)
-	buf.WriteString(c.generateImports())
-	buf.WriteString("\n")
-	buf.WriteString("func Page() Node {\n")
-	buf.WriteString("\treturn ")
-	code := c.convertNode(htmlNode, 1)
-	buf.WriteString(code)
-	buf.WriteString("\n}\n")
-	return buf.String(), nil
+	c.analyze([]*html.Node{htmlNode})
+	if patched := c.rootPatchFunc(htmlNode); patched != "" {
+		name = patched
+	}
+	decl := &funcDecl{name: c.uniqueFuncName(name), result: "Node"}
+	declare(decl)
+	// Every document is a function of its own, with its own parameters
+	c.paramValues = make(map[string]string)
+	c.scope = decl
+	decl.body = c.convertNode(htmlNode, 1)
+	return nil
 }
 
 // convertFragment handles HTML snippets/fragments
-func (c *Converter) convertFragment(htmlContent string) (string, error) {
+func (c *Converter) convertFragment(r io.Reader) error {
 	// First try to parse as fragment
-	fragments, err := html.ParseFragment(strings.NewReader(htmlContent), nil)
+	fragments, err := html.ParseFragment(r, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML fragment: %w", err)
+		return fmt.Errorf("failed to parse HTML fragment: %w", err)
 	}
 
 	if len(fragments) == 0 {
-		return "", fmt.Errorf("no fragments found")
+		return fmt.Errorf("no fragments found")
 	}
 
 	// Extract actual content from the parsed fragments
@@ -112,36 +676,95 @@ func (c *Converter) convertFragment(htmlContent string) (string, error) {
 		validFragments = append(validFragments, frag)
 	}
 
-	if len(validFragments) == 0 {
-		return "", fmt.Errorf("no convertible content found")
+	if countContent(validFragments) == 0 {
+		return fmt.Errorf("no convertible content found")
 	}
 
-	var buf bytes.Buffer
 	c.collectImportsFromFragments(validFragments)
-	buf.WriteString(c.generateImports())
-	buf.WriteString("\n")
+	c.analyze(validFragments)
+	validFragments = slices.DeleteFunc(validFragments, func(n *html.Node) bool { return c.strippedRoots[n] })
+	if countContent(validFragments) == 0 {
+		return fmt.Errorf("no convertible content found")
+	}
 
-	if len(validFragments) == 1 {
+	funcName, validFragments := rootFuncDirective(validFragments)
+
+	if countContent(validFragments) == 1 {
 		// Single fragment - return it directly
-		buf.WriteString("func Component() Node {\n")
-		buf.WriteString("\treturn ")
-		code := c.convertNode(validFragments[0], 1)
-		buf.WriteString(code)
-		buf.WriteString("\n}\n")
+		if funcName == "" {
+			funcName = c.rootPatchFunc(firstContent(validFragments))
+		}
+		if funcName == "" {
+			name := funcNameFromFilename(c.opts.Filename)
+			if name == "" {
+				name = "Component"
+			}
+			funcName = c.mainFuncName(name)
+		}
+		c.mainFunc = &funcDecl{name: funcName, result: "Node"}
+		c.scope = c.mainFunc
+		codes := c.convertNodeList(validFragments, 1)
+		if len(codes) == 0 {
+			return fmt.Errorf("no convertible content found")
+		}
+		c.mainFunc.body = codes[0]
+	} else if c.opts.FragmentWrapper {
+		// Multiple fragments - wrap them in a single Fragment node
+		c.mainFunc = &funcDecl{name: c.mainFuncName("Component"), result: "Node"}
+		c.scope = c.mainFunc
+		c.mainFunc.body = listBody("Fragment(", ")", c.convertFragmentList(validFragments))
 	} else {
 		// Multiple fragments - return as slice
-		buf.WriteString("func Components() []Node {\n")
-		buf.WriteString("\treturn []Node{\n")
-		for _, frag := range validFragments {
-			buf.WriteString("\t\t")
-			code := c.convertNode(frag, 2)
-			buf.WriteString(code)
-			buf.WriteString(",")
-			buf.WriteString("\n")
-		}
-		buf.WriteString("\t}\n}\n")
+		c.mainFunc = &funcDecl{name: c.mainFuncName("Components"), result: "[]Node"}
+		c.scope = c.mainFunc
+		c.mainFunc.body = listBody("[]Node{", "}", c.convertFragmentList(validFragments))
+	}
+	return nil
+}
+
+// render assembles the generated file once all functions have been converted
+func (c *Converter) render() string {
+	var buf bytes.Buffer
+	c.renderTo(&buf)
+	return c.formatCode(c.standaloneOutput(c.qualifyOutput(buf.String())))
+}
+
+// codeWriter is where generated code is rendered to
+type codeWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
+// renderTo writes the generated file to w
+func (c *Converter) renderTo(w codeWriter) {
+	w.WriteString(c.generateImports())
+	w.WriteString("\n")
+	c.writeDoctype(w)
+	c.writePageMeta(w)
+	c.writeConsts(w)
+	c.writeVariants(w)
+	c.writeThemeTokens(w)
+	c.writeFuncs(w)
+	if c.opts.EditableRegions {
+		w.WriteString("\n")
+		writeRegion(w, "", "declarations")
 	}
-	return buf.String(), nil
+}
+
+// listBody formats converted root nodes as a multi-line list returned by a function
+func listBody(open, close string, codes []string) string {
+	var body bytes.Buffer
+	body.WriteString(open)
+	body.WriteString("\n")
+	for _, code := range codes {
+		body.WriteString("\t\t")
+		body.WriteString(code)
+		body.WriteString(",")
+		body.WriteString("\n")
+	}
+	body.WriteString("\t")
+	body.WriteString(close)
+	return body.String()
 }
 
 // extractActualContent recursively extracts the meaningful content from parsed fragments
@@ -162,11 +785,66 @@ func (c *Converter) extractActualContent(n *html.Node) []*html.Node {
 	} else if n.Type == html.TextNode && strings.TrimSpace(n.Data) != "" {
 		// Non-empty text node
 		result = append(result, n)
+	} else if isDirective(n) {
+		// Directives steer the conversion of the following node
+		result = append(result, n)
 	}
 
 	return result
 }
 
+// analyze runs the passes that inspect the whole tree before code is generated
+func (c *Converter) analyze(nodes []*html.Node) {
+	if c.opts.ReportMutations {
+		c.reportMutations(c.source, nodes)
+	}
+	c.collectPageMeta(nodes)
+	if c.profile != nil {
+		c.applyProfile(c.profile, nodes)
+	}
+	c.checkScriptOrder(nodes)
+	c.stripDesignArtifacts(nodes)
+	c.stripAttributes(nodes)
+	c.flattenWrappers(nodes)
+	c.extractIcons(nodes)
+	c.stubSnippets(nodes)
+	c.stripConsent(nodes)
+	c.fixAccessibility(nodes)
+	c.relevelHeadings(nodes)
+	c.addHeadingIDs(nodes)
+	c.buildTOC(nodes)
+	c.matchComponents(nodes)
+	c.matchPatches(nodes)
+	c.splitComponents(nodes)
+	c.splitSections(nodes)
+	c.normalizeIndicators(nodes)
+	c.resolveDefines(nodes)
+	c.applyPropAttributes(nodes)
+	c.checkEnums(nodes)
+	c.checkRel(nodes)
+	c.collectConstants(nodes)
+	c.collectVariants(nodes)
+	c.checkCustomProperties(nodes)
+	c.checkThemeCoverage(nodes)
+	c.checkCSP(nodes)
+	c.checkEmail(nodes)
+	c.checkEventHandlers(nodes)
+	c.checkTypedAttrs(nodes)
+	c.checkDetails(nodes)
+	c.checkObsolete(nodes)
+	c.checkExpressions(nodes)
+	c.findAccordions(nodes)
+	c.findFavicons(nodes)
+	c.findMetaHelpers(nodes)
+	c.findCSRFInputs(nodes)
+	c.findNavigation(nodes)
+	c.collectForms(nodes)
+	c.findRepeats(nodes)
+	c.collectLinks(nodes)
+	c.collectHxRequests(nodes)
+	c.collectAlpineUsage(nodes)
+}
+
 // collectImportsFromFragments collects imports from multiple fragments
 func (c *Converter) collectImportsFromFragments(fragments []*html.Node) {
 	c.imports["github.com/plainkit/html"] = true
@@ -192,6 +870,9 @@ func (c *Converter) collectImports(n *html.Node) {
 					strings.HasPrefix(attr.Key, ":")) && c.useAlpine {
 					c.imports["github.com/plainkit/alpine"] = true
 				}
+				if isEventHandlerAttr(attr.Key) && c.opts.RewriteHandlers {
+					c.imports["github.com/plainkit/alpine"] = true
+				}
 			}
 		}
 		for child := node.FirstChild; child != nil; child = child.NextSibling {
@@ -204,44 +885,150 @@ func (c *Converter) collectImports(n *html.Node) {
 // generateImports generates the import statements
 func (c *Converter) generateImports() string {
 	var buf bytes.Buffer
-	buf.WriteString("package main\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", c.packageName())
 	buf.WriteString("import (\n")
 
-	// Always import html with dot import for convenience
-	buf.WriteString("\t. \"github.com/plainkit/html\"\n")
-
-	if c.imports["github.com/plainkit/htmx"] {
-		buf.WriteString("\t\"github.com/plainkit/htmx\"\n")
+	std := c.stdlibImports()
+	for _, spec := range std {
+		buf.WriteString("\t" + spec + "\n")
 	}
-	if c.imports["github.com/plainkit/alpine"] {
-		buf.WriteString("\t\"github.com/plainkit/alpine\"\n")
+	if len(std) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, spec := range c.packageImports() {
+		buf.WriteString("\t" + spec + "\n")
+	}
+	if c.opts.EditableRegions {
+		writeRegion(&buf, "\t", "imports")
 	}
 
 	buf.WriteString(")\n")
 	return buf.String()
 }
 
+// importSpecs returns the import declarations the generated code needs
+func (c *Converter) importSpecs() []string {
+	return append(c.stdlibImports(), c.packageImports()...)
+}
+
+// stdlibImports returns the standard library imports of generated helpers, sorted
+func (c *Converter) stdlibImports() []string {
+	var specs []string
+	for path := range c.stdImports {
+		specs = append(specs, strconv.Quote(path))
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// packageImports returns the imports of Plain packages and configured helpers
+func (c *Converter) packageImports() []string {
+	// Always import html, with a dot import unless qualified
+	specs := []string{c.htmlImportSpec()}
+
+	if c.imports["github.com/plainkit/htmx"] {
+		specs = append(specs, `"github.com/plainkit/htmx"`)
+	}
+	if c.imports["github.com/plainkit/alpine"] {
+		specs = append(specs, `"github.com/plainkit/alpine"`)
+	}
+	for _, imp := range c.opts.Imports {
+		if c.imports[imp] {
+			specs = append(specs, formatImport(imp))
+		}
+	}
+	return specs
+}
+
 // convertNode converts an HTML node to Plain code
 func (c *Converter) convertNode(n *html.Node, depth int) string {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return ""
+	}
 	switch n.Type {
 	case html.TextNode:
-		text := strings.TrimSpace(n.Data)
-		if text == "" {
+		if isRawText(n.Parent) {
+			return c.rawText(n)
+		}
+		text, ok := c.nodeText(n)
+		if !ok {
 			return ""
 		}
-		return fmt.Sprintf("T(%s)", c.quoteValue(text))
+		if code, ok := c.slotText(n); ok {
+			return code
+		}
+		if code, ok := c.paramText(n); ok {
+			return code
+		}
+		if code, ok := c.propText(n, text); ok {
+			return code
+		}
+		code := fmt.Sprintf("T(%s)", c.textValue(n, text))
+		if c.opts.AnnotateLang {
+			code += c.langAnnotation(n)
+		}
+		return code
 
 	case html.ElementNode:
+		if patch, ok := c.patches[n]; ok {
+			return c.convertPatched(n, patch, depth)
+		}
+		if n == c.consentStub {
+			c.useQualifier(c.opts.ConsentStub)
+			return c.opts.ConsentStub
+		}
+		if code, ok := c.snippetCalls[n]; ok {
+			c.useQualifier(code)
+			return code
+		}
+		if icon, ok := c.iconCalls[n]; ok {
+			return c.iconCall(icon)
+		}
+		if icon, ok := c.iconContent[n]; ok {
+			return c.convertElementWithChildren(n, depth, []string{c.iconCall(icon)})
+		}
+		if c.hoistsSVG(n) {
+			return c.hoistSVG(n)
+		}
+		if group, ok := c.accordions[n]; ok {
+			return c.accordionCall(group, depth)
+		}
+		if c.accordionMembers[n] {
+			return ""
+		}
+		if c.faviconMembers[n] {
+			if n == c.favicons[0] {
+				return c.faviconsCall()
+			}
+			return ""
+		}
+		if c.indicators[n] {
+			return c.indicatorCall(n)
+		}
+		if call, ok := c.metaCalls[n]; ok {
+			return c.metaCall(call)
+		}
+		if p, ok := c.paginations[n]; ok {
+			return c.paginationCall(p)
+		}
+		if b, ok := c.breadcrumbs[n]; ok {
+			return c.breadcrumbCall(b, depth)
+		}
+		if c.csrfInputs[n] && c.opts.CSRFHelper != "" {
+			c.useQualifier(c.opts.CSRFHelper)
+			return c.opts.CSRFHelper
+		}
+		if group, ok := c.repeats[n]; ok {
+			return c.repeatCall(group, n)
+		}
 		return c.convertElement(n, depth)
 
+	case html.CommentNode:
+		return c.commentNode(n)
+
 	case html.DocumentNode:
 		// Process children
-		var children []string
-		for child := n.FirstChild; child != nil; child = child.NextSibling {
-			if code := c.convertNode(child, depth); code != "" {
-				children = append(children, code)
-			}
-		}
+		children := c.convertChildren(n, depth)
 		if len(children) == 1 {
 			return children[0]
 		}
@@ -252,30 +1039,84 @@ func (c *Converter) convertNode(n *html.Node, depth int) string {
 	}
 }
 
+// textValue returns the string expression of the converted text of a node
+func (c *Converter) textValue(n *html.Node, text string) string {
+	if expr, ok := c.propExpr("", text); ok && !isRawText(n.Parent) {
+		return expr
+	}
+	if expr, ok := c.placeholderExpr("", text); ok && !isRawText(n.Parent) {
+		return expr
+	}
+	if preformatted(n) && strings.Contains(text, "\t") && canUseRawString(text) {
+		// Tabs of code samples stay readable in a raw string
+		return rawStringLiteral(text)
+	}
+	return c.quoteValue(text)
+}
+
 // convertElement converts an HTML element to Plain code
 func (c *Converter) convertElement(n *html.Node, depth int) string {
+	return c.convertElementWithChildren(n, depth, c.convertChildren(n, depth+1))
+}
+
+// convertElementWithChildren converts an HTML element using already converted children
+func (c *Converter) convertElementWithChildren(n *html.Node, depth int, children []string) string {
 	var buf bytes.Buffer
 
 	// Convert tag name to Plain function with context
 	funcName := c.tagToFunctionWithContext(n.Data, n)
-	buf.WriteString(funcName)
-	buf.WriteString("(")
+	component, isComponent := c.componentNodes[n]
+	if isComponent {
+		funcName = component.helper
+		c.useQualifier(funcName)
+	}
 
 	var args []string
+	if funcName == "" {
+		// Custom and unknown elements have no constructor of their own
+		funcName = "Element"
+		args = append(args, c.quoteValue(n.Data))
+	}
+	buf.WriteString(funcName)
+	buf.WriteString("(")
 
 	// Process attributes
 	for _, attr := range n.Attr {
+		if isComponent && attr.Key == "class" {
+			if attr.Val = withoutClass(attr.Val, component.class); attr.Val == "" {
+				continue
+			}
+		}
+		if attrCode, ok := c.slotAttr(n, attr); ok {
+			args = append(args, attrCode)
+			continue
+		}
+		if attrCode, ok := c.patchAttribute(n, attr); ok {
+			args = append(args, attrCode)
+			continue
+		}
+		if attrCode, ok := c.faviconAttribute(n, attr); ok {
+			args = append(args, attrCode)
+			continue
+		}
+		if attrCode, ok := c.csrfAttribute(n, attr); ok {
+			args = append(args, attrCode)
+			continue
+		}
+		if attrCode, ok := c.paramAttribute(n, attr); ok {
+			args = append(args, attrCode)
+			continue
+		}
+		if attrCode, ok := c.svgAttribute(n, attr); ok {
+			args = append(args, attrCode)
+			continue
+		}
 		if attrCode := c.convertAttribute(attr, n.Data); attrCode != "" {
 			args = append(args, attrCode)
 		}
 	}
 
-	// Process children
-	for child := n.FirstChild; child != nil; child = child.NextSibling {
-		if code := c.convertNode(child, depth+1); code != "" {
-			args = append(args, code)
-		}
-	}
+	args = append(args, children...)
 
 	if len(args) > 0 {
 		if len(args) > 3 || containsMultilineContent(args) {
@@ -300,54 +1141,13 @@ func (c *Converter) convertElement(n *html.Node, depth int) string {
 
 // tagToFunctionWithContext converts HTML tag names to Plain function names with context awareness
 func (c *Converter) tagToFunctionWithContext(tag string, node *html.Node) string {
-	// Special cases with context-specific function names
-	specialTags := map[string]string{
-		"a":          "A",
-		"b":          "B",
-		"i":          "I",
-		"p":          "P",
-		"br":         "Br",
-		"hr":         "Hr",
-		"h1":         "H1",
-		"h2":         "H2",
-		"h3":         "H3",
-		"h4":         "H4",
-		"h5":         "H5",
-		"h6":         "H6",
-		"ul":         "Ul",
-		"ol":         "Ol",
-		"li":         "Li",
-		"dl":         "Dl",
-		"dt":         "Dt",
-		"dd":         "Dd",
-		"em":         "Em",
-		"abbr":       "Abbr",
-		"kbd":        "Kbd",
-		"var":        "Var",
-		"dfn":        "Dfn",
-		"del":        "Del",
-		"ins":        "Ins",
-		"sub":        "Sub",
-		"sup":        "Sup",
-		"col":        "Col",
-		"colgroup":   "ColGroup",
-		"tbody":      "Tbody",
-		"thead":      "Thead",
-		"tfoot":      "Tfoot",
-		"tr":         "Tr",
-		"td":         "Td",
-		"th":         "Th",
-		"fieldset":   "Fieldset",
-		"legend":     "Legend",
-		"datalist":   "Datalist",
-		"optgroup":   "OptGroup",
-		"textarea":   "Textarea",
-		"blockquote": "Blockquote",
-		"figcaption": "Figcaption",
-	}
-
-	if special, ok := specialTags[tag]; ok {
-		return special
+	if funcName, ok := c.elementOverride(tag); ok {
+		return funcName
+	}
+	if isSVG(node) && tag != "svg" {
+		// SVG elements such as title or a share the names of HTML elements
+		// but not their constructors
+		return ""
 	}
 
 	// Check for context-specific function names
@@ -366,9 +1166,7 @@ func (c *Converter) tagToFunctionWithContext(tag string, node *html.Node) string
 		return "Label"
 	}
 
-	// Title case conversion for standard tags
-	caser := cases.Title(language.English)
-	return caser.String(tag)
+	return elementFuncs[tag]
 }
 
 // isInHeadContext checks if a node is within a head element
@@ -399,15 +1197,47 @@ func (c *Converter) isInFormContext(node *html.Node) bool {
 func (c *Converter) quoteValue(val string) string {
 	// Check if the value contains newlines or is complex JavaScript
 	if strings.Contains(val, "\n") || (len(val) > 50 && (strings.Contains(val, "{") || strings.Contains(val, "function"))) {
-		// Use backticks for multiline or complex content
-		// Escape any existing backticks
-		val = strings.ReplaceAll(val, "`", "` + \"`\" + `")
-		return fmt.Sprintf("`%s`", val)
+		// Raw strings drop carriage returns and cannot hold other control characters
+		if canUseRawString(val) {
+			return rawStringLiteral(val)
+		}
 	}
 
 	// Use regular double quotes for simple content
-	val = strings.ReplaceAll(val, `"`, `\"`)
-	return fmt.Sprintf("\"%s\"", val)
+	return strconv.Quote(val)
+}
+
+// canUseRawString reports whether val survives a round trip through a raw string literal
+func canUseRawString(val string) bool {
+	if !utf8.ValidString(val) {
+		return false
+	}
+	for _, r := range val {
+		if r == '\r' || r == '\uFEFF' || (unicode.IsControl(r) && r != '\n' && r != '\t') {
+			return false
+		}
+	}
+	return true
+}
+
+// rawStringLiteral quotes val with backticks. Backticks inside the value, as
+// used by JavaScript template literals, are spliced in as "`" so that the
+// concatenated constant equals val exactly.
+func rawStringLiteral(val string) string {
+	parts := strings.Split(val, "`")
+	var pieces []string
+	for i, part := range parts {
+		if part != "" {
+			pieces = append(pieces, "`"+part+"`")
+		}
+		if i < len(parts)-1 {
+			pieces = append(pieces, "\"`\"")
+		}
+	}
+	if len(pieces) == 0 {
+		return "``"
+	}
+	return strings.Join(pieces, " + ")
 }
 
 // convertAttribute converts HTML attributes to Plain attributes
@@ -415,6 +1245,10 @@ func (c *Converter) convertAttribute(attr html.Attribute, tagName string) string
 	key := attr.Key
 	val := attr.Val
 
+	if code, ok := c.attributeOverride(key, val, tagName); ok {
+		return code
+	}
+
 	// Handle htmx attributes
 	if strings.HasPrefix(key, "hx-") && c.useHTMX {
 		return c.convertHTMXAttribute(key, val)
@@ -433,166 +1267,100 @@ func (c *Converter) convertAttribute(attr html.Attribute, tagName string) string
 		}
 	}
 
+	// Rewrite inline event handlers into Alpine event attributes
+	if c.opts.RewriteHandlers && isEventHandlerAttr(key) {
+		alpineKey, expr := alpineHandler(key, val)
+		return c.convertAlpineEventAttribute(alpineKey, expr)
+	}
+	if funcName, ok := eventHandlerFuncs[key]; ok && c.opts.Events {
+		return fmt.Sprintf("%s(%s)", funcName, c.attrValue(key, val))
+	}
+
 	// Handle standard HTML attributes with context-specific functions
 	switch key {
 	case "class":
-		return fmt.Sprintf("Class(%s)", c.quoteValue(val))
-	case "id":
-		return fmt.Sprintf("Id(%s)", c.quoteValue(val))
-	case "style":
-		return fmt.Sprintf("Style(%s)", c.quoteValue(val))
-	case "href":
-		return fmt.Sprintf("Href(%s)", c.quoteValue(val))
-	case "src":
-		if tagName == "script" {
-			return fmt.Sprintf("ScriptSrc(%s)", c.quoteValue(val))
-		}
-		return fmt.Sprintf("Src(%s)", c.quoteValue(val))
-	case "type":
-		if tagName == "input" {
-			return fmt.Sprintf("InputType(%s)", c.quoteValue(val))
-		}
-		if tagName == "button" {
-			return fmt.Sprintf("ButtonType(%s)", c.quoteValue(val))
-		}
-		return fmt.Sprintf("Type(%s)", c.quoteValue(val))
-	case "value":
-		if tagName == "input" {
-			return fmt.Sprintf("InputValue(%s)", c.quoteValue(val))
-		}
-		return fmt.Sprintf("Value(%s)", c.quoteValue(val))
-	case "name":
-		if tagName == "input" {
-			return fmt.Sprintf("InputName(%s)", c.quoteValue(val))
-		}
-		if tagName == "meta" {
-			return fmt.Sprintf("Name(%s)", c.quoteValue(val))
-		}
-		return fmt.Sprintf("Name(%s)", c.quoteValue(val))
-	case "placeholder":
-		return fmt.Sprintf("Placeholder(%s)", c.quoteValue(val))
-	case "disabled":
-		return "Disabled()"
-	case "checked":
-		return "Checked()"
-	case "readonly":
-		return "ReadOnly()"
-	case "required":
-		return "Required()"
-	case "multiple":
-		return "Multiple()"
-	case "selected":
-		return "Selected()"
-	case "defer":
-		return "Defer()"
-	case "async":
-		return "Async()"
-	case "charset":
-		return fmt.Sprintf("Charset(%s)", c.quoteValue(val))
-	case "content":
-		return fmt.Sprintf("Content(%s)", c.quoteValue(val))
-	case "method":
-		return fmt.Sprintf("Method(%s)", c.quoteValue(val))
-	case "action":
-		return fmt.Sprintf("Action(%s)", c.quoteValue(val))
-	case "target":
-		return fmt.Sprintf("Target(%s)", c.quoteValue(val))
-	case "rel":
-		return fmt.Sprintf("Rel(%s)", c.quoteValue(val))
-	case "alt":
-		return fmt.Sprintf("Alt(%s)", c.quoteValue(val))
-	case "title":
-		return fmt.Sprintf("Title(%s)", c.quoteValue(val))
-	case "width":
-		return fmt.Sprintf("Width(%s)", c.quoteValue(val))
-	case "height":
-		return fmt.Sprintf("Height(%s)", c.quoteValue(val))
-	case "colspan":
-		return fmt.Sprintf("ColSpan(%s)", c.quoteValue(val))
-	case "rowspan":
-		return fmt.Sprintf("RowSpan(%s)", c.quoteValue(val))
-	case "for":
-		return fmt.Sprintf("For(%s)", c.quoteValue(val))
-	case "maxlength":
-		return fmt.Sprintf("MaxLength(%s)", c.quoteValue(val))
-	case "minlength":
-		return fmt.Sprintf("MinLength(%s)", c.quoteValue(val))
-	case "min":
-		return fmt.Sprintf("Min(%s)", c.quoteValue(val))
-	case "max":
-		return fmt.Sprintf("Max(%s)", c.quoteValue(val))
-	case "step":
-		return fmt.Sprintf("Step(%s)", c.quoteValue(val))
-	case "pattern":
-		return fmt.Sprintf("Pattern(%s)", c.quoteValue(val))
-	case "rows":
-		return fmt.Sprintf("Rows(%s)", c.quoteValue(val))
-	case "cols":
-		return fmt.Sprintf("Cols(%s)", c.quoteValue(val))
-	case "autocomplete":
-		return fmt.Sprintf("AutoComplete(%s)", c.quoteValue(val))
-	case "autofocus":
-		return "Autofocus()"
-	default:
-		// Handle data- and aria- attributes
-		if strings.HasPrefix(key, "data-") {
-			dataKey := strings.TrimPrefix(key, "data-")
-			return fmt.Sprintf("Data(%s, %s)", c.quoteValue(dataKey), c.quoteValue(val))
-		}
-		if strings.HasPrefix(key, "aria-") {
-			ariaKey := strings.TrimPrefix(key, "aria-")
-			return fmt.Sprintf("Aria(%s, %s)", c.quoteValue(ariaKey), c.quoteValue(val))
-		}
-		if key == "role" {
-			return fmt.Sprintf("Role(%s)", c.quoteValue(val))
+		if expr, ok := c.darkClassExpr(val); ok {
+			return fmt.Sprintf("Class(%s)", expr)
 		}
-		if key == "tabindex" {
-			return fmt.Sprintf("TabIndex(%s)", c.quoteValue(val))
+	case "rel":
+		// Rel takes the token list as one string
+		val = relValue(val)
+	case "nonce":
+		if c.opts.StripNonce {
+			// Nonces are generated per request, so the caller has to supply one
+			if c.scope != nil {
+				c.scope.addParam("nonce", "string")
+			}
+			return "Nonce(nonce)"
 		}
-		// For any unknown attributes, use Custom
 		return fmt.Sprintf("Custom(%s, %s)", c.quoteValue(key), c.quoteValue(val))
 	}
+	if funcName, ok := booleanAttributeFuncs[key]; ok {
+		return funcName + "()"
+	}
+	if funcName := attributeFunc(key, tagName); funcName != "" {
+		return fmt.Sprintf("%s(%s)", funcName, c.attrValue(key, val))
+	}
+
+	// Handle data- and aria- attributes
+	if mapping, ok := c.opts.DataAttributes[key]; ok {
+		if code, err := c.typedAttr(mapping, val); err == nil {
+			return code
+		}
+	}
+	if strings.HasPrefix(key, "data-") {
+		dataKey := strings.TrimPrefix(key, "data-")
+		return fmt.Sprintf("Data(%s, %s)", c.quoteValue(dataKey), c.attrValue(key, val))
+	}
+	if strings.HasPrefix(key, "aria-") {
+		ariaKey := strings.TrimPrefix(key, "aria-")
+		return fmt.Sprintf("Aria(%s, %s)", c.quoteValue(ariaKey), c.attrValue(key, val))
+	}
+	// For any unknown attributes, use Custom
+	return fmt.Sprintf("Custom(%s, %s)", c.quoteValue(key), c.attrValue(key, val))
+}
+
+// htmxAttributes maps hx- attributes to htmx functions
+var htmxAttributes = map[string]string{
+	"hx-get":          "HxGet",
+	"hx-post":         "HxPost",
+	"hx-put":          "HxPut",
+	"hx-patch":        "HxPatch",
+	"hx-delete":       "HxDelete",
+	"hx-trigger":      "HxTrigger",
+	"hx-target":       "HxTarget",
+	"hx-swap":         "HxSwap",
+	"hx-swap-oob":     "HxSwapOob",
+	"hx-indicator":    "HxIndicator",
+	"hx-push-url":     "HxPushUrl",
+	"hx-replace-url":  "HxReplaceUrl",
+	"hx-select":       "HxSelect",
+	"hx-select-oob":   "HxSelectOob",
+	"hx-vals":         "HxVals",
+	"hx-headers":      "HxHeaders",
+	"hx-include":      "HxInclude",
+	"hx-params":       "HxParams",
+	"hx-confirm":      "HxConfirm",
+	"hx-prompt":       "HxPrompt",
+	"hx-validate":     "HxValidate",
+	"hx-disabled-elt": "HxDisabledElt",
+	"hx-ext":          "HxExt",
+	"hx-boost":        "HxBoost",
+	"hx-preserve":     "HxPreserve",
+	"hx-sse":          "HxSse",
+	"hx-ws":           "HxWs",
+	"hx-sync":         "HxSync",
+	"hx-encoding":     "HxEncoding",
+	"hx-disinherit":   "HxDisinherit",
 }
 
+// htmxBooleans are the hx- attributes whose functions take a bool, or nothing when true
+var htmxBooleans = map[string]bool{"hx-boost": true, "hx-preserve": true, "hx-validate": true}
+
 // convertHTMXAttribute converts htmx attributes
 func (c *Converter) convertHTMXAttribute(key, val string) string {
-	// Map hx- attributes to htmx functions
-	htmxMap := map[string]string{
-		"hx-get":          "HxGet",
-		"hx-post":         "HxPost",
-		"hx-put":          "HxPut",
-		"hx-patch":        "HxPatch",
-		"hx-delete":       "HxDelete",
-		"hx-trigger":      "HxTrigger",
-		"hx-target":       "HxTarget",
-		"hx-swap":         "HxSwap",
-		"hx-swap-oob":     "HxSwapOob",
-		"hx-indicator":    "HxIndicator",
-		"hx-push-url":     "HxPushUrl",
-		"hx-replace-url":  "HxReplaceUrl",
-		"hx-select":       "HxSelect",
-		"hx-select-oob":   "HxSelectOob",
-		"hx-vals":         "HxVals",
-		"hx-headers":      "HxHeaders",
-		"hx-include":      "HxInclude",
-		"hx-params":       "HxParams",
-		"hx-confirm":      "HxConfirm",
-		"hx-prompt":       "HxPrompt",
-		"hx-validate":     "HxValidate",
-		"hx-disabled-elt": "HxDisabledElt",
-		"hx-ext":          "HxExt",
-		"hx-boost":        "HxBoost",
-		"hx-preserve":     "HxPreserve",
-		"hx-sse":          "HxSse",
-		"hx-ws":           "HxWs",
-		"hx-sync":         "HxSync",
-		"hx-encoding":     "HxEncoding",
-		"hx-disinherit":   "HxDisinherit",
-	}
-
-	if funcName, ok := htmxMap[key]; ok {
-		if key == "hx-boost" || key == "hx-preserve" || key == "hx-validate" {
-			// Boolean attributes
+	if funcName, ok := htmxAttributes[key]; ok {
+		if htmxBooleans[key] {
 			if val == "true" {
 				return fmt.Sprintf("htmx.%s()", funcName)
 			}
@@ -605,36 +1373,39 @@ func (c *Converter) convertHTMXAttribute(key, val string) string {
 	return fmt.Sprintf("Custom(%s, %s)", c.quoteValue(key), c.quoteValue(val))
 }
 
+// alpineAttributes maps x- attributes to alpine functions
+var alpineAttributes = map[string]string{
+	"x-data":                   "XData",
+	"x-init":                   "XInit",
+	"x-show":                   "XShow",
+	"x-if":                     "XIf",
+	"x-for":                    "XFor",
+	"x-html":                   "XHtml",
+	"x-text":                   "XText",
+	"x-model":                  "XModel",
+	"x-modelable":              "XModelable",
+	"x-effect":                 "XEffect",
+	"x-ref":                    "XRef",
+	"x-teleport":               "XTeleport",
+	"x-ignore":                 "XIgnore",
+	"x-id":                     "XId",
+	"x-cloak":                  "XCloak",
+	"x-transition":             "XTransition",
+	"x-transition:enter":       "XTransitionEnter",
+	"x-transition:enter-start": "XTransitionEnterStart",
+	"x-transition:enter-end":   "XTransitionEnterEnd",
+	"x-transition:leave":       "XTransitionLeave",
+	"x-transition:leave-start": "XTransitionLeaveStart",
+	"x-transition:leave-end":   "XTransitionLeaveEnd",
+	"x-model.lazy":             "XModelLazy",
+	"x-model.number":           "XModelNumber",
+}
+
+// alpineFlags are the x- attributes whose functions take no argument
+var alpineFlags = map[string]bool{"x-cloak": true, "x-ignore": true, "x-transition": true}
+
 // convertAlpineAttribute converts Alpine.js x- attributes
 func (c *Converter) convertAlpineAttribute(key, val string) string {
-	// Map x- attributes to alpine functions
-	alpineMap := map[string]string{
-		"x-data":                   "XData",
-		"x-init":                   "XInit",
-		"x-show":                   "XShow",
-		"x-if":                     "XIf",
-		"x-for":                    "XFor",
-		"x-html":                   "XHtml",
-		"x-text":                   "XText",
-		"x-model":                  "XModel",
-		"x-modelable":              "XModelable",
-		"x-effect":                 "XEffect",
-		"x-ref":                    "XRef",
-		"x-teleport":               "XTeleport",
-		"x-ignore":                 "XIgnore",
-		"x-id":                     "XId",
-		"x-cloak":                  "XCloak",
-		"x-transition":             "XTransition",
-		"x-transition:enter":       "XTransitionEnter",
-		"x-transition:enter-start": "XTransitionEnterStart",
-		"x-transition:enter-end":   "XTransitionEnterEnd",
-		"x-transition:leave":       "XTransitionLeave",
-		"x-transition:leave-start": "XTransitionLeaveStart",
-		"x-transition:leave-end":   "XTransitionLeaveEnd",
-		"x-model.lazy":             "XModelLazy",
-		"x-model.number":           "XModelNumber",
-	}
-
 	// Check for x-on:event format
 	if strings.HasPrefix(key, "x-on:") {
 		event := strings.TrimPrefix(key, "x-on:")
@@ -644,7 +1415,7 @@ func (c *Converter) convertAlpineAttribute(key, val string) string {
 	// Check for x-bind:attr format
 	if strings.HasPrefix(key, "x-bind:") {
 		attr := strings.TrimPrefix(key, "x-bind:")
-		return fmt.Sprintf("alpine.XBind(%s, %s)", c.quoteValue(attr), c.quoteValue(val))
+		return fmt.Sprintf("alpine.XBind(%s, %s)", c.quoteValue(attr), c.bindValue(attr, val))
 	}
 
 	// Check for x-model with debounce
@@ -656,9 +1427,12 @@ func (c *Converter) convertAlpineAttribute(key, val string) string {
 		}
 	}
 
-	if funcName, ok := alpineMap[key]; ok {
-		if key == "x-cloak" || key == "x-ignore" || key == "x-transition" {
-			// No-argument attributes
+	if key == "x-data" {
+		return fmt.Sprintf("alpine.XData(%s)", c.dataValue(val))
+	}
+
+	if funcName, ok := alpineAttributes[key]; ok {
+		if alpineFlags[key] {
 			return fmt.Sprintf("alpine.%s()", funcName)
 		}
 		return fmt.Sprintf("alpine.%s(%s)", funcName, c.quoteValue(val))
@@ -668,6 +1442,30 @@ func (c *Converter) convertAlpineAttribute(key, val string) string {
 	return fmt.Sprintf("Custom(%s, %s)", c.quoteValue(key), c.quoteValue(val))
 }
 
+// alpineEventCombos maps common @event.modifier combinations to alpine functions
+var alpineEventCombos = map[string]string{
+	"click.away":     "AtClickAway",
+	"click.outside":  "AtClickOutside",
+	"click.prevent":  "AtClickPrevent",
+	"click.stop":     "AtClickStop",
+	"submit.prevent": "AtSubmitPrevent",
+	"keydown.escape": "AtKeydownEscape",
+	"keydown.enter":  "AtKeydownEnter",
+	"keydown.window": "AtKeydownWindow",
+}
+
+// alpineEvents maps simple @ events to alpine functions
+var alpineEvents = map[string]string{
+	"click":      "AtClick",
+	"submit":     "AtSubmit",
+	"change":     "AtChange",
+	"input":      "AtInput",
+	"keydown":    "AtKeydown",
+	"keyup":      "AtKeyup",
+	"mouseenter": "AtMouseenter",
+	"mouseleave": "AtMouseleave",
+}
+
 // convertAlpineEventAttribute converts Alpine @ event attributes
 func (c *Converter) convertAlpineEventAttribute(key, val string) string {
 	// Remove @ prefix
@@ -681,20 +1479,8 @@ func (c *Converter) convertAlpineEventAttribute(key, val string) string {
 		// Has modifiers
 		modifiers := strings.Join(parts[1:], ".")
 
-		// Common event+modifier combinations
-		commonCombos := map[string]string{
-			"click.away":     "AtClickAway",
-			"click.outside":  "AtClickOutside",
-			"click.prevent":  "AtClickPrevent",
-			"click.stop":     "AtClickStop",
-			"submit.prevent": "AtSubmitPrevent",
-			"keydown.escape": "AtKeydownEscape",
-			"keydown.enter":  "AtKeydownEnter",
-			"keydown.window": "AtKeydownWindow",
-		}
-
 		combo := event + "." + modifiers
-		if funcName, ok := commonCombos[combo]; ok {
+		if funcName, ok := alpineEventCombos[combo]; ok {
 			return fmt.Sprintf("alpine.%s(%s)", funcName, c.quoteValue(val))
 		}
 
@@ -702,19 +1488,7 @@ func (c *Converter) convertAlpineEventAttribute(key, val string) string {
 		return fmt.Sprintf("Custom(%s, %s)", c.quoteValue(key), c.quoteValue(val))
 	}
 
-	// Simple @ events
-	eventMap := map[string]string{
-		"click":      "AtClick",
-		"submit":     "AtSubmit",
-		"change":     "AtChange",
-		"input":      "AtInput",
-		"keydown":    "AtKeydown",
-		"keyup":      "AtKeyup",
-		"mouseenter": "AtMouseenter",
-		"mouseleave": "AtMouseleave",
-	}
-
-	if funcName, ok := eventMap[event]; ok {
+	if funcName, ok := alpineEvents[event]; ok {
 		return fmt.Sprintf("alpine.%s(%s)", funcName, c.quoteValue(val))
 	}
 
@@ -722,25 +1496,25 @@ func (c *Converter) convertAlpineEventAttribute(key, val string) string {
 	return fmt.Sprintf("alpine.At(%s, %s)", c.quoteValue(event), c.quoteValue(val))
 }
 
+// alpineBinds maps common : bind attributes to alpine functions
+var alpineBinds = map[string]string{
+	"class":    "ColonClass",
+	"style":    "ColonStyle",
+	"disabled": "ColonDisabled",
+	"value":    "ColonValue",
+	"key":      "Colon",
+}
+
 // convertAlpineBindAttribute converts Alpine : bind attributes
 func (c *Converter) convertAlpineBindAttribute(key, val string) string {
 	// Remove : prefix
 	attr := strings.TrimPrefix(key, ":")
 
-	// Common bind attributes
-	bindMap := map[string]string{
-		"class":    "ColonClass",
-		"style":    "ColonStyle",
-		"disabled": "ColonDisabled",
-		"value":    "ColonValue",
-		"key":      "Colon",
-	}
-
-	if funcName, ok := bindMap[attr]; ok {
+	if funcName, ok := alpineBinds[attr]; ok {
 		if funcName == "Colon" {
 			return fmt.Sprintf("alpine.Colon(%s, %s)", c.quoteValue(attr), c.quoteValue(val))
 		}
-		return fmt.Sprintf("alpine.%s(%s)", funcName, c.quoteValue(val))
+		return fmt.Sprintf("alpine.%s(%s)", funcName, c.bindValue(attr, val))
 	}
 
 	// Generic : bind
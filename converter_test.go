@@ -1,8 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"go/constant"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
 	"strings"
 	"testing"
+
+	"golang.org/x/net/html"
 )
 
 func TestConvertFullPage(t *testing.T) {
@@ -106,10 +116,10 @@ func TestConvertBasicHTML(t *testing.T) {
 			expected: []string{
 				"Div(",
 				"P(",
-				`T("Paragraph")`,
+				`T("Paragraph ")`,
 				"Strong(",
 				`T("bold")`,
-				`T("text")`,
+				`T(" text")`,
 			},
 		},
 		{
@@ -424,3 +434,2502 @@ func TestConvertSpecialAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertAnnotateLang(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html lang="en">
+<body>
+	<p>Hello</p>
+	<p lang="fr">Bonjour</p>
+	<div dir="rtl" lang="ar"><span>مرحبا</span></div>
+</body>
+</html>`
+
+	converter := NewConverterWithOptions(Options{AnnotateLang: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`T("Hello"))`,
+		`T("Bonjour") /* lang=fr */`,
+		`T("مرحبا") /* lang=ar dir=rtl */`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertCommentDirectives(t *testing.T) {
+	input := `<!-- plainkit:func Landing -->
+<main>
+	<!-- plainkit:func HeroSection -->
+	<section class="hero">
+		<!-- plainkit:param title -->
+		<h1>Welcome</h1>
+	</section>
+	<!-- plainkit:skip -->
+	<div>debug</div>
+	<!-- plainkit:bogus -->
+	<p>Footer</p>
+</main>`
+
+	converter := NewConverter(false, false)
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"func Landing(title string) Node",
+		"HeroSection(title)",
+		"func HeroSection(title string) Node",
+		"H1(T(title))",
+		`T("Footer")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "debug") {
+		t.Errorf("Expected skipped element to be omitted.\nOutput:\n%s", result)
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "directive-unknown" {
+		t.Errorf("Expected a single directive-unknown diagnostic, got %v", diags)
+	}
+}
+
+func TestConvertHoistConstants(t *testing.T) {
+	input := `<nav>
+	<a class="nav-link active" href="/home">Home</a>
+	<a class="nav-link active" href="/about">About</a>
+	<a class="nav-link active" href="/contact">Contact</a>
+</nav>`
+
+	converter := NewConverterWithOptions(Options{HoistConstants: 3})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"const (",
+		`classNavLinkActive = "nav-link active"`,
+		"Class(classNavLinkActive)",
+		`Href("/home")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Only the attributes counted for a constant reference it
+	result, err = NewConverterWithOptions(Options{HoistConstants: 2}).Convert(
+		`<div><a class="btn" href="/a">A</a><a class="btn" href="/b">B</a><input value="btn" title="btn"></div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Input(InputValue("btn"), Title("btn"))`) || !strings.Contains(result, "Class(classBtn)") {
+		t.Errorf("Expected the constant only in class attributes.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertNonceAndCSP(t *testing.T) {
+	input := `<div>
+	<script nonce="r4nd0m">init()</script>
+	<script>track()</script>
+	<p style="color: red">Hi</p>
+</div>`
+
+	converter := NewConverterWithOptions(Options{
+		StripNonce: true,
+		CSP:        "default-src 'self'; script-src 'nonce-abc'; style-src 'self' 'unsafe-inline'",
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	for _, exp := range []string{"func Component(nonce string) Node", "Nonce(nonce)"} {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "r4nd0m") {
+		t.Errorf("Expected nonce value to be stripped.\nOutput:\n%s", result)
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "csp-inline-script" {
+		t.Errorf("Expected a single csp-inline-script diagnostic, got %v", diags)
+	}
+}
+
+func TestCSPHashSource(t *testing.T) {
+	content := "init()"
+	policy := parseCSP("script-src '" + cspHash("sha256", content) + "'")
+	sources, _ := policy.sources("script-src")
+	if !allowsInline(sources, "", content, true) {
+		t.Error("Expected script matching the hash source to be allowed")
+	}
+	if allowsInline(sources, "", "other()", true) {
+		t.Error("Expected script not matching the hash source to be blocked")
+	}
+}
+
+func TestConvertEmailChecks(t *testing.T) {
+	input := `<table role="presentation">
+	<tr><td style="padding: 8px"><img src="https://example.com/logo.png" alt="Logo" width="100" height="40"></td></tr>
+	<tr><td><div style="display: flex"><a href="/unsubscribe">Unsubscribe</a></div></td></tr>
+</table>`
+
+	converter := NewConverterWithOptions(Options{Email: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Style("padding: 8px")`) {
+		t.Errorf("Expected inline styles to be preserved.\nOutput:\n%s", result)
+	}
+
+	codes := make(map[string]bool)
+	for _, d := range converter.Diagnostics() {
+		codes[d.Code] = true
+	}
+	for _, code := range []string{"email-layout", "email-relative-url"} {
+		if !codes[code] {
+			t.Errorf("Expected %s diagnostic, got %v", code, converter.Diagnostics())
+		}
+	}
+	for _, code := range []string{"email-image-alt", "email-image-size", "email-table-role"} {
+		if codes[code] {
+			t.Errorf("Unexpected %s diagnostic", code)
+		}
+	}
+}
+
+func TestConvertEmailClassOnly(t *testing.T) {
+	body := `<table class="wrapper" role="presentation"><tr><td class="cell">A</td><td class="cell">B</td>` +
+		`<td class="cell" style="color: red">C</td></tr></table>`
+
+	// Classes are reported once for the document, and only with a style block
+	for _, tt := range []struct {
+		input string
+		count int
+	}{
+		{`<style>.cell { padding: 8px }</style>` + body, 1},
+		{body, 0},
+	} {
+		converter := NewConverterWithOptions(Options{Email: true})
+		if _, err := converter.Convert(tt.input); err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		var found []Diagnostic
+		for _, d := range converter.Diagnostics() {
+			if d.Code == "email-class-only" {
+				found = append(found, d)
+			}
+		}
+		if len(found) != tt.count {
+			t.Errorf("Expected %d email-class-only diagnostic(s), got %v", tt.count, found)
+		}
+		if len(found) == 1 && !strings.HasPrefix(found[0].Message, "3 element(s)") {
+			t.Errorf("Expected the class-only elements to be counted, got %q", found[0].Message)
+		}
+	}
+}
+
+func TestConvertFragmentWrapper(t *testing.T) {
+	input := `<div>Fragment 1</div><p>Fragment 2</p>`
+
+	converter := NewConverterWithOptions(Options{FragmentWrapper: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"func Component() Node",
+		"return Fragment(",
+		`Div(T("Fragment 1")),`,
+		`P(T("Fragment 2")),`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertFragmentFuncs(t *testing.T) {
+	input := `<header id="top">Hi</header><nav class="main-nav">Links</nav>` +
+		`<section>A</section><section>B</section>` +
+		`<!-- plainkit:func SiteFooter --><footer>Bye</footer>`
+
+	converter := NewConverterWithOptions(Options{FragmentFuncs: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"func Components() []Node",
+		"Top(),\n\t\tMainNav(),\n\t\tSectionFragment(),\n\t\tSectionFragment2(),\n\t\tSiteFooter(),",
+		"func Top() Node {\n\treturn Header(Id(\"top\"), T(\"Hi\"))",
+		"func MainNav() Node {\n\treturn Nav(Class(\"main-nav\"), T(\"Links\"))",
+		"func SectionFragment2() Node {\n\treturn Section(T(\"B\"))",
+		"func SiteFooter() Node {\n\treturn Footer(",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertComments(t *testing.T) {
+	input := `<div><!-- Title --><h1>Hi</h1><!--[if IE]><p>Old</p><![endif]--><ul><li>A</li><!-- last */ --></ul></div>`
+
+	tests := []struct {
+		mode     string
+		expected []string
+		absent   []string
+	}{
+		{
+			mode:   CommentsDrop,
+			absent: []string{"Title", "last"},
+		},
+		{
+			mode:     CommentsCode,
+			expected: []string{"\t\t// Title\n\t\tH1(T(\"Hi\")),", "Ul(Li(T(\"A\")) /* last * / */)"},
+			absent:   []string{"Comment(", "if IE"},
+		},
+		{
+			mode:     CommentsNode,
+			expected: []string{`Comment(" Title "),`, `Ul(Li(T("A")), Comment(" last */ "))`},
+			absent:   []string{"if IE"},
+		},
+	}
+	for _, tt := range tests {
+		result, err := NewConverterWithOptions(Options{Comments: tt.mode}).Convert(input)
+		if err != nil {
+			t.Fatalf("Conversion with %s failed: %v", tt.mode, err)
+		}
+		for _, exp := range tt.expected {
+			if !strings.Contains(result, exp) {
+				t.Errorf("Expected %s output to contain %q, but it doesn't.\nOutput:\n%s", tt.mode, exp, result)
+			}
+		}
+		for _, unexpected := range tt.absent {
+			if strings.Contains(result, unexpected) {
+				t.Errorf("Expected %s output not to contain %q.\nOutput:\n%s", tt.mode, unexpected, result)
+			}
+		}
+	}
+
+	if _, err := NewConverterWithOptions(Options{Comments: "keep"}).Convert(input); err == nil {
+		t.Error("Expected an error for an unknown comment mode")
+	}
+}
+
+func TestConvertHeadingIDs(t *testing.T) {
+	slugs := map[string]string{
+		"Getting started":     "getting-started",
+		"  Install & run!  ":  "install-run",
+		"Über die API (v2.0)": "über-die-api-v2-0",
+		"???":                 "section",
+	}
+	for text, expected := range slugs {
+		if got := headingSlug(text); got != expected {
+			t.Errorf("headingSlug(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+
+	input := `<h1>Guide</h1><h2 id="setup">Setup</h2><h2>Setup</h2><h3 id="setup">Again</h3><h2></h2>`
+	converter := NewConverterWithOptions(Options{HeadingIDs: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`H1(Id("guide"), T("Guide"))`,
+		`H2(Id("setup"), T("Setup"))`,
+		`H2(Id("setup-2"), T("Setup"))`,
+		"H2()",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	duplicate := false
+	for _, d := range converter.Diagnostics() {
+		duplicate = duplicate || d.Code == "duplicate-id"
+	}
+	if !duplicate {
+		t.Errorf("Expected a duplicate-id warning, got %v", converter.Diagnostics())
+	}
+}
+
+func TestConvertTOC(t *testing.T) {
+	input := `<nav><h2>Menu</h2></nav><article><h1>Guide</h1>` +
+		`<h2>Getting started</h2><h3>Install &amp; run</h3><h3 id="config">Configuration</h3>` +
+		`<h2>Getting started</h2><h2 id="install-run">FAQ</h2></article>`
+
+	result, err := NewConverterWithOptions(Options{TOC: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`H2(Id("getting-started"), T("Getting started"))`,
+		`H3(Id("install-run-2"), T("Install & run"))`,
+		`H2(Id("getting-started-2"), T("Getting started"))`,
+		"func TableOfContents() Node {",
+		`Li(A(Href("#getting-started"), T("Getting started")), Ul(` + "\n" +
+			`			Li(A(Href("#install-run-2"), T("Install & run"))),` + "\n" +
+			`			Li(A(Href("#config"), T("Configuration"))),`,
+		`Li(A(Href("#install-run"), T("FAQ"))),`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	for _, unexpected := range []string{"#guide", "#menu"} {
+		if strings.Contains(result, unexpected) {
+			t.Errorf("Expected the page title and navigation headings to be left out, found %q.\nOutput:\n%s", unexpected, result)
+		}
+	}
+}
+
+func TestConvertProps(t *testing.T) {
+	input := `<h1>Hello {{user}}</h1>` +
+		`<a href="/users/{{ id }}" data-prop-title="tooltip" title="Sample">Profile</a>` +
+		`<img src="sample.png" data-prop-src="avatar" data-prop-alt="user">` +
+		`<p data-prop="bio">Lorem ipsum</p>`
+
+	result, err := NewConverterWithOptions(Options{Props: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"(user, id, tooltip, avatar, bio string) []Node",
+		`H1(T("Hello "), T(user))`,
+		`A(Href("/users/"+id), Title(tooltip), T("Profile"))`,
+		"Img(Src(avatar), Alt(user))",
+		"P(T(bio))",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// In parameterize mode props are typed from the sample values they replace
+	input = `<img data-prop-width="w" width="100" data-prop-src="avatar" src="/a.png"><span data-prop="count">3</span>`
+	result, err = NewConverterWithOptions(Options{Props: true, Parameterize: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected = []string{
+		"(w int, avatarURL string, count int) []Node",
+		"Img(Width(strconv.Itoa(w)), Src(avatarURL))",
+		"Span(T(strconv.Itoa(count)))",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Without Props, braces are text like any other
+	result, err = NewConverterWithOptions(Options{}).Convert(`<h1>Hello {{user}}</h1>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `H1(T("Hello {{user}}"))`) {
+		t.Errorf("Expected the braces to be kept.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertDedupe(t *testing.T) {
+	input := `<div class="grid">` +
+		`<div class="card"><h3>Alpha</h3><a href="/a">More</a></div>` +
+		`<div class="card"><h3>Beta</h3><a href="/b">More</a></div>` +
+		`<div class="card"><h3>Gamma</h3><a href="/c">More</a></div>` +
+		`<p>Single</p><p>Single</p><p>Single</p>` +
+		`</div>`
+
+	result, err := NewConverterWithOptions(Options{Dedupe: 3}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"Card(\"Alpha\", \"/a\"),\n\t\tCard(\"Beta\", \"/b\"),\n\t\tCard(\"Gamma\", \"/c\"),",
+		"func Card(h3Text, href string) Node {\n\treturn Div(Class(\"card\"), H3(T(h3Text)), A(Href(href), T(\"More\")))",
+		"P(T(\"Single\")),",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Fewer repetitions than the threshold are kept inline
+	result, err = NewConverterWithOptions(Options{Dedupe: 4}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.Contains(result, "func Card") {
+		t.Errorf("Expected no helper below the threshold.\nOutput:\n%s", result)
+	}
+
+	// Repeats of the same shape in different lists share a helper
+	items := `<li><a href="/a">A</a></li><li><a href="/b">B</a></li><li><a href="/c">C</a></li>`
+	result, err = NewConverterWithOptions(Options{Dedupe: 3}).Convert(`<div><ul>` + items + `</ul><ol class="steps">` + items + `</ol></div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.Count(result, "func LiItem(") != 1 || strings.Contains(result, "LiItem2") {
+		t.Errorf("Expected one shared helper.\nOutput:\n%s", result)
+	}
+	if strings.Count(result, `LiItem("/c", "C")`) != 2 {
+		t.Errorf("Expected both lists to call the shared helper.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertFormat(t *testing.T) {
+	input := `<div><img src="${CDN_URL}/logo.png"></div>`
+	defines := map[string]string{"CDN_URL": "https://cdn.example.com", "SITE_NAME": "Example"}
+
+	result, err := NewConverterWithOptions(Options{Defines: defines, DefineConsts: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	formatted, err := format.Source([]byte(result))
+	if err != nil {
+		t.Fatalf("Generated code does not parse: %v\nOutput:\n%s", err, result)
+	}
+	if string(formatted) != result {
+		t.Errorf("Expected gofmt output.\nOutput:\n%s", result)
+	}
+
+	result, err = NewConverterWithOptions(Options{Defines: defines, DefineConsts: true, NoFormat: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Src(cdnUrl + "/logo.png")`) {
+		t.Errorf("Expected unformatted output with NoFormat.\nOutput:\n%s", result)
+	}
+
+	converter := NewConverterWithOptions(Options{Mappings: MappingOverrides{Elements: map[string]string{"div": "func"}}})
+	result, err = converter.Convert(`<div>Hi</div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	failed := slices.ContainsFunc(converter.Diagnostics(), func(d Diagnostic) bool { return d.Code == "format-failed" })
+	if !strings.Contains(result, "func(") || !failed {
+		t.Errorf("Expected unformatted output and a format-failed warning.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertQualified(t *testing.T) {
+	input := `<div class="card" hx-get="/items"><input type="text" disabled><svg width="16" height="16" id="logo"><path d="M0 0"/></svg></div>`
+
+	converter := NewConverterWithOptions(Options{HTMX: true, HoistSVG: true, Qualified: true, HTMLAlias: "h"})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`h "github.com/plainkit/html"`,
+		"func Component() h.Node",
+		`h.Div(`,
+		`h.Class("card")`,
+		`htmx.HxGet("/items")`,
+		`h.Input(h.InputType("text"), h.Disabled())`,
+		"\t\tIconLogo(),",
+		"func IconLogo() h.Node",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, `. "github.com/plainkit/html"`) {
+		t.Errorf("Expected no dot import.\nOutput:\n%s", result)
+	}
+
+	if _, err := NewConverterWithOptions(Options{Qualified: true, HTMLAlias: "my-html"}).Convert(input); err == nil {
+		t.Error("Expected an error for an invalid alias")
+	}
+}
+
+func TestConvertFilenameHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Function name from file name",
+			filename: "views/login-form.html",
+			input:    `<form method="post"></form>`,
+			expected: []string{"func LoginForm() Node"},
+		},
+		{
+			name:     "JSX attributes",
+			filename: "card.jsx",
+			input:    `<label className="card" htmlFor={"email"}>Email</label>`,
+			expected: []string{"func Card() Node", `Class("card")`, `For("email")`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := NewConverterWithOptions(Options{Filename: tt.filename})
+			result, err := converter.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Conversion failed: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestQuoteValueRoundTrip(t *testing.T) {
+	values := []string{
+		"simple",
+		`say "hi"`,
+		`C:\path\to\file`,
+		"{ greeting: `Hello ${name}!` }",
+		"`${a}` + `${b}`",
+		"{\n  label: `Total: ${items.length}`,\n  open: false\n}",
+		"line one\r\nline two",
+		"`starts and ends with backticks`",
+		"tab\there",
+		"مرحبا ${user}",
+	}
+
+	converter := NewConverter(false, true)
+	for _, val := range values {
+		literal := converter.quoteValue(val)
+		tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, literal)
+		if err != nil {
+			t.Errorf("quoteValue(%q) produced invalid Go %s: %v", val, literal, err)
+			continue
+		}
+		if got := constant.StringVal(tv.Value); got != val {
+			t.Errorf("quoteValue(%q) = %s, which evaluates to %q", val, literal, got)
+		}
+	}
+}
+
+func TestConvertAlpineTemplateLiterals(t *testing.T) {
+	input := "<div x-data=\"{ name: 'World' }\" x-text=\"`Hello ${name}`\" @click=\"alert(`Hi ${name}, &quot;welcome&quot;`)\"></div>"
+
+	converter := NewConverter(false, true)
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"alpine.XText(\"`Hello ${name}`\")",
+		`alpine.AtClick("alert(` + "`" + `Hi ${name}, \"welcome\"` + "`" + `)")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertInlineEventHandlers(t *testing.T) {
+	input := `<div>
+	<button onclick="toggle(this); return false;">Toggle</button>
+	<a onclick="location.href='/home'">Home</a>
+</div>`
+
+	suggest := NewConverterWithOptions(Options{SuggestHandlers: true})
+	if _, err := suggest.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	diags := suggest.Diagnostics()
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Message, `@click.prevent="toggle($el)"`) {
+		t.Errorf("Expected Alpine suggestion, got %q", diags[0].Message)
+	}
+	if !strings.Contains(diags[1].Message, `hx-get="/home"`) {
+		t.Errorf("Expected htmx suggestion, got %q", diags[1].Message)
+	}
+
+	rewrite := NewConverterWithOptions(Options{RewriteHandlers: true})
+	result, err := rewrite.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, exp := range []string{"Div(\n\t\tCustom(\"x-data\", \"\"),", `alpine.AtClickPrevent("toggle($el)")`, `"github.com/plainkit/alpine"`} {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Handlers already inside a component keep its scope
+	result, err = NewConverterWithOptions(Options{Alpine: true, RewriteHandlers: true}).Convert(
+		`<main><div x-data="{ open: false }"><button onclick="open = !open">Menu</button></div></main>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.Count(result, "XData(") != 1 || !strings.Contains(result, `alpine.AtClick("open = !open")`) {
+		t.Errorf("Expected the existing x-data to be kept alone.\nOutput:\n%s", result)
+	}
+	result, err = NewConverterWithOptions(Options{Alpine: true, RewriteHandlers: true}).Convert(
+		`<!DOCTYPE html><html><body><main><button onclick="go()">Go</button></main></body></html>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Body(alpine.XData(""),`) {
+		t.Errorf("Expected x-data on the body of a document.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertEvents(t *testing.T) {
+	input := `<form onsubmit="return validate(this)"><input onchange="save()" oninput="count()" onfoo="bar()"><button onclick="go()" ondblclick="stop()">Go</button></form>`
+
+	result, err := NewConverterWithOptions(Options{}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Custom("onclick", "go()")`) {
+		t.Errorf("Expected handlers to stay Custom without Events.\nOutput:\n%s", result)
+	}
+
+	result, err = NewConverterWithOptions(Options{Events: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`OnSubmit("return validate(this)")`,
+		`OnChange("save()")`,
+		`OnInput("count()")`,
+		`OnClick("go()")`,
+		`OnDblClick("stop()")`,
+		// Unknown handlers have no helper
+		`Custom("onfoo", "bar()")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Rewriting into Alpine attributes wins
+	result, err = NewConverterWithOptions(Options{Events: true, RewriteHandlers: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.Contains(result, "OnClick(") {
+		t.Errorf("Expected RewriteHandlers to take precedence.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertClassConditions(t *testing.T) {
+	input := `<div x-data="{ open: false, error: false }">` +
+		`<button :class="{ 'bg-blue-600 text-white': open, 'hover:bg-gray-100': !open, active }">Menu</button>` +
+		`<p x-bind:class="{ hidden: !error }">Error</p>` +
+		`<span :class="open ? 'on' : 'off'"></span>` +
+		`<span :class="{ [dynamic]: open }"></span></div>`
+
+	result, err := NewConverterWithOptions(Options{Alpine: true, ClassConditions: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`classCond{"bg-blue-600 text-white", "open"}`,
+		`classCond{"hover:bg-gray-100", "!open"}`,
+		`classCond{"active", "active"}`,
+		`alpine.XBind("class", classConds(classCond{"hidden", "!error"}))`,
+		// Values other than plain objects are kept
+		`alpine.ColonClass("open ? 'on' : 'off'")`,
+		`alpine.ColonClass("{ [dynamic]: open }")`,
+		"type classCond struct {",
+		"func classConds(conds ...classCond) string {",
+		`"strconv"`,
+		`"strings"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertAlpineData(t *testing.T) {
+	input := `<div x-data="dropdown">` +
+		`<form x-data="checkout({ step: 1, total: sum(a, b) }, 'eur')"></form>` +
+		`<p x-data="{ open: false }"></p>` +
+		`<span x-data="ready() || fallback()"></span></div>`
+
+	result, err := NewConverterWithOptions(Options{Alpine: true, AlpineData: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`alpine.XData(alpineData("dropdown"))`,
+		`alpine.XData(alpineData("checkout", "{ step: 1, total: sum(a, b) }", "'eur'"))`,
+		// Inline state and other expressions are kept
+		`alpine.XData("{ open: false }")`,
+		`alpine.XData("ready() || fallback()")`,
+		"func alpineData(name string, args ...string) string {",
+		`"strings"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertNormalizeIndicators(t *testing.T) {
+	input := `<div>
+	<button hx-get="/a" hx-indicator="#spin-a">A</button>
+	<div id="spin-a"><span class="spinner"></span></div>
+	<button hx-get="/b" hx-indicator="#spin-b">B</button>
+	<div id="spin-b" role="status"><span class="spinner"></span></div>
+</div>`
+
+	converter := NewConverterWithOptions(Options{HTMX: true, NormalizeIndicators: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`LoadingIndicator("spin-a")`,
+		`LoadingIndicator("spin-b")`,
+		"func LoadingIndicator(id string) Node",
+		"Id(id)",
+		`Aria("live", "polite")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Count(result, "func LoadingIndicator") != 1 {
+		t.Errorf("Expected a single shared helper.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertParameterizeHead(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html>
+<head>
+	<title>Pricing - Acme</title>
+	<meta name="description" content="Plans for every team">
+	<meta property="og:title" content="Pricing">
+	<meta name="viewport" content="width=device-width">
+</head>
+<body><h1>Pricing</h1></body>
+</html>`
+
+	converter := NewConverterWithOptions(Options{Parameterize: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"func Page(title, description string) Node",
+		"HeadTitle(T(title))",
+		`Meta(Name("description"), Content(description))`,
+		"Content(title)",
+		`Content("width=device-width")`,
+		`H1(T("Pricing"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertDesignArtifacts(t *testing.T) {
+	input := `<div data-wf-page="5f1" data-w-id="abc" class="hero"><p data-figma-name="Copy" data-figma-component="Label">Hi</p></div>`
+
+	converter := NewConverterWithOptions(Options{
+		StripDesignArtifacts: true,
+		DataAttributes: map[string]TypedAttr{
+			"data-figma-component": {Type: "string", Format: "FigmaComponent(%s)"},
+		},
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	for _, unwanted := range []string{"wf-page", "w-id", "figma-name"} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("Expected %s to be stripped.\nOutput:\n%s", unwanted, result)
+		}
+	}
+	if !strings.Contains(result, `FigmaComponent("Label")`) {
+		t.Errorf("Expected mapped artifact to be kept.\nOutput:\n%s", result)
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "stripped 3 design tool attributes") {
+		t.Errorf("Expected a summary diagnostic, got %v", diags)
+	}
+}
+
+func TestConvertProfile(t *testing.T) {
+	input := `<div class="w-container hero" data-w-id="abc"><div class="w-embed"><span id="w-node-1f">Hi</span></div>` +
+		`<a class="nav-link w-inline-block w--current" href="/">Home</a>` +
+		`<script src="https://d3e54v103j8qbb.cloudfront.net/js/jquery-3.5.1.min.js"></script></div>`
+
+	converter := NewConverterWithOptions(Options{Profile: "webflow"})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`Div(Class("hero"), Span(T("Hi"))`,
+		`A(Class("nav-link"), Href("/"), T("Home"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	for _, unwanted := range []string{"w-", "Script", "w-id"} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("Expected %s to be removed.\nOutput:\n%s", unwanted, result)
+		}
+	}
+
+	if _, err := NewConverterWithOptions(Options{Profile: "nope"}).Convert(input); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+
+	// Utility classes sharing the w- prefix are not Webflow classes
+	result, err = NewConverterWithOptions(Options{Profile: "webflow"}).Convert(
+		`<div class="w-full md:w-1/2 w-64 w-container w-nav w-col w-col-6">Hi</div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Class("w-full md:w-1/2 w-64")`) {
+		t.Errorf("Expected the width utilities to be kept.\nOutput:\n%s", result)
+	}
+
+	// The profile strips without changing the options of the converter
+	if converter.opts.StripDesignArtifacts {
+		t.Error("Expected the profile to leave StripDesignArtifacts unset")
+	}
+	converter.opts.Profile = ""
+	result, err = converter.Convert(`<div data-w-id="abc">Hi</div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Data("w-id", "abc")`) {
+		t.Errorf("Expected the attribute to be kept without a profile.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertBootstrapComponents(t *testing.T) {
+	input := `<div class="card shadow"><div class="card-body"><h5 class="card-title">Title</h5><p class="card-text lead">Body</p></div></div>`
+
+	converter := NewConverterWithOptions(Options{
+		Profile:    "bootstrap",
+		Imports:    []string{"ui example.com/app/bootstrap"},
+		Components: map[string]string{"card-text": "ui.Text"},
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`ui "example.com/app/bootstrap"`,
+		"ui.Card(",
+		`Class("shadow")`,
+		"ui.CardBody(",
+		`ui.CardTitle(T("Title"))`,
+		`ui.Text(Class("lead"), T("Body"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if diags := converter.Diagnostics(); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+
+	converter = NewConverterWithOptions(Options{Profile: "bootstrap"})
+	if _, err := converter.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "component-import" {
+		t.Errorf("Expected a missing import warning, got %v", diags)
+	}
+}
+
+func TestConvertClassVariants(t *testing.T) {
+	input := `<div class="card p-4">` +
+		`<button class="btn rounded px-4 bg-blue-600 text-white">Save</button>` +
+		`<button class="btn rounded px-4 bg-blue-600 text-white">Send</button>` +
+		`<button class="btn rounded px-4 hover:bg-red-700 bg-destructive">Delete</button>` +
+		`<button class="btn rounded px-4 hover:bg-red-700 bg-destructive">Remove</button>` +
+		`<span class="badge">New</span><span class="badge">Hot</span></div>`
+
+	converter := NewConverterWithOptions(Options{ClassVariants: 2})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`spanClass = "badge"`,
+		"buttonVariants = map[string]string{",
+		`"bg-blue-600": "btn rounded px-4 bg-blue-600 text-white",`,
+		`"destructive": "btn rounded px-4 hover:bg-red-700 bg-destructive",`,
+		`Button(Class(buttonVariants["destructive"]), T("Delete"))`,
+		`Span(Class(spanClass), T("New"))`,
+		`Class("card p-4")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Attributes other than class keep values equal to a bundle, also when a
+	// hoisted constant gives way to the bundle
+	result, err = NewConverterWithOptions(Options{ClassVariants: 2, HoistConstants: 2}).Convert(
+		`<div><span class="badge">New</span><span class="badge">Hot</span><a class="badge" href="badge" title="badge">x</a></div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, exp := range []string{`Span(Class(spanClass), T("New"))`, `Href("badge")`, `Title("badge")`} {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertDefines(t *testing.T) {
+	input := `<div><img src="${CDN_URL}/logo.png" alt="${SITE_NAME}"><p>Hosted at ${CDN_URL}</p>` +
+		"<script>console.log(`${CDN_URL}`)</script></div>"
+
+	converter := NewConverterWithOptions(Options{Defines: map[string]string{"CDN_URL": "https://cdn.example.com"}})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"func Component(siteName string) Node",
+		`Src("https://cdn.example.com/logo.png")`,
+		"Alt(siteName)",
+		`T("Hosted at https://cdn.example.com")`,
+		"console.log(`${CDN_URL}`)",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	converter = NewConverterWithOptions(Options{
+		Defines:      map[string]string{"CDN_URL": "https://cdn.example.com", "SITE_NAME": "Example"},
+		DefineConsts: true,
+	})
+	result, err = converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected = []string{
+		`cdnUrl   = "https://cdn.example.com"`,
+		`Src(cdnUrl+"/logo.png")`,
+		"Alt(siteName)",
+		`T("Hosted at "+cdnUrl)`,
+		"func Component() Node",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertDefinesScriptAttributes(t *testing.T) {
+	input := "<div><button onclick=\"alert(`Hi ${name}`)\">Hi</button>" +
+		"<span x-text=\"`${CDN_URL}`\"></span><a href=\"${CDN_URL}/docs\">Docs</a></div>"
+
+	converter := NewConverterWithOptions(Options{Defines: map[string]string{"CDN_URL": "https://cdn.example.com"}})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"func Component() Node",
+		"Custom(\"onclick\", \"alert(`Hi ${name}`)\")",
+		"Custom(\"x-text\", \"`${CDN_URL}`\")",
+		`Href("https://cdn.example.com/docs")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if diags := converter.Diagnostics(); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics for script template literals, got %v", diags)
+	}
+}
+
+func TestConvertDetails(t *testing.T) {
+	input := `<div><details open><summary>Q1</summary><p>A1</p></details><details><summary>Q2</summary><p>A2</p></details><details><p>No summary</p><summary>Late</summary></details></div>`
+
+	converter := NewConverter(false, false)
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `Details(Open(), Summary(T("Q1")), P(T("A1")))`) {
+		t.Errorf("Expected open details with a summary.\nOutput:\n%s", result)
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "summary-position" {
+		t.Errorf("Expected a summary-position warning, got %v", diags)
+	}
+
+	converter = NewConverterWithOptions(Options{Parameterize: true})
+	result, err = converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"Accordion([]AccordionItem{",
+		`{Summary: "Q1", Body: P(T("A1")), Open: true},`,
+		`{Summary: "Q2", Body: P(T("A2"))},`,
+		"type AccordionItem struct {",
+		"func Accordion(items []AccordionItem) Node {",
+		`Details(P(T("No summary")), Summary(T("Late")))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestValidateAutocomplete(t *testing.T) {
+	valid := []string{"on", "off", "email", "section-blue shipping street-address", "billing work tel", "username webauthn", "One-Time-Code"}
+	for _, val := range valid {
+		if problem := validateAutocomplete("input", val); problem != "" {
+			t.Errorf("Expected %q to be valid, got %q", val, problem)
+		}
+	}
+
+	invalid := map[string]string{
+		"emial":             `did you mean "email"?`,
+		"cc-numbr":          `did you mean "cc-number"?`,
+		"home name":         "contact fields",
+		"shipping":          "missing a field name",
+		"email tel":         "unexpected tokens",
+		"nick-name":         `did you mean "nickname"?`,
+		"webauthn":          "must follow a field name",
+		"given-name family": "unexpected tokens",
+	}
+	for val, want := range invalid {
+		if problem := validateAutocomplete("input", val); !strings.Contains(problem, want) {
+			t.Errorf("validateAutocomplete(%q) = %q, want it to contain %q", val, problem, want)
+		}
+	}
+	if problem := validateAutocomplete("form", "email"); problem == "" {
+		t.Error("Expected forms to only accept on/off")
+	}
+
+	converter := NewConverter(false, false)
+	if _, err := converter.Convert(`<form><input name="e" autocomplete="emial"><input autocomplete="email"></form>`); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "autocomplete-invalid" {
+		t.Errorf("Expected one autocomplete-invalid warning, got %v", diags)
+	}
+}
+
+func TestConvertEnumeratedAttributes(t *testing.T) {
+	input := `<form method="POST" enctype="multipart/formdata">` +
+		`<input type="emial"><button type="Submit">Go</button>` +
+		`<a href="/" target="_new" rel="noopener nofolow">Home</a>` +
+		`<img src="a.png" loading="LAZY" alt=""><textarea wrap="off"></textarea></form>`
+
+	converter := NewConverterWithOptions(Options{NormalizeEnums: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	for _, exp := range []string{`Method("post")`, `ButtonType("submit")`, `Custom("loading", "lazy")`} {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	var invalid, normalized []string
+	for _, d := range converter.Diagnostics() {
+		switch d.Code {
+		case "enum-invalid":
+			invalid = append(invalid, d.Message)
+		case "enum-normalized":
+			normalized = append(normalized, d.Message)
+		}
+	}
+	wantInvalid := []string{`enctype="multipart/formdata"`, `type="emial"`, `target="_new"`, "unknown tokens nofolow", `wrap="off"`}
+	if len(invalid) != len(wantInvalid) {
+		t.Fatalf("Expected %d invalid values, got %v", len(wantInvalid), invalid)
+	}
+	for i, want := range wantInvalid {
+		if !strings.Contains(invalid[i], want) {
+			t.Errorf("Expected diagnostic %d to mention %s, got %q", i, want, invalid[i])
+		}
+	}
+	if len(normalized) != 3 {
+		t.Errorf("Expected three normalized values, got %v", normalized)
+	}
+}
+
+func TestConvertElementFallback(t *testing.T) {
+	converter := NewConverter(false, false)
+	result, err := converter.Convert(`<section><my-widget size="2"><span>Hi</span></my-widget><data value="3">Three</data><textarea></textarea><colgroup></colgroup></section>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`Element("my-widget", Custom("size", "2"), Span(T("Hi")))`,
+		`DataEl(Value("3"), T("Three"))`,
+		"Textarea()",
+		"Section(",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertObsoleteElements(t *testing.T) {
+	converter := NewConverter(false, false)
+	result, err := converter.Convert(`<div><center><font color="red">Sale</font></center><p><acronym title="As soon as possible">ASAP</acronym></p></div>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`Element("center", Element("font", Custom("color", "red"), T("Sale")))`,
+		`Element("acronym", Title("As soon as possible"), T("ASAP"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 3 {
+		t.Fatalf("Expected three obsolete-element warnings, got %v", diags)
+	}
+	if diags[2].Code != "obsolete-element" || !strings.Contains(diags[2].Message, "use abbr") {
+		t.Errorf("Expected a replacement suggestion for acronym, got %v", diags[2])
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	input := "<div>\n<p>Intro\n<div>block</div></p>\n<li>stray</li>\n<ul><li>a<li>b</ul>\n" +
+		"<table><tr><td>x<td>y</tr></table>\n<form><form></form></form>\n<svg><title>Icon</title></svg><main></main><main></main></div>"
+
+	converter := NewConverterWithOptions(Options{Validate: true})
+	if _, err := converter.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"line 3: <div> inside <p> closes the paragraph early",
+		"line 4: <li> inside <div>; expected a parent <ul>, <ol> or <menu>",
+		"line 7: <form> inside <form> is not allowed",
+		"line 8: duplicate <main>",
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, exp := range expected {
+		if got := diags[i].String(); !strings.Contains(got, exp) {
+			t.Errorf("Expected diagnostic %d to contain %q, got %q", i, exp, got)
+		}
+	}
+}
+
+func TestReportMutations(t *testing.T) {
+	input := "\n<div>\n<p>Intro\n<div>block</div></p>\n</span>\n<table><div>fostered</div><tr><td>x</td></tr></table>\n<ul><li>a<li>b</ul></div>"
+
+	converter := NewConverterWithOptions(Options{ReportMutations: true})
+	if _, err := converter.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"line 4: <div> closed the open <p>",
+		"line 4: stray </p> has no open paragraph",
+		"line 5: stray </span> has no open element",
+		"line 6: <div> was moved from <table> to <div>",
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, exp := range expected {
+		if got := diags[i].String(); !strings.Contains(got, exp) {
+			t.Errorf("Expected diagnostic %d to contain %q, got %q", i, exp, got)
+		}
+	}
+}
+
+func TestConvertPreformatted(t *testing.T) {
+	input := "<div><pre>  line 1\n    indented\n</pre><textarea>\n\n  keep\n</textarea>" +
+		"<pre><code>if x {\n\treturn\n}</code></pre><p>Use <code> a  b </code> or<br> <kbd>Ctrl</kbd>.</p></div>"
+
+	result, err := NewConverterWithOptions(Options{}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"Pre(\n\t\t\tT(`  line 1\n    indented\n`),",
+		// The parser drops the newline right after the start tag
+		"T(`\n  keep\n`)",
+		"Code(\n\t\t\t\tT(`if x {\n\treturn\n}`),",
+		`T("Use ")`,
+		`Code(T(" a  b "))`,
+		`T(" or")`,
+		`Br()`,
+		`Kbd(T("Ctrl"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertTextModes(t *testing.T) {
+	input := "<div>\n  <p>Hello   <strong>big</strong> <em>world</em>\n</p>\n  <pre class=\"code\">a\n  b</pre>\n</div>"
+
+	tests := []struct {
+		mode     string
+		patches  []Patch
+		expected []string
+	}{
+		{
+			mode:     TextTrim,
+			expected: []string{`T("Hello ")`, `Strong(T("big"))`, `T(" ")`, `Em(T("world"))`, "T(`a\n  b`)"},
+		},
+		{
+			mode:     TextCollapse,
+			expected: []string{`T("Hello ")`, `T(" ")`, `Em(T("world"))`, "T(`a\n  b`)"},
+		},
+		{
+			mode:     TextVerbatim,
+			expected: []string{`T("Hello   ")`, "T(`\n  `)", "T(`a\n  b`)"},
+		},
+		{
+			mode:     TextCollapse,
+			patches:  []Patch{{Selector: "pre", TextMode: TextCollapse}},
+			expected: []string{`T("Hello ")`, `T("a b")`},
+		},
+	}
+	for _, tt := range tests {
+		converter := NewConverterWithOptions(Options{TextMode: tt.mode, Patches: tt.patches})
+		result, err := converter.Convert(input)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		for _, exp := range tt.expected {
+			if !strings.Contains(result, exp) {
+				t.Errorf("%s: expected output to contain %q.\nOutput:\n%s", tt.mode, exp, result)
+			}
+		}
+	}
+
+	if _, err := NewConverterWithOptions(Options{TextMode: "squash"}).Convert(input); err == nil {
+		t.Error("Expected an error for an unknown text mode")
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	input := `<div class="card"><h2>Title</h2><p>Body</p></div>`
+
+	// Formatted output is written whole, unformatted output is streamed
+	for _, opts := range []Options{{EditableRegions: true}, {EditableRegions: true, NoFormat: true}} {
+		expected, err := NewConverterWithOptions(opts).Convert(input)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+
+		var out strings.Builder
+		if err := ConvertTo(&out, strings.NewReader(input), opts); err != nil {
+			t.Fatalf("ConvertTo failed: %v", err)
+		}
+		if out.String() != expected {
+			t.Errorf("Expected output with NoFormat=%v to match Convert.\nGot:\n%s\nWant:\n%s", opts.NoFormat, out.String(), expected)
+		}
+	}
+
+	var out strings.Builder
+	if err := ConvertTo(&out, strings.NewReader(input), Options{TextMode: "squash"}); err == nil {
+		t.Error("Expected an error for an unknown text mode")
+	}
+}
+
+func TestConvertToFragmentStream(t *testing.T) {
+	input := "\uFEFF \r\n<div class=\"card\">\r\n<pre>a\rb</pre>\xff</div>\u00a0text \r\n"
+	opts := Options{Mode: ModeFragment, TextMode: TextVerbatim}
+	expected, err := NewConverterWithOptions(opts).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	// The reader is parsed as it is read, with the same result
+	converter := NewConverterWithOptions(opts)
+	if !converter.streamsInput() {
+		t.Fatal("Expected fragment input to be streamed")
+	}
+	var out strings.Builder
+	if err := converter.ConvertTo(&out, &chunkReader{r: strings.NewReader(input)}); err != nil {
+		t.Fatalf("ConvertTo failed: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("Expected streamed output to match Convert.\nGot:\n%s\nWant:\n%s", out.String(), expected)
+	}
+
+	for _, opts := range []Options{{}, {Mode: ModeFragment, Validate: true}, {Mode: ModeFragment, Filename: "card.jsx"}} {
+		if NewConverterWithOptions(opts).streamsInput() {
+			t.Errorf("Expected input with %+v to be read whole", opts)
+		}
+	}
+}
+
+// chunkReader reads at most three bytes at a time
+type chunkReader struct {
+	r io.Reader
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	return r.r.Read(p[:min(len(p), 3)])
+}
+
+func TestInputReader(t *testing.T) {
+	for _, input := range []string{
+		"", " ", "\uFEFF", "\uFEFF  <p>x</p>  ", "a\r\nb\rc\n", " \t\u00a0x\u2003 y \u00a0", "\xffa\xfe ", "x\uFEFF", "\r",
+	} {
+		got, err := io.ReadAll(newInputReader(&chunkReader{r: strings.NewReader(input)}))
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		if want := strings.TrimSpace(normalizeInput(input)); string(got) != want {
+			t.Errorf("%q: expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestConvertContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	converter := NewConverter(false, false)
+	if _, err := converter.ConvertContext(ctx, `<div>Hello</div>`); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	var out strings.Builder
+	if err := ConvertToContext(ctx, &out, strings.NewReader(`<div>Hello</div>`), Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no output after cancellation, got %q", out.String())
+	}
+
+	// The converter stays usable for later conversions
+	if _, err := converter.Convert(`<div>Hello</div>`); err != nil {
+		t.Errorf("Conversion after cancellation failed: %v", err)
+	}
+}
+
+func TestConvertResult(t *testing.T) {
+	input := `<!-- plainkit:func Card --><div class="card"><h2>Title</h2><button hx-post="/save">Save</button></div>`
+	result, err := ConvertResult(context.Background(), input, Options{HTMX: true})
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected, err := NewConverterWithOptions(Options{HTMX: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if result.Code != expected {
+		t.Errorf("Expected result code to match Convert.\nGot:\n%s\nWant:\n%s", result.Code, expected)
+	}
+	imports := []string{`. "github.com/plainkit/html"`, `"github.com/plainkit/htmx"`}
+	if strings.Join(result.Imports, "\n") != strings.Join(imports, "\n") {
+		t.Errorf("Expected imports %q, got %q", imports, result.Imports)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].Name != "Card" {
+		t.Errorf("Expected the Card function, got %+v", result.Functions)
+	}
+
+	if _, err := ConvertResult(context.Background(), input, Options{TextMode: "squash"}); err == nil {
+		t.Error("Expected an error for an unknown text mode")
+	}
+}
+
+func TestConvertCustomProperties(t *testing.T) {
+	input := `<div>
+<style>.card { --radius: 4px; border-radius: var(--radius); }</style>
+<div class="card" style="color: var(--brand); background: var(--surface, white); padding: var(--space)"></div>
+</div>`
+	converter := NewConverterWithOptions(Options{
+		Theme:       parseCustomProperties(":root { --brand: #0057b8; --space: 1rem; }"),
+		ThemeTokens: true,
+		Filename:    "card.html",
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := "var cardThemeTokens = map[string]string{\n\t\"--radius\":  \"4px\",\n\t\"--brand\":   \"#0057b8\",\n\t\"--surface\": \"\",\n\t\"--space\":   \"1rem\",\n}"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+
+	var undefined []Diagnostic
+	for _, d := range converter.Diagnostics() {
+		if d.Code == "css-var-undefined" {
+			undefined = append(undefined, d)
+		}
+	}
+	if len(undefined) != 1 || !strings.Contains(undefined[0].Message, "--surface") || undefined[0].Severity != SeverityInfo {
+		t.Errorf("Expected an info about --surface, got %v", undefined)
+	}
+}
+
+func TestConvertDarkMode(t *testing.T) {
+	input := `<div class="bg-white dark:bg-gray-900 p-4">
+<h2 class="text-gray-900 text-lg">Title</h2>
+<p class="text-sm hover:text-blue-600 dark:text-gray-300">Body</p>
+<section data-theme="dark"><a class="bg-blue-500">Link</a></section>
+</div>`
+	converter := NewConverterWithOptions(Options{ThemeCoverage: true, DarkVariants: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`Class(withDark("bg-white p-4", "bg-gray-900"))`,
+		`Class(withDark("text-sm hover:text-blue-600", "text-gray-300"))`,
+		`H2(Class("text-gray-900 text-lg")`,
+		"func withDark(base string, dark ...string) string {",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	codes := make(map[string][]string)
+	for _, d := range converter.Diagnostics() {
+		codes[d.Code] = append(codes[d.Code], d.Message)
+	}
+	coverage := codes["theme-coverage"]
+	if len(coverage) != 1 || !strings.Contains(coverage[0], "cover 3 of 4 elements") || !strings.Contains(coverage[0], "data-theme values: dark") {
+		t.Errorf("Unexpected theme coverage: %q", coverage)
+	}
+	if missing := codes["dark-variant-missing"]; len(missing) != 1 || !strings.Contains(missing[0], "text-gray-900") {
+		t.Errorf("Expected the h2 to miss a dark variant, got %q", missing)
+	}
+}
+
+func TestConvertFlatten(t *testing.T) {
+	input := `<main>
+<div>
+	<div><div><section class="hero"><h1>Hi</h1></section></div></div>
+</div>
+<div><p>a</p><p>b</p></div>
+<div id="kept"><p>c</p></div>
+<div>text <b>x</b></div>
+</main>`
+	converter := NewConverterWithOptions(Options{Flatten: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"Main(\n\t\tSection(Class(\"hero\")",
+		`Div(P(T("a")), P(T("b")))`,
+		`Div(Id("kept"), P(T("c")))`,
+		`Div(T("text "), B(T("x")))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "flattened" || !strings.Contains(diags[0].Message, "removed 3 wrapper element(s)") {
+		t.Errorf("Expected one flattened report, got %v", diags)
+	}
+}
+
+func TestConvertStripAttributes(t *testing.T) {
+	input := `<div data-testid="card" data-gtm-event="view" data-role="card"><button data-qa="buy" data-track="click" class="btn">Buy</button></div>`
+	converter := NewConverterWithOptions(Options{StripAttributes: []string{"testing", "data-gtm-*", "data-track"}})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := `Div(Data("role", "card"), Button(Class("btn"), T("Buy")))`
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Message != "stripped 4 attributes: data-gtm-event, data-qa, data-testid, data-track" {
+		t.Errorf("Unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestConvertIcons(t *testing.T) {
+	input := `<div>
+<svg style="display:none"><symbol id="icon-cart" viewBox="0 0 24 24"><path d="M1 1h22"/></symbol></svg>
+<button><svg class="w-4"><use href="#icon-cart"></use></svg>Cart</button>
+<svg class="icon-check w-4"><path d="M5 10l3 3 7-7"/></svg>
+<svg class="icon-check w-6"><path d="M5 10l3 3 7-7"/></svg>
+<svg class="logo"><circle r="4"/></svg>
+</div>`
+	converter := NewConverterWithOptions(Options{IconsImport: "example.com/app/icons"})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`"example.com/app/icons"`,
+		`Button(Svg(Class("w-4"), Custom("viewBox", "0 0 24 24"), icons.Cart()), T("Cart"))`,
+		`Svg(Class("icon-check w-4"), icons.Check())`,
+		`Svg(Class("icon-check w-6"), icons.Check())`,
+		`Svg(Class("logo"), Element("circle"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "symbol") || strings.Contains(result, "display:none") {
+		t.Errorf("Expected the sprite to be removed.\nOutput:\n%s", result)
+	}
+
+	icons := converter.IconsCode()
+	expected = []string{
+		"package icons",
+		"func Cart() Node {\n\treturn Element(\"path\", Custom(\"d\", \"M1 1h22\"))\n}",
+		"func Check() Node {",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(icons, exp) {
+			t.Errorf("Expected icons package to contain %q.\nOutput:\n%s", exp, icons)
+		}
+	}
+}
+
+func TestConvertRawText(t *testing.T) {
+	input := "<style>\n  a > b { content: \"</\"; }\n</style>" +
+		`<script type="application/ld+json">{"@type": "Organization"}</script>` +
+		"<script>if (a < b && c) { s = \"<\\/script>\"; }</script><script src=\"app.js\"></script>"
+
+	result, err := NewConverterWithOptions(Options{}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"StyleEl(\n\t\t\tRaw(`\n  a > b { content: \"</\"; }\n`),",
+		`Script(Type("application/ld+json"), Raw("{\"@type\": \"Organization\"}"))`,
+		`Raw("if (a < b && c) { s = \"<\\/script>\"; }")`,
+		`Script(ScriptSrc("app.js"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	if got := escapeRawText("script", `document.write("</SCRIPT>")`); got != `document.write("<\/SCRIPT>")` {
+		t.Errorf("Expected the end tag to be escaped, got %s", got)
+	}
+}
+
+func TestConvertSVG(t *testing.T) {
+	input := `<svg xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 24 24" width="24" class="icon" aria-hidden="true">` +
+		`<title>Logo</title><linearGradient gradientUnits="userSpaceOnUse"></linearGradient>` +
+		`<path d="M12 2L2 7z" stroke-width="2"/><use xlink:href="#icon"/><a href="/home"><text>Home</text></a>` +
+		`<foreignObject><p title="Note">Hi</p></foreignObject></svg>`
+
+	result, err := NewConverterWithOptions(Options{}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`Custom("xmlns:xlink", "http://www.w3.org/1999/xlink")`,
+		`Custom("viewBox", "0 0 24 24")`,
+		`Custom("width", "24")`,
+		`Class("icon")`,
+		`Aria("hidden", "true")`,
+		`Element("title", T("Logo"))`,
+		`Element("linearGradient", Custom("gradientUnits", "userSpaceOnUse"))`,
+		`Element("path", Custom("d", "M12 2L2 7z"), Custom("stroke-width", "2"))`,
+		`Element("use", Custom("xlink:href", "#icon"))`,
+		`Element("a", Custom("href", "/home"), Element("text", T("Home")))`,
+		`P(Title("Note"), T("Hi"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q, but it doesn't.\nOutput:\n%s", exp, result)
+		}
+	}
+}
+
+func TestConvertHoistSVG(t *testing.T) {
+	input := `<div><a class="brand"><svg id="logo" viewBox="0 0 32 32"><path d="M0 0"/></svg></a>` +
+		`<section class="hero"><svg viewBox="0 0 800 600"><circle r="10"/></svg></section>` +
+		`<div><svg class="w-64 hero-illustration" viewBox="0 0 800 600"><rect width="8"/></svg></div></div>`
+
+	converter := NewConverterWithOptions(Options{HoistSVG: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`A(Class("brand"), IconLogo())`,
+		`Section(Class("hero"), IllustrationHero())`,
+		"func IconLogo() Node {\n\treturn Svg(Id(\"logo\")",
+		"func IllustrationHero() Node {",
+		// The class words besides the kind name the svg
+		"Div(IllustrationHero2())",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if converter.SVGCode() != "" {
+		t.Error("Expected no separate SVG code without SeparateSVG")
+	}
+
+	converter = NewConverterWithOptions(Options{HoistSVG: true, SeparateSVG: true})
+	result, err = converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	svg := converter.SVGCode()
+	if strings.Contains(result, "func IconLogo") || !strings.Contains(result, "IconLogo()") {
+		t.Errorf("Expected only the call in the main output.\nOutput:\n%s", result)
+	}
+	if !strings.Contains(svg, "func IconLogo() Node {") || !strings.Contains(svg, "func IllustrationHero() Node {") {
+		t.Errorf("Expected the SVG functions in the separate file.\nOutput:\n%s", svg)
+	}
+}
+
+func TestSVGClassName(t *testing.T) {
+	tests := map[string]string{
+		"hero-illustration": "hero",
+		"svg-logo w-6":      "logo",
+		"w-6 h-6 text-gray": "",
+		"icon":              "",
+	}
+	for class, want := range tests {
+		n := &html.Node{Type: html.ElementNode, Data: "svg", Attr: []html.Attribute{{Key: "class", Val: class}}}
+		if got := svgClassName(n); got != want {
+			t.Errorf("%q: expected %q, got %q", class, want, got)
+		}
+	}
+}
+func TestConvertFavicons(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html><head>
+<meta charset="utf-8">
+<link rel="apple-touch-icon" href="/static/fav/apple-touch-icon.png">
+<link rel="icon" type="image/png" href="/static/fav/favicon-32x32.png">
+<title>Home</title>
+<link rel="manifest" href="/static/fav/site.webmanifest">
+<meta name="theme-color" content="#ffffff">
+<link rel="stylesheet" href="/static/site.css">
+</head><body><p>Hi</p></body></html>`
+
+	result, err := NewConverterWithOptions(Options{GroupFavicons: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		"Meta(Charset(\"utf-8\")),\n\t\t\tFavicons(\"/static/fav\"),\n\t\t\tHeadTitle(T(\"Home\")),",
+		`Link(Rel("stylesheet"), Href("/static/site.css"))`,
+		"func Favicons(base string) Node {\n\treturn Fragment(",
+		`Link(Rel("apple-touch-icon"), Href(base+"/apple-touch-icon.png"))`,
+		`Link(Rel("manifest"), Href(base+"/site.webmanifest"))`,
+		`Meta(Name("theme-color"), Content("#ffffff"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Count(result, "favicon-32x32.png") != 1 {
+		t.Errorf("Expected the favicon tags only in the helper.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertStubSnippets(t *testing.T) {
+	input := `<div>
+<script async src="https://www.googletagmanager.com/gtag/js?id=G-ABC123"></script>
+<script>window.dataLayer = window.dataLayer || []; function gtag(){dataLayer.push(arguments);} gtag('js', new Date()); gtag('config', 'G-ABC123');</script>
+<script>(function(h,o,t,j,a,r){h._hjSettings={hjid:1234567,hjsv:6};})(window,document,'https://static.hotjar.com/c/hotjar-','.js?sv=');</script>
+<script>console.log("kept")</script>
+</div>`
+	converter := NewConverterWithOptions(Options{
+		StubSnippets: true,
+		Snippets:     map[string]string{"hotjar": "tracking.Hotjar"},
+		Imports:      []string{"example.com/app/analytics", "tracking example.com/app/hotjar"},
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`"example.com/app/analytics"`,
+		`tracking "example.com/app/hotjar"`,
+		"Div(\n\t\tanalytics.GoogleTag(\"G-ABC123\"),\n\t\ttracking.Hotjar(\"1234567\"),\n\t\tScript(",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "dataLayer") {
+		t.Errorf("Expected the gtag config script to be dropped.\nOutput:\n%s", result)
+	}
+	for _, d := range converter.Diagnostics() {
+		if d.Code == "snippet-import" {
+			t.Errorf("Unexpected diagnostic: %v", d)
+		}
+	}
+}
+
+func TestConvertStripConsent(t *testing.T) {
+	input := `<div>
+<script src="https://cdn.cookielaw.org/scripttemplates/otSDKStub.js" data-domain-script="abc"></script>
+<main><p>Content</p></main>
+<div id="onetrust-consent-sdk"><div id="onetrust-banner-sdk"><button>Accept</button></div></div>
+<div class="cookie-notice">We use cookies</div>
+</div>`
+
+	converter := NewConverterWithOptions(Options{StripConsent: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := "return Div(Main(P(T(\"Content\"))))"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+	var messages []string
+	for _, d := range converter.Diagnostics() {
+		messages = append(messages, d.Message)
+	}
+	if strings.Join(messages, "; ") != "removed 2 OneTrust consent element(s); removed 1 cookie banner consent element(s)" {
+		t.Errorf("Unexpected diagnostics: %q", messages)
+	}
+
+	converter = NewConverterWithOptions(Options{StripConsent: true, ConsentStub: "views.CookieConsent()", Imports: []string{"example.com/app/views"}})
+	result, err = converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected = "Div(Main(P(T(\"Content\"))), views.CookieConsent())"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+
+	// A banner at the top level of a fragment is left out too
+	converter = NewConverterWithOptions(Options{StripConsent: true})
+	result, err = converter.Convert(`<div id="cookie-banner">We use cookies</div><p>Hi</p>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.Contains(result, "cookies") || !strings.Contains(result, "return P(T(\"Hi\"))") {
+		t.Errorf("Expected the top-level banner to be removed.\nOutput:\n%s", result)
+	}
+	if diags := converter.Diagnostics(); len(diags) != 1 || diags[0].Message != "removed 1 cookie banner consent element(s)" {
+		t.Errorf("Unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestConvertA11yFix(t *testing.T) {
+	input := `<form id="email">
+<img src="/divider.png" class="divider">
+<img src="/logo.png">
+<label>Email address</label>
+<input type="email">
+<label>Name</label> <input name="full_name">
+<button @click="open = true">Options</button>
+<button>Send</button>
+</form>`
+
+	converter := NewConverterWithOptions(Options{A11yFix: true, DecorativeImages: "img.divider"})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := []string{
+		`Img(Src("/divider.png"), Class("divider"), Alt(""))`,
+		`Img(Src("/logo.png"))`,
+		`Label(For("email-address"), T("Email address"))`,
+		`Input(InputType("email"), Id("email-address"))`,
+		`Label(For("full-name"), T("Name"))`,
+		`Input(InputName("full_name"), Id("full-name"))`,
+		`Button(Custom("@click", "open = true"), T("Options"))`,
+		`Button(ButtonType("submit"), T("Send"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 5 {
+		t.Fatalf("Expected 4 reported fixes and a warning, got %d: %v", len(diags), diags)
+	}
+	var warned bool
+	for _, d := range diags {
+		warned = warned || (d.Code == "a11y-button-type" && d.Severity == SeverityWarning)
+	}
+	if !warned {
+		t.Errorf("Expected a warning about the scripted button, got %v", diags)
+	}
+}
+
+func TestConvertHeadingLevel(t *testing.T) {
+	input := `<section><h3>Pricing</h3><h4>Monthly</h4><h5>Details</h5><h6>Fine print</h6></section>`
+
+	converter := NewConverterWithOptions(Options{HeadingLevel: 2})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := "H2(T(\"Pricing\")),\n\t\tH3(T(\"Monthly\")),\n\t\tH4(T(\"Details\")),\n\t\tH5(T(\"Fine print\")),"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+
+	converter = NewConverterWithOptions(Options{HeadingLevel: 4})
+	result, err = converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected = "H4(T(\"Pricing\")),\n\t\tH5(T(\"Monthly\")),\n\t\tH6(T(\"Details\")),\n\t\tH6(T(\"Fine print\")),"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 2 || diags[1].Severity != SeverityWarning {
+		t.Errorf("Expected a warning about clamped headings, got %v", diags)
+	}
+}
+
+func TestConvertFormStructs(t *testing.T) {
+	input := `<form action="/signup"><input name="email"><input type="checkbox" name="terms">` +
+		`<input type="number" name="age"><select name="topics" multiple></select><button>Go</button></form>`
+
+	converter := NewConverterWithOptions(Options{FormStructs: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"\t\"net/http\"\n\t\"strconv\"\n",
+		"type SignupForm struct {\n\tEmail  string   `form:\"email\"`\n\tTerms  bool     `form:\"terms\"`\n\tAge    int      `form:\"age\"`\n\tTopics []string `form:\"topics\"`\n}",
+		"func BindSignupForm(r *http.Request) (SignupForm, error) {",
+		"form.Terms = r.Form.Get(\"terms\") != \"\"",
+		"return form, fmt.Errorf(\"age: %w\", err)",
+		"form.Topics = r.Form[\"topics\"]",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertFormStructsName(t *testing.T) {
+	for _, input := range []string{`<form><input name="q"></form>`, `<form id="form"><input name="q"></form>`} {
+		result, err := NewConverterWithOptions(Options{FormStructs: true}).Convert(input)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		for _, expected := range []string{"type FormData struct {", "func BindFormData(r *http.Request) (FormData, error) {"} {
+			if !strings.Contains(result, expected) {
+				t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+			}
+		}
+	}
+}
+
+func TestConvertFormStructsFieldNames(t *testing.T) {
+	input := `<form id="signup"><input name="first-name"><input name="first_name"><input name="FirstName"></form>`
+
+	result, err := NewConverterWithOptions(Options{FormStructs: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"FirstName  string `form:\"first-name\"`",
+		"FirstName2 string `form:\"first_name\"`",
+		"FirstName3 string `form:\"FirstName\"`",
+		"form.FirstName2 = r.Form.Get(\"first_name\")",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertFormValidation(t *testing.T) {
+	input := `<form id="signup"><input name="user" required minlength="3" pattern="[a-z]+">` +
+		`<input type="email" name="email"><input type="number" name="age" min="18"></form>`
+
+	converter := NewConverterWithOptions(Options{FormStructs: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"User  string `form:\"user\" validate:\"required,min=3\" pattern:\"^(?:[a-z]+)$\"`",
+		"Email string `form:\"email\" validate:\"omitempty,email\"`",
+		"Age   int    `form:\"age\" validate:\"omitempty,min=18\"`",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertCSRFToken(t *testing.T) {
+	input := `<form method="post"><input type="hidden" name="_token" value="stale"><button>Save</button></form>`
+
+	converter := NewConverter(false, false)
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{"(csrfToken string) Node", `InputValue(csrfToken)`} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "stale") {
+		t.Errorf("Expected the token value to be dropped.\nOutput:\n%s", result)
+	}
+
+	converter = NewConverterWithOptions(Options{CSRFHelper: "CSRFField()"})
+	result, err = converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := `Form(Method("post"), CSRFField(), Button(T("Save")))`
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+}
+
+func TestConvertPagination(t *testing.T) {
+	input := `<nav class="pagination"><ul><li><a href="/posts?p=1">1</a></li>` +
+		`<li aria-current="page"><span>2</span></li><li><a href="/posts?p=3">3</a></li></ul></nav>`
+
+	converter := NewConverterWithOptions(Options{Parameterize: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"(currentPage, totalPages int) Node",
+		"return Pagination(currentPage, totalPages)",
+		"func Pagination(current, total int) Node {",
+		`pages = append(pages, Li(Aria("current", "page"), Span(T(strconv.Itoa(page)))))`,
+		`pages = append(pages, Li(A(Href(fmt.Sprintf("/posts?p=%d", page)), T(strconv.Itoa(page)))))`,
+		`return Nav(Class("pagination"), Ul(Fragment(pages...)))`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertPaginationEnds(t *testing.T) {
+	input := `<nav class="pagination"><ul><li><a href="/blog?page=1">Previous</a></li>` +
+		`<li><a href="/blog?page=1">1</a></li><li aria-current="page"><span>2</span></li><li><a href="/blog?page=3">3</a></li>` +
+		`<li><a href="/blog?page=3" aria-label="Next page">»</a></li></ul></nav>`
+
+	result, err := NewConverterWithOptions(Options{Parameterize: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"var prev Node = Fragment()\n\tif current > 1 {\n\t\tprev = Li(A(Href(fmt.Sprintf(\"/blog?page=%d\", current-1)), T(\"Previous\")))",
+		"var next Node = Fragment()\n\tif current < total {\n\t\tnext = Li(A(Href(fmt.Sprintf(\"/blog?page=%d\", current+1)), Aria(\"label\", \"Next page\"), T(\"»\")))",
+		"prev,\n\t\t\tFragment(pages...),\n\t\t\tnext,",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, `Href("/blog?page=`) {
+		t.Errorf("Expected no frozen page links.\nOutput:\n%s", result)
+	}
+}
+
+func TestConvertBreadcrumb(t *testing.T) {
+	input := `<nav aria-label="breadcrumb"><ol><li><a href="/">Home</a></li><li>Docs</li></ol></nav>`
+
+	converter := NewConverterWithOptions(Options{Parameterize: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"{Label: \"Home\", Href: \"/\"},\n\t\t{Label: \"Docs\"},",
+		`crumbs = append(crumbs, Li(T(item.Label)))`,
+		`crumbs = append(crumbs, Li(A(Href(item.Href), T(item.Label))))`,
+		`return Nav(Aria("label", "breadcrumb"), Ol(Fragment(crumbs...)))`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertRelTokens(t *testing.T) {
+	input := `<link rel="preload  nofollow" href="a.css"><a href="/" rel="noopener noreferrer NOOPENER opener">Home</a>`
+
+	converter := NewConverterWithOptions(Options{})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{`Rel("preload nofollow")`, `Rel("noopener noreferrer opener")`} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+
+	var codes []string
+	for _, d := range converter.Diagnostics() {
+		codes = append(codes, d.Code)
+	}
+	expected := []string{"rel-context", "rel-duplicate", "rel-conflict"}
+	if !slices.Equal(codes, expected) {
+		t.Errorf("Expected diagnostics %v, got %v", expected, codes)
+	}
+}
+
+func TestConvertMetaHelpers(t *testing.T) {
+	input := `<!DOCTYPE html><html><head><meta name="viewport" content="width=device-width,initial-scale=1.0">` +
+		`<meta name="theme-color" media="(prefers-color-scheme: dark)" content="#000">` +
+		`<meta name="theme-color" media="(prefers-color-scheme: light)" content="#fff"></head><body></body></html>`
+
+	converter := NewConverterWithOptions(Options{MetaHelpers: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		`Head(Viewport(), ThemeColor("#fff", "#000"))`,
+		"func Viewport() Node {\n\treturn Meta(Name(\"viewport\"), Content(\"width=device-width, initial-scale=1\"))\n}",
+		"func ThemeColor(light, dark string) Node {",
+		"return Meta(Name(\"theme-color\"), Content(light))",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+	if diags := converter.Diagnostics(); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+
+	if _, err := converter.Convert(`<!DOCTYPE html><html><body><p>Hi</p></body></html>`); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if diags := converter.Diagnostics(); len(diags) != 1 || diags[0].Code != "viewport-missing" {
+		t.Errorf("Expected a viewport-missing warning, got %v", diags)
+	}
+}
+
+func TestConvertScriptOrder(t *testing.T) {
+	input := "<!DOCTYPE html><html><head><title>Shop</title>\n<img src=\"pixel.gif\">\n<script src=\"app.js\"></script></head>" +
+		"<body><p>Hi</p></body></html>"
+
+	converter := NewConverterWithOptions(Options{})
+	if _, err := converter.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "script-moved" || diags[0].Line != 3 {
+		t.Errorf("Expected a script-moved warning on line 3, got %v", diags)
+	}
+
+	converter = NewConverterWithOptions(Options{PinScripts: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := `Head(HeadTitle(T("Shop")), Script(ScriptSrc("app.js"))),`
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+}
+
+func TestConvertDoctype(t *testing.T) {
+	tests := []struct {
+		doctype, code, constant string
+	}{
+		{"<!DOCTYPE html>", "", ""},
+		{"", "doctype-missing", ""},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`, "doctype-legacy",
+			`const PageDoctype = "<!DOCTYPE html PUBLIC \"-//W3C//DTD HTML 4.01//EN\" \"http://www.w3.org/TR/html4/strict.dtd\">"`},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`, "doctype-quirks", "const PageDoctype"},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`, "doctype-quirks", "const PageDoctype"},
+	}
+	for _, tt := range tests {
+		converter := NewConverterWithOptions(Options{})
+		result, err := converter.ConvertResult(context.Background(), tt.doctype+`<html><body><p>Hi</p></body></html>`)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		var codes []string
+		for _, d := range result.Diagnostics {
+			codes = append(codes, d.Code)
+		}
+		if tt.code == "" && len(codes) != 0 || tt.code != "" && !slices.Equal(codes, []string{tt.code}) {
+			t.Errorf("%s: expected diagnostic %q, got %v", tt.doctype, tt.code, codes)
+		}
+		if tt.constant == "" && strings.Contains(result.Code, "Doctype") || !strings.Contains(result.Code, tt.constant) {
+			t.Errorf("%s: expected output to contain %q.\nOutput:\n%s", tt.doctype, tt.constant, result.Code)
+		}
+		if tt.doctype != "" && result.Doctype == "" {
+			t.Errorf("%s: expected the doctype to be recorded", tt.doctype)
+		}
+	}
+}
+
+func TestConvertNormalizesInput(t *testing.T) {
+	converter := NewConverterWithOptions(Options{TextMode: TextVerbatim})
+	result, err := converter.Convert("\uFEFF<pre>one\r\ntwo\rthree</pre>")
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.ContainsAny(result, "\r\uFEFF") {
+		t.Errorf("Expected no carriage returns or byte order marks.\nOutput:\n%s", result)
+	}
+	expected := "T(`one\ntwo\nthree`)"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+
+	converter = NewConverterWithOptions(Options{Validate: true})
+	if _, err := converter.Convert("\uFEFF<!DOCTYPE html>\r\n<html><body>\r\n<p><div>x</div></p>\r\n</body></html>"); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if diags := converter.Diagnostics(); len(diags) == 0 || diags[0].Line != 3 {
+		t.Errorf("Expected a diagnostic on line 3, got %v", diags)
+	}
+}
+
+func TestIsFullPage(t *testing.T) {
+	tests := []struct {
+		input string
+		page  bool
+	}{
+		{"<!doctype html><p>Hi</p>", true},
+		{"<HTML><body>Hi</body></HTML>", true},
+		{"<Head><title>x</title></Head><BODY>Hi</BODY>", true},
+		{"<p>Write <code>&lt;!DOCTYPE html&gt;</code> first</p>", false},
+		{"<!-- copied from <html> --><div>Hi</div>", false},
+		{`<script>document.write("<html><body>")</script>`, false},
+		{`<div title="<html>">Hi</div>`, false},
+		{"<body><p>Hi</p></body>", false},
+	}
+	for _, tt := range tests {
+		if got := isFullPage(tt.input); got != tt.page {
+			t.Errorf("isFullPage(%q) = %v, want %v", tt.input, got, tt.page)
+		}
+	}
+}
+
+func TestConvertMode(t *testing.T) {
+	converter := NewConverterWithOptions(Options{Mode: ModePage})
+	result, err := converter.Convert(`<p>Hi</p>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, `return Html(Head(), Body(P(T("Hi"))))`) {
+		t.Errorf("Expected a full page.\nOutput:\n%s", result)
+	}
+
+	converter = NewConverterWithOptions(Options{Mode: ModeFragment})
+	result, err = converter.Convert(`<!DOCTYPE html><html><body><p>Hi</p></body></html>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if strings.Contains(result, "Html(") || !strings.Contains(result, `P(T("Hi"))`) {
+		t.Errorf("Expected a fragment.\nOutput:\n%s", result)
+	}
+
+	if _, err := NewConverterWithOptions(Options{Mode: "document"}).Convert(`<p>Hi</p>`); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestConvertMultipleDocuments(t *testing.T) {
+	input := "<!-- archive -->\n<!DOCTYPE html><html><body><p>One</p></body></html>\n" +
+		"<!doctype html>\n<html><body><img src=\"a.png\">\n<div><p>Two</span></div></body></html>"
+
+	converter := NewConverterWithOptions(Options{ReportMutations: true})
+	result, err := converter.ConvertResult(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"func Page1() Node {\n\treturn Html(Head(), Body(P(T(\"One\"))))\n}",
+		"func Page2() Node {",
+	} {
+		if !strings.Contains(result.Code, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result.Code)
+		}
+	}
+	if len(result.Functions) != 2 || result.Functions[1].Name != "Page2" {
+		t.Errorf("Expected two page functions, got %v", result.Functions)
+	}
+	if diags := result.Diagnostics; len(diags) != 1 || diags[0].Code != "parser-dropped" || diags[0].Line != 5 {
+		t.Errorf("Expected the stray end tag of the second document on line 5, got %v", diags)
+	}
+
+	converter = NewConverterWithOptions(Options{Mode: ModeFragment})
+	result, err = converter.ConvertResult(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if len(result.Functions) != 1 {
+		t.Errorf("Expected a single function in fragment mode, got %v", result.Functions)
+	}
+}
+
+func TestConvertMIME(t *testing.T) {
+	input := "MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/related; boundary=\"b\"\r\n\r\n" +
+		"--b\r\nContent-Type: text/html; charset=iso-8859-1\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n" +
+		"<div><p style=3D\"background: url(cid:bg)\">Caf=E9</p><img src=3D\"cid:logo\"></div>\r\n" +
+		"--b\r\nContent-Type: image/gif\r\nContent-Transfer-Encoding: base64\r\nContent-ID: <logo>\r\n\r\nR0lGODlh\r\n" +
+		"--b\r\nContent-Type: image/png\r\nContent-Transfer-Encoding: base64\r\nContent-ID: <bg>\r\n\r\niVBORw0K\r\n" +
+		"--b--\r\n"
+
+	converter := NewConverterWithOptions(Options{Filename: "welcome.eml"})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, expected := range []string{
+		"func Welcome() Node",
+		`url(data:image/png;base64,iVBORw0K)`,
+		`T("Café")`,
+		`Img(Src("data:image/gif;base64,R0lGODlh"))`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+		}
+	}
+
+	_, err = NewConverterWithOptions(Options{Filename: "plain.eml"}).Convert("Content-Type: text/plain\r\n\r\nHi")
+	if err == nil || !strings.Contains(err.Error(), "no text/html part") {
+		t.Errorf("Expected a missing HTML part error, got %v", err)
+	}
+}
+
+func TestConvertPageMeta(t *testing.T) {
+	input := `<!DOCTYPE html><html><head><title>Pricing</title><meta name="viewport" content="width=device-width">` +
+		`<meta property="og:title" content="Acme pricing"><meta name="description" content="Plans">` +
+		`<meta property="og:image" content="/og.png"></head><body></body></html>`
+
+	converter := NewConverterWithOptions(Options{PageMeta: true})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	expected := "// PageMeta is the SEO metadata of the source page of Page\nvar PageMeta = struct {\n" +
+		"\tTitle       string\n\tDescription string\n\tImage       string\n\tURL         string\n\tSiteName    string\n\tType        string\n" +
+		"}{\n\tTitle:       \"Acme pricing\",\n\tDescription: \"Plans\",\n\tImage:       \"/og.png\",\n}\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected output to contain %q.\nOutput:\n%s", expected, result)
+	}
+	if diags := converter.Diagnostics(); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestConvertMappingOverrides(t *testing.T) {
+	overrides := MappingOverrides{
+		Elements:   map[string]string{"search": "Search", "Selectmenu": "ui.SelectMenu"},
+		Attributes: map[string]string{"popover": "Popover", "inert": "Inert()", "input[list]": "InputList", "href": "SafeHref", "hx-on": "htmx.HxOn"},
+	}
+	if err := overrides.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	converter := NewConverterWithOptions(Options{Mappings: overrides, Imports: []string{"example.com/ui"}})
+	result, err := converter.Convert(`<search popover="auto" inert><a href="/x">x</a><input list="l"><datalist list="l"></datalist><selectmenu hx-on="x"></selectmenu></search>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`Search(`,
+		`Popover("auto")`,
+		`Inert()`,
+		`SafeHref("/x")`,
+		`InputList("l")`,
+		`Custom("list", "l")`,
+		`ui.SelectMenu(htmx.HxOn("x"))`,
+		`"github.com/plainkit/htmx"`,
+		`"example.com/ui"`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %s in:\n%s", want, result)
+		}
+	}
+
+	bad := MappingOverrides{Attributes: map[string]string{"popover": "Popover(x)"}}
+	if err := bad.normalize(); err == nil {
+		t.Error("Expected an error for a mapping that is not a function name")
+	}
+}
+
+func TestConvertPackageAndFuncName(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		input string
+		wants []string
+	}{
+		{"package and func", Options{Package: "views", FuncName: "LoginForm"}, `<form><input name="user"></form>`, []string{"package views\n", "func LoginForm() Node {"}},
+		{"unexported file name", Options{Filename: "login-form.html", Unexported: true}, `<p>x</p>`, []string{"package main\n", "func loginForm() Node {"}},
+		{"reserved file name", Options{Filename: "footer.html"}, `<footer>x</footer>`, []string{"func FooterComponent() Node {\n\treturn Footer("}},
+		{"reserved short file name", Options{Filename: "views/t.html"}, `<p>x</p>`, []string{"func TComponent() Node {"}},
+		{"exported func", Options{FuncName: "card"}, `<p>x</p>`, []string{"func Card() Node {"}},
+		{"unexported page", Options{Unexported: true}, `<!DOCTYPE html><html><body></body></html>`, []string{"func page() Node {"}},
+		{"fragments", Options{FuncName: "Rows"}, `<p>a</p><p>b</p>`, []string{"func Rows() []Node {"}},
+		{"documents", Options{FuncName: "Mail"}, "<!DOCTYPE html><html><body>a</body></html>\n<!DOCTYPE html><html><body>b</body></html>", []string{"func Mail1() Node {", "func Mail2() Node {"}},
+		{"directive wins", Options{FuncName: "Other"}, `<!-- plainkit:func Hero --><section>x</section>`, []string{"func Hero() Node {"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewConverterWithOptions(tt.opts).Convert(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(result, want) {
+					t.Errorf("Expected %q in:\n%s", want, result)
+				}
+			}
+		})
+	}
+
+	if _, err := NewConverterWithOptions(Options{Package: "my-views"}).Convert(`<p>x</p>`); err == nil {
+		t.Error("Expected an error for an invalid package name")
+	}
+}
+
+func TestConvertParamNames(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html>
+<head>
+	<title>Pricing - Acme</title>
+	<meta name="description" content="Plans for every team">
+	<meta property="og:title" content="Pricing">
+	<meta property="og:description" content="Plans for every team">
+	<meta name="twitter:title" content="Pricing">
+</head>
+<body></body>
+</html>`
+
+	tests := []struct {
+		strategy string
+		expected []string
+	}{
+		{ParamNamesUnique, []string{
+			"func Page(title, description, title2 string) Node",
+			`Meta(Custom("property", "og:title"), Content(title2))`,
+			`Meta(Custom("property", "og:description"), Content(description))`,
+			`Meta(Name("twitter:title"), Content(title2))`,
+		}},
+		{ParamNamesSource, []string{
+			"func Page(title, description, ogTitle, ogDescription, twitterTitle string) Node",
+			`Meta(Custom("property", "og:title"), Content(ogTitle))`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			result, err := NewConverterWithOptions(Options{Parameterize: true, ParamNames: tt.strategy}).Convert(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, exp := range tt.expected {
+				if !strings.Contains(result, exp) {
+					t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+				}
+			}
+		})
+	}
+
+	if _, err := NewConverterWithOptions(Options{Parameterize: true, ParamNames: "random"}).Convert(input); err == nil {
+		t.Error("Expected an error for an unknown naming strategy")
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// contentSecurityPolicy holds the source lists of a parsed CSP header value
+type contentSecurityPolicy map[string][]string
+
+// parseCSP parses a Content-Security-Policy header value
+func parseCSP(policy string) contentSecurityPolicy {
+	csp := make(contentSecurityPolicy)
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, seen := csp[name]; seen {
+			// Browsers ignore repeated directives
+			continue
+		}
+		csp[name] = fields[1:]
+	}
+	return csp
+}
+
+// sources returns the source list governing a directive, following the CSP fallback chain
+func (csp contentSecurityPolicy) sources(directives ...string) ([]string, bool) {
+	for _, d := range append(directives, "default-src") {
+		if sources, ok := csp[d]; ok {
+			return sources, true
+		}
+	}
+	return nil, false
+}
+
+// allowsInline reports whether inline content is allowed by a source list. The
+// element's nonce and content are matched against nonce and hash sources.
+func allowsInline(sources []string, nonce, content string, hashesAllowed bool) bool {
+	hasNonceOrHash := false
+	unsafeInline := false
+	for _, src := range sources {
+		src = strings.Trim(src, "'")
+		switch {
+		case src == "unsafe-inline":
+			unsafeInline = true
+		case strings.HasPrefix(src, "nonce-"):
+			hasNonceOrHash = true
+			if nonce != "" {
+				return true
+			}
+		case strings.HasPrefix(src, "sha256-"), strings.HasPrefix(src, "sha384-"), strings.HasPrefix(src, "sha512-"):
+			hasNonceOrHash = true
+			if hashesAllowed && src == cspHash(src[:6], content) {
+				return true
+			}
+		}
+	}
+	// A nonce or hash source disables 'unsafe-inline'
+	return unsafeInline && !hasNonceOrHash
+}
+
+// cspHash computes a CSP hash source expression such as sha256-... for content
+func cspHash(algorithm, content string) string {
+	var sum []byte
+	switch algorithm {
+	case "sha256":
+		h := sha256.Sum256([]byte(content))
+		sum = h[:]
+	case "sha384":
+		h := sha512.Sum384([]byte(content))
+		sum = h[:]
+	case "sha512":
+		h := sha512.Sum512([]byte(content))
+		sum = h[:]
+	}
+	return algorithm + "-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// checkCSP reports inline scripts, styles and handlers the configured policy would block
+func (c *Converter) checkCSP(nodes []*html.Node) {
+	if c.opts.CSP == "" {
+		return
+	}
+	csp := parseCSP(c.opts.CSP)
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			c.checkElementCSP(csp, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+// checkElementCSP checks a single element against a policy
+func (c *Converter) checkElementCSP(csp contentSecurityPolicy, n *html.Node) {
+	nonce := attrValue(n, "nonce")
+
+	switch n.Data {
+	case "script":
+		content := textContent(n)
+		if attrValue(n, "src") == "" && strings.TrimSpace(content) != "" && !isDataScript(n) {
+			if sources, ok := csp.sources("script-src-elem", "script-src"); ok && !allowsInline(sources, nonce, content, true) {
+				c.report(n, SeverityWarning, "csp-inline-script", "inline script is blocked by the Content-Security-Policy")
+			}
+		}
+	case "style":
+		content := textContent(n)
+		if sources, ok := csp.sources("style-src-elem", "style-src"); ok && !allowsInline(sources, nonce, content, true) {
+			c.report(n, SeverityWarning, "csp-inline-style", "inline style element is blocked by the Content-Security-Policy")
+		}
+	}
+
+	for _, attr := range n.Attr {
+		switch {
+		case attr.Key == "style":
+			if sources, ok := csp.sources("style-src-attr", "style-src"); ok && !allowsInline(sources, "", attr.Val, false) {
+				c.report(n, SeverityWarning, "csp-inline-style", "style attribute is blocked by the Content-Security-Policy")
+			}
+		case strings.HasPrefix(attr.Key, "on"):
+			if sources, ok := csp.sources("script-src-attr", "script-src"); ok && !allowsInline(sources, "", attr.Val, false) {
+				c.report(n, SeverityWarning, "csp-inline-handler", "inline %s handler is blocked by the Content-Security-Policy", attr.Key)
+			}
+		case (attr.Key == "href" || attr.Key == "action") && strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Val)), "javascript:"):
+			if sources, ok := csp.sources("script-src"); ok && !allowsInline(sources, "", "", false) {
+				c.report(n, SeverityWarning, "csp-javascript-url", "javascript: URL is blocked by the Content-Security-Policy")
+			}
+		}
+	}
+}
+
+// isDataScript reports whether a script element holds data rather than executable code
+func isDataScript(n *html.Node) bool {
+	typ := strings.ToLower(strings.TrimSpace(attrValue(n, "type")))
+	switch typ {
+	case "", "text/javascript", "application/javascript", "module":
+		return false
+	}
+	return true
+}
+
+// attrValue returns the value of an attribute, or an empty string if it is absent
+func attrValue(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// textContent concatenates the text of all descendant text nodes
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			sb.WriteString(node.Data)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// csrfFieldNames are the hidden field names used for CSRF tokens by common frameworks
+var csrfFieldNames = map[string]bool{
+	"csrf":                       true,
+	"csrf_token":                 true,
+	"csrftoken":                  true,
+	"_csrf":                      true,
+	"_csrf_token":                true,
+	"_token":                     true, // Laravel
+	"gorilla.csrf.token":         true, // gorilla/csrf
+	"csrfmiddlewaretoken":        true, // Django
+	"authenticity_token":         true, // Rails
+	"__requestverificationtoken": true, // ASP.NET
+}
+
+// csrfParam is the parameter carrying the token when no CSRFHelper is configured
+const csrfParam = "csrfToken"
+
+// isCSRFInput reports whether n is a hidden input carrying a CSRF token
+func isCSRFInput(n *html.Node) bool {
+	return n.Data == "input" && strings.EqualFold(attrValue(n, "type"), "hidden") &&
+		csrfFieldNames[strings.ToLower(attrValue(n, "name"))]
+}
+
+// findCSRFInputs finds hidden CSRF token inputs, whose values are only valid
+// for the session the HTML was saved from
+func (c *Converter) findCSRFInputs(nodes []*html.Node) {
+	c.csrfInputs = make(map[*html.Node]bool)
+	forEachElement(nodes, func(n *html.Node) {
+		if !isCSRFInput(n) {
+			return
+		}
+		c.csrfInputs[n] = true
+		if c.opts.CSRFHelper != "" {
+			c.report(n, SeverityInfo, "csrf-token", "CSRF field %q replaced by %s", attrValue(n, "name"), c.opts.CSRFHelper)
+		} else {
+			c.report(n, SeverityInfo, "csrf-token", "CSRF field %q takes its value from the %s parameter", attrValue(n, "name"), csrfParam)
+		}
+	})
+	if c.opts.CSRFHelper != "" && len(c.csrfInputs) > 0 && !c.hasQualifier(c.opts.CSRFHelper) && strings.Contains(c.opts.CSRFHelper, ".") {
+		c.report(nil, SeverityWarning, "csrf-import", "no import provides %s; add its package to the config imports", c.opts.CSRFHelper)
+	}
+}
+
+// csrfAttribute returns the value attribute of a CSRF input, taken from a parameter
+func (c *Converter) csrfAttribute(n *html.Node, attr html.Attribute) (string, bool) {
+	if !c.csrfInputs[n] || attr.Key != "value" {
+		return "", false
+	}
+	attr.Val = patchParamPlaceholder
+	code := c.convertAttribute(attr, n.Data)
+	return strings.Replace(code, c.quoteValue(patchParamPlaceholder), c.param(csrfParam, "string"), 1), true
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// customPropertyUse matches var(--name) references, capturing whether a fallback follows
+	customPropertyUse = regexp.MustCompile(`var\(\s*(--[A-Za-z0-9_-]+)\s*(,)?`)
+	// customPropertyDecl matches --name: value declarations
+	customPropertyDecl = regexp.MustCompile(`(--[A-Za-z0-9_-]+)\s*:\s*([^;}]*)`)
+)
+
+// themeToken is a custom property referenced by the converted markup
+type themeToken struct {
+	name  string
+	value string
+}
+
+// parseCustomProperties returns the custom properties declared in CSS, the first declaration winning
+func parseCustomProperties(css string) map[string]string {
+	props := make(map[string]string)
+	for _, m := range customPropertyDecl.FindAllStringSubmatch(css, -1) {
+		if _, ok := props[m[1]]; !ok {
+			props[m[1]] = strings.TrimSpace(m[2])
+		}
+	}
+	return props
+}
+
+// loadTheme reads the custom properties declared in a CSS theme file
+func loadTheme(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+	return parseCustomProperties(string(data)), nil
+}
+
+// checkCustomProperties collects the CSS custom properties used in style
+// attributes and style elements, reporting those the theme does not define
+func (c *Converter) checkCustomProperties(nodes []*html.Node) {
+	c.themeTokens = nil
+	if c.opts.Theme == nil && !c.opts.ThemeTokens {
+		return
+	}
+
+	// Styles of the document itself, such as :root in a style element, define properties too
+	type use struct {
+		n        *html.Node
+		name     string
+		fallback bool
+	}
+	defined := make(map[string]string)
+	var uses []use
+	forEachElement(nodes, func(n *html.Node) {
+		var css []string
+		if style := attrValue(n, "style"); style != "" {
+			css = append(css, style)
+		}
+		if n.Data == "style" && n.FirstChild != nil {
+			css = append(css, n.FirstChild.Data)
+		}
+		for _, s := range css {
+			for name, value := range parseCustomProperties(s) {
+				if _, ok := defined[name]; !ok {
+					defined[name] = value
+				}
+			}
+			for _, m := range customPropertyUse.FindAllStringSubmatch(s, -1) {
+				uses = append(uses, use{n: n, name: m[1], fallback: m[2] != ""})
+			}
+		}
+	})
+
+	seen := make(map[string]bool)
+	for _, u := range uses {
+		if seen[u.name] {
+			continue
+		}
+		seen[u.name] = true
+
+		value, inTheme := c.opts.Theme[u.name]
+		if !inTheme {
+			value = defined[u.name]
+		}
+		c.themeTokens = append(c.themeTokens, themeToken{name: u.name, value: value})
+
+		if c.opts.Theme == nil || inTheme {
+			continue
+		}
+		if _, ok := defined[u.name]; ok {
+			continue
+		}
+		if u.fallback {
+			c.report(u.n, SeverityInfo, "css-var-undefined",
+				"custom property %s is not defined by the theme; its fallback is used", u.name)
+		} else {
+			c.report(u.n, SeverityWarning, "css-var-undefined",
+				"custom property %s is not defined by the theme", u.name)
+		}
+	}
+}
+
+// writeThemeTokens renders the map of custom properties referenced by the component
+func (c *Converter) writeThemeTokens(buf codeWriter) {
+	if !c.opts.ThemeTokens || len(c.themeTokens) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "var %s = map[string]string{\n", c.themeTokensName())
+	for _, token := range c.themeTokens {
+		fmt.Fprintf(buf, "\t%s: %s,\n", c.quoteValue(token.name), c.quoteValue(token.value))
+	}
+	buf.WriteString("}\n\n")
+}
+
+// themeTokensName is the name of the theme tokens map, derived from the main function
+func (c *Converter) themeTokensName() string {
+	return goIdentifier(c.mainFunc.name+" theme tokens", false)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonSocket  string
+	daemonTimeout time.Duration
+)
+
+// daemonMaxRequest is the longest request line the daemon accepts
+var daemonMaxRequest = 64 * 1024 * 1024
+
+// daemonRequest is a single conversion request, sent as one JSON object per line
+type daemonRequest struct {
+	HTML    string  `json:"html"`
+	Options Options `json:"options"`
+}
+
+// daemonResponse is the reply to a daemonRequest
+type daemonResponse struct {
+	Code        string       `json:"code,omitempty"`
+	Imports     []string     `json:"imports,omitempty"`
+	Functions   []FuncInfo   `json:"functions,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Serve conversion requests over a unix socket",
+	Long: `Run a long-lived converter that serves requests over a unix socket, avoiding
+process startup costs when build systems convert many files.
+
+Each request is a JSON object on its own line:
+  {"html": "<div>Hello</div>", "options": {"HTMX": true, "Filename": "card.html"}}
+
+and is answered with one JSON line:
+  {"code": "package main...", "imports": [...], "functions": [...], "diagnostics": [...]}
+or {"error": "..."}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := removeStaleSocket(daemonSocket); err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("unix", daemonSocket)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", daemonSocket, err)
+		}
+
+		ctx := cmd.Context()
+		go func() {
+			<-ctx.Done()
+			if err := listener.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing socket: %v\n", err)
+			}
+		}()
+
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", daemonSocket)
+		return serveDaemon(ctx, listener)
+	},
+}
+
+// removeStaleSocket removes a socket left behind by a previous run at path,
+// refusing to remove anything that is not a socket
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// serveDaemon accepts connections until the listener is closed, cancelling
+// conversions still in progress once ctx is done
+func serveDaemon(ctx context.Context, listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go handleDaemonConn(ctx, conn)
+	}
+}
+
+// handleDaemonConn answers requests on a connection until the client disconnects
+func handleDaemonConn(ctx context.Context, conn net.Conn) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing connection: %v\n", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, min(64*1024, daemonMaxRequest)), daemonMaxRequest)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req daemonRequest
+		var resp daemonResponse
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			resp = newDaemonResponse(convertRequest(ctx, req))
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+
+	// A request that cannot be read is answered before the connection closes
+	if err := scanner.Err(); err != nil {
+		msg := fmt.Sprintf("failed to read request: %v", err)
+		if errors.Is(err, bufio.ErrTooLong) {
+			msg = fmt.Sprintf("request exceeds %d bytes", daemonMaxRequest)
+		}
+		_ = encoder.Encode(daemonResponse{Error: msg})
+	}
+}
+
+// newDaemonResponse returns the reply carrying the result of a conversion or
+// its error
+func newDaemonResponse(result Result, err error) daemonResponse {
+	resp := daemonResponse{
+		Code:        result.Code,
+		Imports:     result.Imports,
+		Functions:   result.Functions,
+		Diagnostics: result.Diagnostics,
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// convertRequest converts the HTML of one request, giving up after the configured timeout
+func convertRequest(ctx context.Context, req daemonRequest) (Result, error) {
+	if daemonTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, daemonTimeout)
+		defer cancel()
+	}
+	return ConvertResult(ctx, req.HTML, req.Options)
+}
+
+func init() {
+	daemonCmd.Flags().StringVar(&daemonSocket, "socket", "plainkit-converter.sock", "Unix socket path to listen on")
+	daemonCmd.Flags().DurationVar(&daemonTimeout, "timeout", 0, "Abandon a request whose conversion takes longer than this, e.g. 5s (0 means no limit)")
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDaemonConvert(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "converter.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- serveDaemon(context.Background(), listener) }()
+	defer func() {
+		_ = listener.Close()
+		if err := <-done; err != nil {
+			t.Errorf("serveDaemon returned error: %v", err)
+		}
+	}()
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	requests := []string{
+		`{"html": "<button hx-get=\"/data\">Load</button>", "options": {"HTMX": true}}`,
+		`not json`,
+	}
+	for i, req := range requests {
+		if _, err := conn.Write([]byte(req + "\n")); err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("Failed to read response: %v", err)
+		}
+		var resp daemonResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			t.Fatalf("Invalid response %q: %v", line, err)
+		}
+
+		switch i {
+		case 0:
+			if resp.Error != "" || !strings.Contains(resp.Code, `htmx.HxGet("/data")`) {
+				t.Errorf("Unexpected response: %+v", resp)
+			}
+			if len(resp.Imports) != 2 || resp.Imports[1] != `"github.com/plainkit/htmx"` {
+				t.Errorf("Unexpected imports: %q", resp.Imports)
+			}
+			if len(resp.Functions) != 1 || resp.Functions[0].Name != "Component" {
+				t.Errorf("Unexpected functions: %+v", resp.Functions)
+			}
+		case 1:
+			if !strings.Contains(resp.Error, "invalid request") {
+				t.Errorf("Expected invalid request error, got %+v", resp)
+			}
+		}
+	}
+}
+
+func TestDaemonRequestTooLong(t *testing.T) {
+	defer func(limit int) { daemonMaxRequest = limit }(daemonMaxRequest)
+	daemonMaxRequest = 1024
+
+	client, server := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handleDaemonConn(context.Background(), server)
+		close(done)
+	}()
+	defer func() {
+		_ = client.Close()
+		<-done
+	}()
+
+	// The pipe blocks until the daemon reads, which it stops doing at the limit
+	go func() {
+		_, _ = client.Write([]byte(`{"html": "` + strings.Repeat("x", 4096) + `"}` + "\n"))
+	}()
+	line, err := bufio.NewReader(client).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	var resp daemonResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		t.Fatalf("Invalid response %q: %v", line, err)
+	}
+	if !strings.Contains(resp.Error, "request exceeds 1024 bytes") {
+		t.Errorf("Expected an error for the overlong request, got %+v", resp)
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeStaleSocket(file); err == nil {
+		t.Error("Expected an error for a regular file")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Expected the regular file to be kept: %v", err)
+	}
+	if err := removeStaleSocket(filepath.Join(dir, "missing.sock")); err != nil {
+		t.Errorf("Expected a missing socket to be ignored, got %v", err)
+	}
+
+	socket := filepath.Join(dir, "converter.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	// Leave the socket file behind as a crashed daemon would
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = listener.Close()
+	if err := removeStaleSocket(socket); err != nil {
+		t.Errorf("Expected the stale socket to be removed, got %v", err)
+	}
+	if _, err := os.Lstat(socket); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// darkPrefix is the Tailwind variant applying classes in dark mode
+const darkPrefix = "dark:"
+
+// colorClass matches Tailwind utilities that set a color, such as
+// bg-white, text-slate-700 or border-[#e5e7eb]
+var colorClass = regexp.MustCompile(`^(bg|text|border(-[xytrbl])?|ring|divide|from|via|to|fill|stroke|outline|decoration|placeholder|shadow|accent|caret)-` +
+	`(white|black|transparent|current|\[#[0-9a-fA-F]+\]|` +
+	`(slate|gray|zinc|neutral|stone|red|orange|amber|yellow|lime|green|emerald|teal|cyan|sky|blue|indigo|violet|purple|fuchsia|pink|rose)-\d{2,3})(/\d+)?$`)
+
+// splitDarkClasses separates the dark: classes of a class list from the others
+func splitDarkClasses(value string) (base, dark []string) {
+	for _, class := range strings.Fields(value) {
+		if rest, ok := strings.CutPrefix(class, darkPrefix); ok {
+			dark = append(dark, rest)
+		} else {
+			base = append(base, class)
+		}
+	}
+	return base, dark
+}
+
+// colorClasses returns the color utilities among classes, ignoring variants such as hover:
+func colorClasses(classes []string) []string {
+	var colors []string
+	for _, class := range classes {
+		utility := class[strings.LastIndex(class, ":")+1:]
+		if colorClass.MatchString(utility) {
+			colors = append(colors, class)
+		}
+	}
+	return colors
+}
+
+// checkThemeCoverage reports how many elements setting colors have dark mode
+// variants, for every component: each root and each region split into a
+// function of its own. Elements inside a data-theme scope take their colors
+// from the theme and count as covered.
+func (c *Converter) checkThemeCoverage(nodes []*html.Node) {
+	if !c.opts.ThemeCoverage {
+		return
+	}
+
+	type coverage struct {
+		root            *html.Node
+		name            string
+		colored         int
+		covered         []*html.Node
+		missing         []*html.Node
+		themes          []string
+		usesDarkClasses bool
+	}
+	var units []*coverage
+
+	var walk func(n *html.Node, unit *coverage, themed bool)
+	walk = func(n *html.Node, unit *coverage, themed bool) {
+		if n.Type == html.ElementNode {
+			if p := c.patches[n]; p != nil && p.Func != "" && n != unit.root {
+				unit = &coverage{root: n, name: p.Func}
+				units = append(units, unit)
+			}
+			if hasAttr(n, "data-theme") {
+				themed = true
+				if theme := strings.TrimSpace(attrValue(n, "data-theme")); theme != "" && !containsString(unit.themes, theme) {
+					unit.themes = append(unit.themes, theme)
+				}
+			}
+			base, dark := splitDarkClasses(attrValue(n, "class"))
+			if len(dark) > 0 {
+				unit.usesDarkClasses = true
+			}
+			if len(colorClasses(base)) > 0 {
+				unit.colored++
+				if len(dark) > 0 || themed {
+					unit.covered = append(unit.covered, n)
+				} else {
+					unit.missing = append(unit.missing, n)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, unit, themed)
+		}
+	}
+	for _, n := range nodes {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		unit := &coverage{root: n}
+		if p := c.patches[n]; p != nil {
+			unit.name = p.Func
+		}
+		units = append(units, unit)
+		walk(n, unit, false)
+	}
+
+	for _, unit := range units {
+		if unit.colored == 0 && len(unit.themes) == 0 {
+			continue
+		}
+		subject := "dark mode variants"
+		if unit.name != "" {
+			subject = unit.name + ": " + subject
+		}
+		message := fmt.Sprintf("%s cover %d of %d elements with color classes", subject, len(unit.covered), unit.colored)
+		if len(unit.themes) > 0 {
+			message += fmt.Sprintf("; data-theme values: %s", strings.Join(unit.themes, ", "))
+		}
+		c.report(unit.root, SeverityInfo, "theme-coverage", "%s", message)
+
+		// Partial dark mode support usually means some elements were forgotten
+		if !unit.usesDarkClasses {
+			continue
+		}
+		for _, n := range unit.missing {
+			base, _ := splitDarkClasses(attrValue(n, "class"))
+			c.report(n, SeverityWarning, "dark-variant-missing",
+				"%s has no dark: variant", strings.Join(colorClasses(base), " "))
+		}
+	}
+}
+
+// darkClassExpr returns a call to the withDark helper for class lists that
+// mix dark: classes with others, keeping the two apart in generated code
+func (c *Converter) darkClassExpr(value string) (string, bool) {
+	if !c.opts.DarkVariants {
+		return "", false
+	}
+	if _, ok := c.constNames[constKey{"class", value}]; ok {
+		return "", false
+	}
+	base, dark := splitDarkClasses(value)
+	if len(base) == 0 || len(dark) == 0 {
+		return "", false
+	}
+
+	if c.darkFunc == nil {
+		name := c.uniqueFuncName("withDark")
+		c.darkFunc = &funcDecl{name: name, result: "string", raw: darkHelper(name)}
+		c.funcs = append(c.funcs, c.darkFunc)
+	}
+	args := []string{c.quoteValue(strings.Join(base, " "))}
+	for _, class := range dark {
+		args = append(args, c.quoteValue(class))
+	}
+	return fmt.Sprintf("%s(%s)", c.darkFunc.name, strings.Join(args, ", ")), true
+}
+
+// darkHelper returns the declaration of the helper joining base and dark mode classes
+func darkHelper(name string) string {
+	return fmt.Sprintf(`// %[1]s returns the base classes followed by classes applied in dark mode
+func %[1]s(base string, dark ...string) string {
+	for _, class := range dark {
+		base += " dark:" + class
+	}
+	return base
+}
+`, name)
+}
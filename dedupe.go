@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// repeatGroup is a set of structurally identical sibling subtrees, such as
+// the cards of a grid, converted through one helper called once per subtree
+type repeatGroup struct {
+	name      string
+	instances []*html.Node
+	// slots are the texts and attribute values that differ between the
+	// instances, which become parameters of the helper
+	slots []repeatSlot
+	decl  *funcDecl
+}
+
+// repeatSlot is a text or attribute value that differs between the
+// instances of a group, holding the node carrying it in every instance
+type repeatSlot struct {
+	param string
+	// key is the attribute the slot is the value of, "" for a text node
+	key   string
+	nodes []*html.Node
+}
+
+// repeatValue is a text or attribute value of a subtree, in document order
+type repeatValue struct {
+	node *html.Node
+	key  string
+	val  string
+}
+
+// findRepeats groups the structurally identical sibling subtrees that occur
+// at least Options.Dedupe times. Subtrees only differing in texts and plain
+// attribute values are identical; the differences become parameters. Groups
+// are not searched for inside the subtrees of another group, and groups of
+// the same shape under different parents share one helper.
+func (c *Converter) findRepeats(nodes []*html.Node) {
+	c.repeats = make(map[*html.Node]*repeatGroup)
+	if c.opts.Dedupe < 2 {
+		return
+	}
+
+	byShape := make(map[string][]*repeatGroup)
+	var shapeOrder []string
+	var walk func(children []*html.Node)
+	walk = func(children []*html.Node) {
+		shapes := make(map[string][]*html.Node)
+		var order []string
+		for _, n := range children {
+			if n.Type != html.ElementNode {
+				continue
+			}
+			shape, ok := c.repeatShape(n)
+			if !ok || strings.Count(shape, "<")-strings.Count(shape, "</>") < 2 {
+				// Single elements gain nothing from a helper
+				continue
+			}
+			if _, seen := shapes[shape]; !seen {
+				order = append(order, shape)
+			}
+			shapes[shape] = append(shapes[shape], n)
+		}
+
+		grouped := make(map[*html.Node]bool)
+		for _, shape := range order {
+			instances := shapes[shape]
+			if len(instances) < c.opts.Dedupe {
+				continue
+			}
+			if group, ok := c.repeatGroup(instances); ok {
+				if len(byShape[shape]) == 0 {
+					shapeOrder = append(shapeOrder, shape)
+				}
+				byShape[shape] = append(byShape[shape], group)
+				for _, n := range instances {
+					grouped[n] = true
+				}
+			}
+		}
+		for _, n := range children {
+			if n.Type == html.ElementNode && !grouped[n] {
+				walk(childNodes(n))
+			}
+		}
+	}
+	walk(nodes)
+
+	for _, shape := range shapeOrder {
+		groups := byShape[shape]
+		if len(groups) > 1 {
+			// Values differing between the groups become parameters too,
+			// unless one cannot be passed
+			var all []*html.Node
+			for _, group := range groups {
+				all = append(all, group.instances...)
+			}
+			if merged, ok := c.repeatGroup(all); ok {
+				groups = []*repeatGroup{merged}
+			}
+		}
+		for _, group := range groups {
+			for _, n := range group.instances {
+				c.repeats[n] = group
+			}
+		}
+	}
+}
+
+// childNodes returns the children of a node
+func childNodes(n *html.Node) []*html.Node {
+	var children []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		children = append(children, child)
+	}
+	return children
+}
+
+// repeatShape describes the structure of a subtree: its tags, attribute
+// names and texts, but not their values. Subtrees holding comments, raw text
+// or nodes that other passes convert specially have no shape.
+func (c *Converter) repeatShape(n *html.Node) (string, bool) {
+	var b strings.Builder
+	var shape func(n *html.Node) bool
+	shape = func(n *html.Node) bool {
+		switch n.Type {
+		case html.TextNode:
+			if _, ok := c.nodeText(n); ok {
+				b.WriteString("#")
+			}
+			return true
+		case html.ElementNode:
+			if isRawText(n) || c.convertsSpecially(n) {
+				return false
+			}
+			keys := make([]string, len(n.Attr))
+			for i, attr := range n.Attr {
+				keys[i] = attr.Key
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(&b, "<%s %s>", n.Data, strings.Join(keys, " "))
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				if !shape(child) {
+					return false
+				}
+			}
+			b.WriteString("</>")
+			return true
+		}
+		return false
+	}
+	if !shape(n) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// convertsSpecially reports whether an element is converted by another pass
+// than the generic one, such as a patch, an icon call or a helper
+func (c *Converter) convertsSpecially(n *html.Node) bool {
+	_, patched := c.patches[n]
+	_, snippet := c.snippetCalls[n]
+	_, icon := c.iconCalls[n]
+	_, iconContent := c.iconContent[n]
+	_, accordion := c.accordions[n]
+	_, meta := c.metaCalls[n]
+	_, paginated := c.paginations[n]
+	_, breadcrumb := c.breadcrumbs[n]
+	_, component := c.componentNodes[n]
+	return patched || snippet || icon || iconContent || accordion || meta || paginated || breadcrumb || component ||
+		n == c.consentStub || c.hoistsSVG(n) || c.accordionMembers[n] || c.faviconMembers[n] ||
+		c.indicators[n] || c.csrfInputs[n]
+}
+
+// repeatValues lists the texts and attribute values of a subtree
+func (c *Converter) repeatValues(n *html.Node) []repeatValue {
+	var values []repeatValue
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if text, ok := c.nodeText(n); ok {
+				values = append(values, repeatValue{node: n, val: text})
+			}
+		case html.ElementNode:
+			attrs := append([]html.Attribute(nil), n.Attr...)
+			sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+			for _, attr := range attrs {
+				values = append(values, repeatValue{node: n, key: attr.Key, val: attr.Val})
+			}
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+	return values
+}
+
+// repeatGroup finds the slots of identically shaped subtrees, or fails when
+// they differ in a value that cannot be passed as a parameter
+func (c *Converter) repeatGroup(instances []*html.Node) (*repeatGroup, bool) {
+	values := make([][]repeatValue, len(instances))
+	for i, n := range instances {
+		values[i] = c.repeatValues(n)
+	}
+
+	group := &repeatGroup{name: c.repeatFuncName(instances[0]), instances: instances}
+	taken := make(map[string]bool)
+	for j, first := range values[0] {
+		same := true
+		for _, v := range values[1:] {
+			if v[j].val != first.val {
+				same = false
+			}
+		}
+		if same {
+			continue
+		}
+		if first.key != "" && !c.slotAttribute(first.key, first.node.Data) {
+			return nil, false
+		}
+
+		slot := repeatSlot{param: c.slotParam(first, taken), key: first.key}
+		for _, v := range values {
+			slot.nodes = append(slot.nodes, v[j].node)
+		}
+		group.slots = append(group.slots, slot)
+	}
+	return group, true
+}
+
+// slotAttribute reports whether the value of an attribute can be passed as a
+// parameter: whether its conversion uses the value as it is
+func (c *Converter) slotAttribute(key, tagName string) bool {
+	if funcName, ok := c.opts.Mappings.Attributes[tagName+"["+key+"]"]; ok {
+		return !strings.HasSuffix(funcName, "()")
+	}
+	if funcName, ok := c.opts.Mappings.Attributes[key]; ok {
+		return !strings.HasSuffix(funcName, "()")
+	}
+	switch {
+	case strings.HasPrefix(key, "hx-") && c.useHTMX:
+		return htmxAttributes[key] != "" && !htmxBooleans[key]
+	case c.useAlpine && (strings.HasPrefix(key, "x-") || strings.HasPrefix(key, "@") || strings.HasPrefix(key, ":")):
+		return false
+	case c.opts.RewriteHandlers && isEventHandlerAttr(key):
+		return false
+	case key == "class" && c.opts.DarkVariants, key == "rel", key == "nonce":
+		return false
+	case booleanAttributeFuncs[key] != "":
+		return false
+	}
+	_, typed := c.opts.DataAttributes[key]
+	return !typed
+}
+
+// slotParam names the parameter of a slot after the attribute, or for texts
+// after the first class or the tag of their element
+func (c *Converter) slotParam(v repeatValue, taken map[string]bool) string {
+	name := v.key
+	if name == "" {
+		parent := v.node.Parent
+		if classes := strings.Fields(attrValue(parent, "class")); len(classes) > 0 {
+			name = classes[0]
+		} else {
+			name = parent.Data + " text"
+		}
+	}
+	name = withExport(goIdentifier(name, false), false)
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// repeatFuncName names the helper of a group after the first class or the
+// tag of its subtrees; their ids usually differ
+func (c *Converter) repeatFuncName(n *html.Node) string {
+	name := n.Data
+	if classes := strings.Fields(attrValue(n, "class")); len(classes) > 0 {
+		name = classes[0]
+	}
+	name = withExport(goIdentifier(name, true), !c.opts.Unexported)
+	if plainName(name) {
+		name += "Item"
+	}
+	return name
+}
+
+// repeatCall converts an instance of a group into a call of its helper,
+// declaring the helper when converting the first instance
+func (c *Converter) repeatCall(group *repeatGroup, n *html.Node) string {
+	if group.decl == nil {
+		group.decl = &funcDecl{name: c.uniqueFuncName(group.name), result: "Node"}
+		c.funcs = append(c.funcs, group.decl)
+		for _, slot := range group.slots {
+			group.decl.addParam(slot.param, "string")
+		}
+
+		// The first instance is converted with its slots as parameters
+		c.slots = make(map[*html.Node]map[string]string)
+		for _, slot := range group.slots {
+			node := slot.nodes[0]
+			if c.slots[node] == nil {
+				c.slots[node] = make(map[string]string)
+			}
+			c.slots[node][slot.key] = slot.param
+		}
+		parent := c.scope
+		c.scope = group.decl
+		group.decl.body = c.convertElement(group.instances[0], 1)
+		c.scope = parent
+		c.slots = nil
+	}
+
+	index := 0
+	for i, instance := range group.instances {
+		if instance == n {
+			index = i
+		}
+	}
+	args := make([]string, len(group.decl.params))
+	for i, p := range group.decl.params {
+		if i >= len(group.slots) {
+			// Parameters of the page the helper needs are passed on
+			args[i] = p.name
+			if c.scope != nil {
+				c.scope.addParam(p.name, p.typ)
+			}
+			continue
+		}
+		slot := group.slots[i]
+		node := slot.nodes[index]
+		if slot.key == "" {
+			text, _ := c.nodeText(node)
+			args[i] = c.textValue(node, text)
+		} else {
+			args[i] = c.attrValue(slot.key, attrValue(node, slot.key))
+		}
+	}
+	return fmt.Sprintf("%s(%s)", group.decl.name, strings.Join(args, ", "))
+}
+
+// slotText returns the code of a text node that is a slot of the helper being declared
+func (c *Converter) slotText(n *html.Node) (string, bool) {
+	param, ok := c.slots[n][""]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("T(%s)", param), true
+}
+
+// slotAttr returns the code of an attribute that is a slot of the helper
+// being declared: its conversion with the parameter in place of the value
+func (c *Converter) slotAttr(n *html.Node, attr html.Attribute) (string, bool) {
+	param, ok := c.slots[n][attr.Key]
+	if !ok {
+		return "", false
+	}
+	const placeholder = "plainkit-slot"
+	code := c.convertAttribute(html.Attribute{Key: attr.Key, Val: placeholder}, n.Data)
+	return strings.Replace(code, c.quoteValue(placeholder), param, 1), true
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// placeholderPattern matches ${NAME} placeholders in text and attribute values
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// parseDefine parses a NAME=value definition
+func parseDefine(def string) (string, string, error) {
+	name, value, ok := strings.Cut(def, "=")
+	if !ok || !placeholderPattern.MatchString("${"+name+"}") {
+		return "", "", fmt.Errorf("invalid define %q: expected NAME=value", def)
+	}
+	return name, value, nil
+}
+
+// isRawText reports whether an element holds script or style source, which
+// may use ${...} for its own template literals
+func isRawText(n *html.Node) bool {
+	return n != nil && n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style")
+}
+
+// isScriptAttr reports whether an attribute holds script, such as inline
+// event handlers and Alpine and hx-on directives, whose ${...} are template
+// literal substitutions rather than placeholders
+func isScriptAttr(key string) bool {
+	return isEventHandlerAttr(key) || isAlpineAttr(key) || strings.HasPrefix(key, "hx-on")
+}
+
+// placeholderIdent returns the Go identifier for a placeholder name
+func placeholderIdent(name string) string {
+	return goIdentifier(strings.ToLower(name), false)
+}
+
+// resolveDefines substitutes defined placeholders, or references them through
+// constants when Options.DefineConsts is set, and reports placeholders without
+// a definition, which become string parameters
+func (c *Converter) resolveDefines(nodes []*html.Node) {
+	c.defineConsts = nil
+	if len(c.opts.Defines) == 0 {
+		return
+	}
+
+	used := make(map[string]bool)
+	undefined := make(map[string]bool)
+	resolve := func(n *html.Node, val string) string {
+		return placeholderPattern.ReplaceAllStringFunc(val, func(match string) string {
+			name := match[2 : len(match)-1]
+			value, ok := c.opts.Defines[name]
+			if !ok {
+				if !undefined[name] {
+					undefined[name] = true
+					c.report(n, SeverityInfo, "undefined-placeholder", "${%s} has no definition; it becomes the %s parameter", name, placeholderIdent(name))
+				}
+				return match
+			}
+			if c.opts.DefineConsts {
+				if !used[name] {
+					used[name] = true
+					c.defineConsts = append(c.defineConsts, constDecl{name: placeholderIdent(name), value: value})
+				}
+				return match
+			}
+			return value
+		})
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if !isRawText(n.Parent) {
+				n.Data = resolve(n.Parent, n.Data)
+			}
+		case html.ElementNode:
+			for i := range n.Attr {
+				if !isScriptAttr(n.Attr[i].Key) {
+					n.Attr[i].Val = resolve(n, n.Attr[i].Val)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+// placeholderExpr returns a string concatenation for an attribute value with
+// unresolved placeholders, referencing their constants or parameters
+func (c *Converter) placeholderExpr(key, val string) (string, bool) {
+	if len(c.opts.Defines) == 0 || isScriptAttr(key) || !placeholderPattern.MatchString(val) {
+		return "", false
+	}
+
+	var parts []string
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(val, -1) {
+		if m[0] > last {
+			parts = append(parts, c.quoteValue(val[last:m[0]]))
+		}
+		name := val[m[2]:m[3]]
+		if _, ok := c.opts.Defines[name]; ok {
+			parts = append(parts, placeholderIdent(name))
+		} else {
+			parts = append(parts, c.param(placeholderIdent(name), "string"))
+		}
+		last = m[1]
+	}
+	if last < len(val) {
+		parts = append(parts, c.quoteValue(val[last:]))
+	}
+	return strings.Join(parts, " + "), true
+}
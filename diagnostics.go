@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Severity classifies a Diagnostic
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Diagnostic is a finding reported while converting HTML
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	// Node is a selector-like path to the element the finding refers to
+	Node string `json:"node,omitempty"`
+	// Line is the source line for findings reported before parsing
+	Line int `json:"line,omitempty"`
+}
+
+// String formats the diagnostic for terminal output
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s [%s]", d.Severity, d.Line, d.Message, d.Code)
+	}
+	if d.Node == "" {
+		return fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Code)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", d.Severity, d.Node, d.Message, d.Code)
+}
+
+// Diagnostics returns the findings reported by the last conversion
+func (c *Converter) Diagnostics() []Diagnostic {
+	return c.diagnostics
+}
+
+// report records a diagnostic about a node
+func (c *Converter) report(n *html.Node, severity Severity, code, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Node:     nodePath(n),
+	})
+}
+
+// reportLine records a diagnostic about a source line
+func (c *Converter) reportLine(line int, severity Severity, code, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     line,
+	})
+}
+
+// nodePath builds a selector-like path such as "body > div#main > p" for a node
+func nodePath(n *html.Node) string {
+	var parts []string
+	for current := n; current != nil; current = current.Parent {
+		if current.Type != html.ElementNode {
+			continue
+		}
+		part := current.Data
+		for _, attr := range current.Attr {
+			if attr.Key == "id" && attr.Val != "" {
+				part += "#" + attr.Val
+				break
+			}
+		}
+		parts = append([]string{part}, parts...)
+	}
+	return strings.Join(parts, " > ")
+}
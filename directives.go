@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// directivePrefix marks HTML comments that carry conversion hints
+const directivePrefix = "plainkit:"
+
+// directive is a conversion hint embedded in an HTML comment, such as
+// <!-- plainkit:func HeroSection -->. It applies to the next sibling node.
+type directive struct {
+	name string
+	arg  string
+}
+
+// parseDirective parses a comment node as a plainkit directive
+func parseDirective(n *html.Node) (directive, bool) {
+	if n.Type != html.CommentNode {
+		return directive{}, false
+	}
+	text := strings.TrimSpace(n.Data)
+	if !strings.HasPrefix(text, directivePrefix) {
+		return directive{}, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(text, directivePrefix))
+	if len(fields) == 0 {
+		return directive{}, false
+	}
+	return directive{name: fields[0], arg: strings.Join(fields[1:], " ")}, true
+}
+
+// isDirective reports whether a node is a plainkit directive comment
+func isDirective(n *html.Node) bool {
+	_, ok := parseDirective(n)
+	return ok
+}
+
+// convertChildren converts the children of a node, applying any directives found among them
+func (c *Converter) convertChildren(n *html.Node, depth int) []string {
+	var nodes []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		nodes = append(nodes, child)
+	}
+	return c.convertNodeList(nodes, depth)
+}
+
+// convertNodeList converts a list of sibling nodes, applying each directive to
+// the next node that produces code, and with CommentsCode writing comments
+// above it
+func (c *Converter) convertNodeList(nodes []*html.Node, depth int) []string {
+	var codes []string
+	var pending *directive
+	var comments []string
+
+	for _, n := range nodes {
+		if d, ok := parseDirective(n); ok {
+			if c.validateDirective(n, d) {
+				pending = &d
+			}
+			continue
+		}
+		if c.opts.Comments == CommentsCode && c.keepsComment(n) {
+			comments = append(comments, n.Data)
+			continue
+		}
+		if _, ok := c.nodeText(n); n.Type == html.TextNode && !ok {
+			continue
+		}
+
+		var code string
+		if pending != nil {
+			code = c.applyDirective(*pending, n, depth)
+			pending = nil
+		} else {
+			code = c.convertNode(n, depth)
+		}
+		if code != "" {
+			codes = append(codes, lineComments(comments, depth)+code)
+			comments = nil
+		}
+	}
+	if len(comments) > 0 {
+		if len(codes) > 0 {
+			// Nothing follows the comments, and a line comment would swallow
+			// the comma after the last call
+			codes[len(codes)-1] += " " + blockComment(comments)
+		} else {
+			c.report(nodes[len(nodes)-1], SeverityInfo, "comment-dropped", "comment without content around it is dropped")
+		}
+	}
+
+	if pending != nil {
+		c.report(nodes[len(nodes)-1], SeverityWarning, "directive-unused",
+			"directive %q is not followed by any content", directivePrefix+pending.name)
+	}
+	return codes
+}
+
+// validateDirective reports malformed directives and returns whether d can be applied
+func (c *Converter) validateDirective(n *html.Node, d directive) bool {
+	switch d.name {
+	case "skip":
+		return true
+	case "func", "param":
+		if d.arg == "" {
+			c.report(n, SeverityWarning, "directive-invalid", "directive %q requires a name", directivePrefix+d.name)
+			return false
+		}
+		return true
+	default:
+		c.report(n, SeverityWarning, "directive-unknown", "unknown directive %q", directivePrefix+d.name)
+		return false
+	}
+}
+
+// applyDirective converts n according to directive d
+func (c *Converter) applyDirective(d directive, n *html.Node, depth int) string {
+	switch d.name {
+	case "skip":
+		return ""
+	case "func":
+		return c.extractFunc(goIdentifier(d.arg, true), func() string {
+			return c.convertNode(n, 1)
+		})
+	case "param":
+		text := fmt.Sprintf("T(%s)", c.paramExpr(d.arg, "", textContent(n)))
+		if n.Type != html.ElementNode {
+			return text
+		}
+		return c.convertElementWithChildren(n, depth, []string{text})
+	}
+	return c.convertNode(n, depth)
+}
+
+// rootFuncDirective returns the function name requested by a directive placed
+// before the only content node of a fragment, and the nodes without it
+func rootFuncDirective(nodes []*html.Node) (string, []*html.Node) {
+	var content []*html.Node
+	for _, n := range nodes {
+		if !isDirective(n) {
+			content = append(content, n)
+		}
+	}
+	if len(content) != 1 {
+		return "", nodes
+	}
+
+	for i, n := range nodes {
+		d, ok := parseDirective(n)
+		if !ok || d.name != "func" || d.arg == "" {
+			continue
+		}
+		if i+1 < len(nodes) && nodes[i+1] == content[0] {
+			rest := append(append([]*html.Node{}, nodes[:i]...), nodes[i+1:]...)
+			return goIdentifier(d.arg, true), rest
+		}
+	}
+	return "", nodes
+}
+
+// firstContent returns the first node in a list that is not a directive
+func firstContent(nodes []*html.Node) *html.Node {
+	for _, n := range nodes {
+		if !isDirective(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+// countContent returns the number of nodes in a list that are not directives
+func countContent(nodes []*html.Node) int {
+	count := 0
+	for _, n := range nodes {
+		if !isDirective(n) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// html5Doctype is the doctype generated pages are assumed to be rendered with
+const html5Doctype = "<!DOCTYPE html>"
+
+// quirksPublicIDs are public identifier prefixes that put browsers in quirks mode
+var quirksPublicIDs = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer",
+	"-//netscape comm. corp.//dtd",
+	"-//o'reilly and associates//dtd html",
+	"-//softquad",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//w3c//dtd html 3",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html",
+	"-//webtechs//dtd mozilla html",
+}
+
+// transitionalPublicIDs are public identifier prefixes that give quirks mode
+// without a system identifier and limited quirks mode with one
+var transitionalPublicIDs = []string{"-//w3c//dtd html 4.01 frameset//", "-//w3c//dtd html 4.01 transitional//"}
+
+// limitedQuirksPublicIDs are public identifier prefixes that give limited quirks mode
+var limitedQuirksPublicIDs = []string{"-//w3c//dtd xhtml 1.0 frameset//", "-//w3c//dtd xhtml 1.0 transitional//"}
+
+// doctypeMode returns the rendering mode a doctype puts browsers in:
+// "quirks", "limited quirks" or "" for standards mode
+func doctypeMode(n *html.Node) string {
+	if n == nil || n.Data != "html" {
+		return "quirks"
+	}
+	public, system := strings.ToLower(attrValue(n, "public")), strings.ToLower(attrValue(n, "system"))
+	hasPrefix := func(prefixes []string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(public, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case public == "-/w3c/dtd html 4.0 transitional/en" || public == "html" ||
+		system == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" || hasPrefix(quirksPublicIDs):
+		return "quirks"
+	case hasPrefix(transitionalPublicIDs):
+		if !hasAttr(n, "system") {
+			return "quirks"
+		}
+		return "limited quirks"
+	case hasPrefix(limitedQuirksPublicIDs):
+		return "limited quirks"
+	}
+	return ""
+}
+
+// doctypeSource returns the doctype declaration of a doctype node
+func doctypeSource(n *html.Node) string {
+	decl := "<!DOCTYPE " + n.Data
+	if hasAttr(n, "public") {
+		decl += fmt.Sprintf(" PUBLIC %q", attrValue(n, "public"))
+		if hasAttr(n, "system") {
+			decl += fmt.Sprintf(" %q", attrValue(n, "system"))
+		}
+	} else if hasAttr(n, "system") {
+		decl += fmt.Sprintf(" SYSTEM %q", attrValue(n, "system"))
+	}
+	return decl + ">"
+}
+
+// checkDoctype records the doctype of a parsed document and reports missing
+// and legacy doctypes, which change how browsers render the page
+func (c *Converter) checkDoctype(doc *html.Node) {
+	var doctype *html.Node
+	for child := doc.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.DoctypeNode {
+			doctype = child
+		}
+	}
+	if doctype == nil {
+		c.report(nil, SeverityWarning, "doctype-missing", "the page has no doctype, so browsers render it in quirks mode; rendering Page with %s switches it to standards mode", html5Doctype)
+		return
+	}
+
+	decl := doctypeSource(doctype)
+	if c.doctype == "" {
+		// With several documents, the first one's doctype is kept
+		c.doctype = decl
+	}
+	if decl == html5Doctype {
+		return
+	}
+	if mode := doctypeMode(doctype); mode != "" {
+		c.report(nil, SeverityWarning, "doctype-quirks", "%s puts browsers in %s mode; the generated code keeps it in a Doctype constant, while rendering with %s changes the layout", decl, mode, html5Doctype)
+		return
+	}
+	c.report(nil, SeverityInfo, "doctype-legacy", "%s is a legacy doctype; it renders in standards mode like %s", decl, html5Doctype)
+}
+
+// writeDoctype declares the doctype of a page converted from a document with
+// a doctype other than HTML5, which the page function does not render
+func (c *Converter) writeDoctype(buf codeWriter) {
+	if c.doctype == "" || c.doctype == html5Doctype {
+		return
+	}
+	name := c.mainFunc.name + "Doctype"
+	fmt.Fprintf(buf, "// %s is the doctype of the source page; write it before %s to keep the\n// browser in the same rendering mode\n", name, c.mainFunc.name)
+	fmt.Fprintf(buf, "const %s = %s\n\n", name, c.quoteValue(c.doctype))
+}
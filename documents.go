@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// document is one of several full documents concatenated in an input
+type document struct {
+	src string
+	// start is the byte offset of the document in the input
+	start int
+}
+
+// splitDocuments splits input holding several documents at their doctypes.
+// Content before the first doctype belongs to the first document.
+func splitDocuments(src string) []document {
+	var starts []int
+	z := html.NewTokenizer(strings.NewReader(src))
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt == html.DoctypeToken {
+			starts = append(starts, offset)
+		}
+		offset += len(z.Raw())
+	}
+	if len(starts) < 2 {
+		return nil
+	}
+
+	starts[0] = 0
+	docs := make([]document, len(starts))
+	for i, start := range starts {
+		end := len(src)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		trimmed := strings.TrimLeftFunc(src[start:end], unicode.IsSpace)
+		docs[i] = document{src: strings.TrimRightFunc(trimmed, unicode.IsSpace), start: end - len(trimmed)}
+	}
+	return docs
+}
+
+// convertDocuments converts input holding several documents into one page
+// function each, named Page1, Page2 and so on after the main function name
+func (c *Converter) convertDocuments(src string, docs []document) error {
+	lineOffset := c.lineOffset
+	for i, doc := range docs {
+		c.source = doc.src
+		c.lineOffset = lineOffset + strings.Count(src[:doc.start], "\n")
+		err := c.convertDocument(doc.src, fmt.Sprintf("%s%d", c.mainFuncName("Page"), i+1), func(decl *funcDecl) {
+			if i == 0 {
+				c.mainFunc = decl
+				return
+			}
+			// Pages come before the helpers extracted from them
+			c.funcs = slices.Insert(c.funcs, i-1, decl)
+		})
+		if err != nil {
+			return fmt.Errorf("document %d: %w", i+1, err)
+		}
+	}
+	c.source = src
+	c.lineOffset = lineOffset
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Editor snippet formats
+const (
+	SnippetVSCode    = "vscode"
+	SnippetJetBrains = "jetbrains"
+)
+
+// validateSnippet checks the editor snippet format
+func validateSnippet(format string) error {
+	switch format {
+	case "", SnippetVSCode, SnippetJetBrains:
+		return nil
+	}
+	return fmt.Errorf("unsupported snippet format %q: use vscode or jetbrains", format)
+}
+
+// tabStop is a tab stop of a snippet: the name of the main function or
+// one of its parameters, at every place it occurs
+type tabStop struct {
+	name    string
+	offsets []int
+}
+
+// editorSource is the part of a generated file that a snippet inserts: the
+// declarations after the imports, with the tab stops found in them
+type editorSource struct {
+	code  string
+	stops []tabStop
+}
+
+// parseEditorSource finds the declarations of generated code and the places
+// where the main function, the first one declared, and its parameters are named
+func parseEditorSource(code string) (editorSource, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "generated.go", code, parser.ParseComments)
+	if err != nil {
+		return editorSource{}, fmt.Errorf("failed to parse generated code: %w", err)
+	}
+
+	var main *ast.FuncDecl
+	start := -1
+	for _, d := range f.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		if start < 0 {
+			start = fset.Position(d.Pos()).Offset
+			if gen, ok := d.(*ast.GenDecl); ok && gen.Doc != nil {
+				start = fset.Position(gen.Doc.Pos()).Offset
+			}
+		}
+		if fn, ok := d.(*ast.FuncDecl); ok && main == nil {
+			main = fn
+		}
+	}
+	if main == nil {
+		return editorSource{}, fmt.Errorf("generated code declares no function")
+	}
+
+	// The function and its parameters, in the order they are tabbed through
+	objects := []*ast.Object{main.Name.Obj}
+	for _, field := range main.Type.Params.List {
+		for _, name := range field.Names {
+			objects = append(objects, name.Obj)
+		}
+	}
+	stops := make([]tabStop, len(objects))
+	for i, obj := range objects {
+		stops[i].name = obj.Name
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || ident.Obj == nil {
+			return true
+		}
+		for i, obj := range objects {
+			if ident.Obj == obj {
+				stops[i].offsets = append(stops[i].offsets, fset.Position(ident.Pos()).Offset-start)
+			}
+		}
+		return true
+	})
+	return editorSource{code: strings.TrimRight(code[start:], "\n"), stops: stops}, nil
+}
+
+// render writes the code with every tab stop replaced by stop(i, name) and
+// the text between them passed through escape
+func (s editorSource) render(escape func(string) string, stop func(i int, name string) string) string {
+	type place struct{ offset, stop int }
+	var places []place
+	for i, st := range s.stops {
+		for _, offset := range st.offsets {
+			places = append(places, place{offset, i})
+		}
+	}
+	sort.Slice(places, func(i, j int) bool { return places[i].offset < places[j].offset })
+
+	var b strings.Builder
+	last := 0
+	for _, p := range places {
+		b.WriteString(escape(s.code[last:p.offset]))
+		b.WriteString(stop(p.stop, s.stops[p.stop].name))
+		last = p.offset + len(s.stops[p.stop].name)
+	}
+	b.WriteString(escape(s.code[last:]))
+	return b.String()
+}
+
+// vscodeSnippet is an entry of a VS Code snippets file
+type vscodeSnippet struct {
+	Prefix      string   `json:"prefix"`
+	Description string   `json:"description"`
+	Body        []string `json:"body"`
+}
+
+// jetbrainsTemplateSet is a JetBrains live template file
+type jetbrainsTemplateSet struct {
+	XMLName   xml.Name            `xml:"templateSet"`
+	Group     string              `xml:"group,attr"`
+	Templates []jetbrainsTemplate `xml:"template"`
+}
+
+// jetbrainsTemplate is a JetBrains live template
+type jetbrainsTemplate struct {
+	Name        string              `xml:"name,attr"`
+	Value       string              `xml:"value,attr"`
+	Description string              `xml:"description,attr"`
+	Reformat    bool                `xml:"toReformat,attr"`
+	Variables   []jetbrainsVariable `xml:"variable"`
+	Context     []jetbrainsOption   `xml:"context>option"`
+}
+
+// jetbrainsVariable is a variable of a live template
+type jetbrainsVariable struct {
+	Name         string `xml:"name,attr"`
+	Expression   string `xml:"expression,attr"`
+	DefaultValue string `xml:"defaultValue,attr"`
+	AlwaysStopAt bool   `xml:"alwaysStopAt,attr"`
+}
+
+// jetbrainsOption enables a live template in a context
+type jetbrainsOption struct {
+	Name  string `xml:"name,attr"`
+	Value bool   `xml:"value,attr"`
+}
+
+// editorSnippet turns generated code into an editor snippet file in the
+// given format, abbreviated by the name of the main function. The function
+// name and its parameters are tab stops, so they can be renamed on insertion.
+func editorSnippet(format, code, inputName string) (string, error) {
+	src, err := parseEditorSource(code)
+	if err != nil {
+		return "", err
+	}
+	name := src.stops[0].name
+	abbrev := goIdentifier(name, false)
+	description := fmt.Sprintf("Plain component %s converted from %s", name, inputName)
+
+	switch format {
+	case SnippetVSCode:
+		escape := strings.NewReplacer(`\`, `\\`, "$", `\$`).Replace
+		body := src.render(escape, func(i int, name string) string {
+			return fmt.Sprintf("${%d:%s}", i+1, name)
+		})
+		snippets := map[string]vscodeSnippet{
+			name: {Prefix: abbrev, Description: description, Body: strings.Split(body, "\n")},
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(snippets); err != nil {
+			return "", fmt.Errorf("failed to encode snippet: %w", err)
+		}
+		return buf.String(), nil
+
+	case SnippetJetBrains:
+		// The function is a variable of its own so that parameter names cannot clash with it
+		vars := make([]string, len(src.stops))
+		vars[0] = "FUNC"
+		for i := 1; i < len(vars); i++ {
+			vars[i] = src.stops[i].name
+		}
+		escape := strings.NewReplacer("$", "$$").Replace
+		value := src.render(escape, func(i int, _ string) string {
+			return "$" + vars[i] + "$"
+		})
+		tmpl := jetbrainsTemplate{
+			Name:        abbrev,
+			Value:       value,
+			Description: description,
+			Reformat:    true,
+			Context:     []jetbrainsOption{{Name: "GO", Value: true}},
+		}
+		for i, v := range vars {
+			tmpl.Variables = append(tmpl.Variables, jetbrainsVariable{
+				Name:         v,
+				DefaultValue: strconv.Quote(src.stops[i].name),
+				AlwaysStopAt: true,
+			})
+		}
+		data, err := xml.MarshalIndent(jetbrainsTemplateSet{Group: "plainkit", Templates: []jetbrainsTemplate{tmpl}}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode snippet: %w", err)
+		}
+		return string(data) + "\n", nil
+	}
+	return "", validateSnippet(format)
+}
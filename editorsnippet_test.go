@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEditorSnippet(t *testing.T) {
+	code := "package main\n\nimport (\n\t. \"github.com/plainkit/html\"\n)\n\nfunc Price(amount string) Node {\n\treturn P(T(\"$\"+amount), Span(Class(\"price\")))\n}\n"
+
+	out, err := editorSnippet(SnippetVSCode, code, "price.html")
+	if err != nil {
+		t.Fatalf("Snippet failed: %v", err)
+	}
+	var snippets map[string]vscodeSnippet
+	if err := json.Unmarshal([]byte(out), &snippets); err != nil {
+		t.Fatalf("Invalid snippet JSON: %v\n%s", err, out)
+	}
+	snippet, ok := snippets["Price"]
+	if !ok || snippet.Prefix != "price" {
+		t.Fatalf("Expected a Price snippet with prefix price, got %v", snippets)
+	}
+	body := strings.Join(snippet.Body, "\n")
+	expected := "func ${1:Price}(${2:amount} string) Node {\n\treturn P(T(\"\\$\"+${2:amount}), Span(Class(\"price\")))\n}"
+	if body != expected {
+		t.Errorf("Expected body:\n%s\ngot:\n%s", expected, body)
+	}
+
+	out, err = editorSnippet(SnippetJetBrains, code, "price.html")
+	if err != nil {
+		t.Fatalf("Snippet failed: %v", err)
+	}
+	for _, exp := range []string{
+		`<template name="price" value="func $FUNC$($amount$ string) Node {&#xA;&#x9;return P(T(&#34;$$&#34;+$amount$)`,
+		`<variable name="FUNC" expression="" defaultValue="&#34;Price&#34;" alwaysStopAt="true">`,
+		`<variable name="amount" expression="" defaultValue="&#34;amount&#34;" alwaysStopAt="true">`,
+		`<option name="GO" value="true">`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected live template to contain %q.\nOutput:\n%s", exp, out)
+		}
+	}
+
+	if err := validateSnippet("sublime"); err == nil {
+		t.Error("Expected an error for an unknown snippet format")
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+// elementFuncs maps every HTML element to its Plain constructor. Elements that
+// are not listed, such as custom elements, are built with Element(tag, ...).
+// title and label depend on their context and are resolved by
+// tagToFunctionWithContext. The style element is StyleEl, as Style sets the
+// style attribute, and the data element is DataEl, as Data sets a data-*
+// attribute.
+var elementFuncs = map[string]string{
+	// Document metadata
+	"html":  "Html",
+	"head":  "Head",
+	"base":  "Base",
+	"link":  "Link",
+	"meta":  "Meta",
+	"style": "StyleEl",
+
+	// Sections
+	"body":    "Body",
+	"article": "Article",
+	"section": "Section",
+	"nav":     "Nav",
+	"aside":   "Aside",
+	"h1":      "H1",
+	"h2":      "H2",
+	"h3":      "H3",
+	"h4":      "H4",
+	"h5":      "H5",
+	"h6":      "H6",
+	"hgroup":  "Hgroup",
+	"header":  "Header",
+	"footer":  "Footer",
+	"address": "Address",
+
+	// Grouping content
+	"p":          "P",
+	"hr":         "Hr",
+	"pre":        "Pre",
+	"blockquote": "Blockquote",
+	"ol":         "Ol",
+	"ul":         "Ul",
+	"menu":       "Menu",
+	"li":         "Li",
+	"dl":         "Dl",
+	"dt":         "Dt",
+	"dd":         "Dd",
+	"figure":     "Figure",
+	"figcaption": "Figcaption",
+	"main":       "Main",
+	"search":     "Search",
+	"div":        "Div",
+
+	// Text-level semantics
+	"a":      "A",
+	"em":     "Em",
+	"strong": "Strong",
+	"small":  "Small",
+	"s":      "S",
+	"cite":   "Cite",
+	"q":      "Q",
+	"dfn":    "Dfn",
+	"abbr":   "Abbr",
+	"ruby":   "Ruby",
+	"rt":     "Rt",
+	"rp":     "Rp",
+	"data":   "DataEl",
+	"time":   "Time",
+	"code":   "Code",
+	"var":    "Var",
+	"samp":   "Samp",
+	"kbd":    "Kbd",
+	"sub":    "Sub",
+	"sup":    "Sup",
+	"i":      "I",
+	"b":      "B",
+	"u":      "U",
+	"mark":   "Mark",
+	"bdi":    "Bdi",
+	"bdo":    "Bdo",
+	"span":   "Span",
+	"br":     "Br",
+	"wbr":    "Wbr",
+
+	// Edits
+	"ins": "Ins",
+	"del": "Del",
+
+	// Embedded content
+	"picture": "Picture",
+	"source":  "Source",
+	"img":     "Img",
+	"iframe":  "Iframe",
+	"embed":   "Embed",
+	"object":  "Object",
+	"video":   "Video",
+	"audio":   "Audio",
+	"track":   "Track",
+	"map":     "Map",
+	"area":    "Area",
+	"svg":     "Svg",
+	"math":    "Math",
+
+	// Tabular data
+	"table":    "Table",
+	"caption":  "Caption",
+	"colgroup": "ColGroup",
+	"col":      "Col",
+	"tbody":    "Tbody",
+	"thead":    "Thead",
+	"tfoot":    "Tfoot",
+	"tr":       "Tr",
+	"td":       "Td",
+	"th":       "Th",
+
+	// Forms
+	"form":     "Form",
+	"input":    "Input",
+	"button":   "Button",
+	"select":   "Select",
+	"datalist": "Datalist",
+	"optgroup": "OptGroup",
+	"option":   "Option",
+	"textarea": "Textarea",
+	"output":   "Output",
+	"progress": "Progress",
+	"meter":    "Meter",
+	"fieldset": "Fieldset",
+	"legend":   "Legend",
+
+	// Interactive elements
+	"details": "Details",
+	"summary": "Summary",
+	"dialog":  "Dialog",
+
+	// Scripting
+	"script":   "Script",
+	"noscript": "Noscript",
+	"template": "Template",
+	"slot":     "Slot",
+	"canvas":   "Canvas",
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// emailUnsupportedElements are elements that most email clients strip or fail to render
+var emailUnsupportedElements = map[string]string{
+	"script":   "scripts are removed by every major email client",
+	"form":     "forms are unsupported or disabled in most email clients",
+	"iframe":   "iframes are not rendered by email clients",
+	"video":    "video is only rendered by a few email clients",
+	"audio":    "audio is not rendered by email clients",
+	"svg":      "inline SVG is unsupported in Gmail and Outlook",
+	"canvas":   "canvas requires scripting, which email clients remove",
+	"object":   "embedded objects are not rendered by email clients",
+	"embed":    "embedded objects are not rendered by email clients",
+	"template": "template elements are not rendered by email clients",
+}
+
+// emailLayoutStyle matches CSS declarations that email clients render inconsistently
+var emailLayoutStyle = regexp.MustCompile(`(?i)(display\s*:\s*(flex|grid|inline-flex|inline-grid)|position\s*:|float\s*:)`)
+
+// checkEmail reports markup that does not survive email clients. Elements
+// styled by class only are reported once for the document, when it has a
+// <style> block their styles may come from.
+func (c *Converter) checkEmail(nodes []*html.Node) {
+	if !c.opts.Email {
+		return
+	}
+
+	var style *html.Node
+	classOnly := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			c.checkEmailElement(n)
+			if n.Data == "style" && style == nil {
+				style = n
+			}
+			if hasAttr(n, "class") && !hasAttr(n, "style") && n.Data != "html" && n.Data != "body" {
+				classOnly++
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	if style != nil && classOnly > 0 {
+		c.report(style, SeverityInfo, "email-class-only", "%d element(s) are styled by class only; the styles of <style> blocks may be stripped, so inline the critical ones", classOnly)
+	}
+}
+
+// checkEmailElement checks a single element against email-client constraints
+func (c *Converter) checkEmailElement(n *html.Node) {
+	if reason, ok := emailUnsupportedElements[n.Data]; ok {
+		c.report(n, SeverityWarning, "email-unsupported-element", "<%s>: %s", n.Data, reason)
+	}
+
+	switch n.Data {
+	case "link":
+		if strings.Contains(strings.ToLower(attrValue(n, "rel")), "stylesheet") {
+			c.report(n, SeverityWarning, "email-external-css", "external stylesheets are not loaded by email clients; inline the styles instead")
+		}
+	case "style":
+		c.report(n, SeverityInfo, "email-style-block", "<style> blocks are stripped by some email clients; keep critical styles inline")
+	case "img":
+		src := attrValue(n, "src")
+		if src != "" && !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") &&
+			!strings.HasPrefix(src, "cid:") && !strings.HasPrefix(src, "data:") {
+			c.report(n, SeverityWarning, "email-relative-url", "image source %q must be an absolute URL in email", src)
+		}
+		if !hasAttr(n, "width") || !hasAttr(n, "height") {
+			c.report(n, SeverityInfo, "email-image-size", "images without width and height attributes render at full size in Outlook")
+		}
+		if !hasAttr(n, "alt") {
+			c.report(n, SeverityWarning, "email-image-alt", "images are often blocked in email; add alt text")
+		}
+	case "a":
+		href := attrValue(n, "href")
+		if strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//") {
+			c.report(n, SeverityWarning, "email-relative-url", "link %q must be an absolute URL in email", href)
+		}
+	case "table":
+		if !hasAttr(n, "role") {
+			c.report(n, SeverityInfo, "email-table-role", `layout tables should declare role="presentation" for screen readers`)
+		}
+	}
+
+	for _, attr := range n.Attr {
+		switch {
+		case attr.Key == "style" && emailLayoutStyle.MatchString(attr.Val):
+			c.report(n, SeverityWarning, "email-layout", "%q is not supported by many email clients; use table-based layout", emailLayoutStyle.FindString(attr.Val))
+		case strings.HasPrefix(attr.Key, "hx-"), strings.HasPrefix(attr.Key, "x-"),
+			strings.HasPrefix(attr.Key, "@"), strings.HasPrefix(attr.Key, "on"):
+			c.report(n, SeverityWarning, "email-scripting", "%s requires scripting, which email clients remove", attr.Key)
+		}
+	}
+}
+
+// hasAttr reports whether an element has an attribute
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
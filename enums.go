@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// enumAttr describes an attribute with an enumerated value
+type enumAttr struct {
+	// tags limits the check to these elements; empty means every element
+	tags []string
+	// values are the allowed keywords, compared ASCII case-insensitively
+	values []string
+	// tokens marks space-separated keyword lists such as rel
+	tokens bool
+	// validate replaces values for attributes with a grammar of their own and
+	// returns a description of the problem, or ""
+	validate func(tag, val string) string
+	// code is the diagnostic code, "enum-invalid" by default
+	code string
+}
+
+var (
+	formMethods  = []string{"get", "post", "dialog"}
+	formEnctypes = []string{"application/x-www-form-urlencoded", "multipart/form-data", "text/plain"}
+	relTokens    = []string{
+		"alternate", "author", "bookmark", "canonical", "compression-dictionary", "dns-prefetch",
+		"expect", "external", "help", "icon", "license", "manifest", "me", "modulepreload",
+		"next", "nofollow", "noopener", "noreferrer", "opener", "pingback", "preconnect",
+		"prefetch", "preload", "prev", "privacy-policy", "search", "stylesheet", "tag",
+		"terms-of-service",
+		// Widely used outside the specification
+		"shortcut", "apple-touch-icon", "apple-touch-icon-precomposed", "apple-touch-startup-image",
+		"mask-icon", "sponsored", "ugc",
+	}
+)
+
+// enumAttrs lists the enumerated attributes by name. An attribute can have
+// several entries for different elements.
+var enumAttrs = map[string][]enumAttr{
+	"method":      {{tags: []string{"form"}, values: formMethods}},
+	"formmethod":  {{tags: []string{"button", "input"}, values: formMethods}},
+	"enctype":     {{tags: []string{"form"}, values: formEnctypes}},
+	"formenctype": {{tags: []string{"button", "input"}, values: formEnctypes}},
+	"target":      {{tags: []string{"a", "area", "base", "form"}, validate: validateTarget}},
+	"rel":         {{tags: []string{"a", "area", "link", "form"}, values: relTokens, tokens: true}},
+	"type": {
+		{tags: []string{"input"}, values: []string{
+			"button", "checkbox", "color", "date", "datetime-local", "email", "file", "hidden",
+			"image", "month", "number", "password", "radio", "range", "reset", "search",
+			"submit", "tel", "text", "time", "url", "week",
+		}},
+		{tags: []string{"button"}, values: []string{"submit", "reset", "button"}},
+	},
+	"loading":       {{tags: []string{"img", "iframe"}, values: []string{"eager", "lazy"}}},
+	"decoding":      {{tags: []string{"img"}, values: []string{"sync", "async", "auto"}}},
+	"fetchpriority": {{tags: []string{"img", "link", "script", "iframe"}, values: []string{"high", "low", "auto"}}},
+	"referrerpolicy": {{values: []string{
+		"", "no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+		"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url",
+	}}},
+	"crossorigin":     {{values: []string{"", "anonymous", "use-credentials"}}},
+	"wrap":            {{tags: []string{"textarea"}, values: []string{"soft", "hard"}}},
+	"dir":             {{values: []string{"ltr", "rtl", "auto"}}},
+	"draggable":       {{values: []string{"true", "false"}}},
+	"spellcheck":      {{values: []string{"", "true", "false"}}},
+	"contenteditable": {{values: []string{"", "true", "false", "plaintext-only"}}},
+	"translate":       {{values: []string{"", "yes", "no"}}},
+	"hidden":          {{values: []string{"", "hidden", "until-found"}}},
+	"popover":         {{values: []string{"", "auto", "manual", "hint"}}},
+	"autocapitalize":  {{values: []string{"off", "none", "on", "sentences", "words", "characters"}}},
+	"inputmode":       {{values: []string{"none", "text", "decimal", "numeric", "tel", "search", "email", "url"}}},
+	"enterkeyhint":    {{values: []string{"enter", "done", "go", "next", "previous", "search", "send"}}},
+	"preload":         {{tags: []string{"audio", "video"}, values: []string{"", "none", "metadata", "auto"}}},
+	"kind":            {{tags: []string{"track"}, values: []string{"subtitles", "captions", "descriptions", "chapters", "metadata"}}},
+	"scope":           {{tags: []string{"th"}, values: []string{"row", "col", "rowgroup", "colgroup"}}},
+	"shape":           {{tags: []string{"area"}, values: []string{"rect", "circle", "poly", "default"}}},
+	"autocomplete": {{
+		tags:     []string{"input", "select", "textarea", "form"},
+		validate: validateAutocomplete,
+		code:     "autocomplete-invalid",
+	}},
+}
+
+// validateTarget accepts the browsing context keywords and any name not starting with an underscore
+func validateTarget(tag, val string) string {
+	switch strings.ToLower(val) {
+	case "_blank", "_self", "_parent", "_top":
+		return ""
+	}
+	if strings.HasPrefix(val, "_") {
+		return "is not a browsing context keyword (_blank, _self, _parent, _top)"
+	}
+	return ""
+}
+
+// lookupEnumAttr returns the enumerated attribute entry that applies to an element
+func lookupEnumAttr(tag, key string) (enumAttr, bool) {
+	for _, entry := range enumAttrs[key] {
+		if len(entry.tags) == 0 || containsString(entry.tags, tag) {
+			return entry, true
+		}
+	}
+	return enumAttr{}, false
+}
+
+// check validates a value and returns a description of the problem, or "",
+// along with the value in canonical case
+func (e enumAttr) check(tag, val string) (string, string) {
+	if e.validate != nil {
+		return e.validate(tag, val), val
+	}
+
+	words := []string{val}
+	if e.tokens {
+		words = strings.Fields(val)
+	}
+	var invalid, canonical []string
+	for _, word := range words {
+		lower := strings.ToLower(strings.TrimSpace(word))
+		if !containsString(e.values, lower) {
+			invalid = append(invalid, word)
+		}
+		canonical = append(canonical, lower)
+	}
+	if len(invalid) == 0 {
+		return "", strings.Join(canonical, " ")
+	}
+	if e.tokens {
+		return fmt.Sprintf("has unknown tokens %s", strings.Join(invalid, " ")), val
+	}
+	return fmt.Sprintf("is not one of %s", strings.Join(quoteAll(e.values), ", ")), val
+}
+
+// quoteAll quotes every string of a list, showing the empty string as ""
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
+
+// checkEnums reports enumerated attributes with values browsers ignore and,
+// with Options.NormalizeEnums, lowercases values that only differ in case
+func (c *Converter) checkEnums(nodes []*html.Node) {
+	forEachElement(nodes, func(n *html.Node) {
+		for i, attr := range n.Attr {
+			entry, ok := lookupEnumAttr(n.Data, attr.Key)
+			if !ok || c.hasProps(attr.Val) {
+				// Props are only known at runtime
+				continue
+			}
+			problem, canonical := entry.check(n.Data, attr.Val)
+			if problem != "" {
+				code := entry.code
+				if code == "" {
+					code = "enum-invalid"
+				}
+				c.report(n, SeverityWarning, code, "%s=%q %s", attr.Key, attr.Val, problem)
+				continue
+			}
+			if c.opts.NormalizeEnums && canonical != attr.Val {
+				c.report(n, SeverityInfo, "enum-normalized", "normalized %s=%q to %q", attr.Key, attr.Val, canonical)
+				n.Attr[i].Val = canonical
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package main
+
+// eventHandlerFuncs maps inline event handler attributes to the Plain
+// functions taking their script with Options.Events
+var eventHandlerFuncs = map[string]string{
+	// Mouse and pointer
+	"onclick":              "OnClick",
+	"ondblclick":           "OnDblClick",
+	"onauxclick":           "OnAuxClick",
+	"oncontextmenu":        "OnContextMenu",
+	"onmousedown":          "OnMouseDown",
+	"onmouseup":            "OnMouseUp",
+	"onmouseenter":         "OnMouseEnter",
+	"onmouseleave":         "OnMouseLeave",
+	"onmousemove":          "OnMouseMove",
+	"onmouseover":          "OnMouseOver",
+	"onmouseout":           "OnMouseOut",
+	"onwheel":              "OnWheel",
+	"onpointerdown":        "OnPointerDown",
+	"onpointerup":          "OnPointerUp",
+	"onpointermove":        "OnPointerMove",
+	"onpointerenter":       "OnPointerEnter",
+	"onpointerleave":       "OnPointerLeave",
+	"onpointerover":        "OnPointerOver",
+	"onpointerout":         "OnPointerOut",
+	"onpointercancel":      "OnPointerCancel",
+	"ongotpointercapture":  "OnGotPointerCapture",
+	"onlostpointercapture": "OnLostPointerCapture",
+	"ontouchstart":         "OnTouchStart",
+	"ontouchend":           "OnTouchEnd",
+	"ontouchmove":          "OnTouchMove",
+	"ontouchcancel":        "OnTouchCancel",
+	// Keyboard and focus
+	"onkeydown":  "OnKeyDown",
+	"onkeyup":    "OnKeyUp",
+	"onkeypress": "OnKeyPress",
+	"onfocus":    "OnFocus",
+	"onblur":     "OnBlur",
+	"onfocusin":  "OnFocusIn",
+	"onfocusout": "OnFocusOut",
+	// Forms
+	"onchange":      "OnChange",
+	"oninput":       "OnInput",
+	"onbeforeinput": "OnBeforeInput",
+	"onsubmit":      "OnSubmit",
+	"onreset":       "OnReset",
+	"oninvalid":     "OnInvalid",
+	"onselect":      "OnSelect",
+	"onsearch":      "OnSearch",
+	"onformdata":    "OnFormData",
+	// Clipboard and drag and drop
+	"oncopy":      "OnCopy",
+	"oncut":       "OnCut",
+	"onpaste":     "OnPaste",
+	"ondrag":      "OnDrag",
+	"ondragstart": "OnDragStart",
+	"ondragend":   "OnDragEnd",
+	"ondragenter": "OnDragEnter",
+	"ondragleave": "OnDragLeave",
+	"ondragover":  "OnDragOver",
+	"ondrop":      "OnDrop",
+	// Loading, scrolling and window
+	"onload":             "OnLoad",
+	"onerror":            "OnError",
+	"onabort":            "OnAbort",
+	"onresize":           "OnResize",
+	"onscroll":           "OnScroll",
+	"onscrollend":        "OnScrollEnd",
+	"onbeforeunload":     "OnBeforeUnload",
+	"onunload":           "OnUnload",
+	"onpagehide":         "OnPageHide",
+	"onpageshow":         "OnPageShow",
+	"onhashchange":       "OnHashChange",
+	"onpopstate":         "OnPopState",
+	"onstorage":          "OnStorage",
+	"ononline":           "OnOnline",
+	"onoffline":          "OnOffline",
+	"onmessage":          "OnMessage",
+	"onbeforeprint":      "OnBeforePrint",
+	"onafterprint":       "OnAfterPrint",
+	"onvisibilitychange": "OnVisibilityChange",
+	// Media
+	"onplay":           "OnPlay",
+	"onplaying":        "OnPlaying",
+	"onpause":          "OnPause",
+	"onended":          "OnEnded",
+	"onvolumechange":   "OnVolumeChange",
+	"ontimeupdate":     "OnTimeUpdate",
+	"ondurationchange": "OnDurationChange",
+	"onratechange":     "OnRateChange",
+	"onseeking":        "OnSeeking",
+	"onseeked":         "OnSeeked",
+	"onwaiting":        "OnWaiting",
+	"onstalled":        "OnStalled",
+	"onsuspend":        "OnSuspend",
+	"onemptied":        "OnEmptied",
+	"oncanplay":        "OnCanPlay",
+	"oncanplaythrough": "OnCanPlayThrough",
+	"onloadstart":      "OnLoadStart",
+	"onloadeddata":     "OnLoadedData",
+	"onloadedmetadata": "OnLoadedMetadata",
+	"onprogress":       "OnProgress",
+	// Animations, transitions, dialogs and popovers
+	"onanimationstart":     "OnAnimationStart",
+	"onanimationend":       "OnAnimationEnd",
+	"onanimationiteration": "OnAnimationIteration",
+	"onanimationcancel":    "OnAnimationCancel",
+	"ontransitionstart":    "OnTransitionStart",
+	"ontransitionend":      "OnTransitionEnd",
+	"ontransitionrun":      "OnTransitionRun",
+	"ontransitioncancel":   "OnTransitionCancel",
+	"ontoggle":             "OnToggle",
+	"onbeforetoggle":       "OnBeforeToggle",
+	"onclose":              "OnClose",
+	"oncancel":             "OnCancel",
+	"onslotchange":         "OnSlotChange",
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// jsTokenKind classifies the tokens of a JavaScript expression
+type jsTokenKind int
+
+const (
+	jsNone jsTokenKind = iota
+	jsIdent
+	jsKeyword
+	jsNumber
+	jsString
+	jsTemplate
+	jsRegexp
+	jsPunct
+)
+
+// jsToken is a token of a JavaScript expression
+type jsToken struct {
+	kind jsTokenKind
+	text string
+	// newline tells whether a line break precedes the token
+	newline bool
+}
+
+// jsKeywords are the reserved and contextual words that do not stand for a
+// value. this, true, false, null and undefined do, and are identifiers here.
+var jsKeywords = map[string]bool{
+	"async": true, "await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "export": true, "extends": true, "finally": true, "for": true, "function": true,
+	"get": true, "if": true, "import": true, "in": true, "instanceof": true, "let": true,
+	"new": true, "of": true, "return": true, "set": true, "static": true, "switch": true,
+	"throw": true, "try": true, "typeof": true, "var": true, "void": true, "while": true,
+	"with": true, "yield": true,
+}
+
+// jsPunctuators are the punctuators of JavaScript, longest first
+var jsPunctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=", "*=",
+	"/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+	"{", "}", "(", ")", "[", "]", ";", ",", "<", ">", "+", "-", "*", "/", "%", "&", "|",
+	"^", "!", "~", "?", ":", "=", ".",
+}
+
+// jsBinaryOnly are the operators that need an operand on their left
+var jsBinaryOnly = map[string]bool{
+	"*": true, "/": true, "%": true, "**": true, "==": true, "!=": true, "===": true, "!==": true,
+	"<": true, ">": true, "<=": true, ">=": true, "&&": true, "||": true, "??": true, "=": true,
+	"+=": true, "-=": true, "*=": true, "/=": true, "%=": true, "**=": true, "&&=": true, "||=": true,
+	"??=": true, "<<=": true, ">>=": true, ">>>=": true, "&=": true, "|=": true, "^=": true,
+	"&": true, "|": true, "^": true, "<<": true, ">>": true, ">>>": true, "?": true, ":": true,
+	"=>": true, ".": true, "?.": true,
+}
+
+// jsChecker scans a JavaScript expression or statement list for the syntax
+// errors that can be told without a full parser: unterminated strings and
+// comments, unbalanced brackets, operators missing an operand and two values
+// on a line with nothing between them
+type jsChecker struct {
+	src string
+	pos int
+	// stack holds the open brackets, with ` for template substitutions
+	stack []byte
+	prev  jsToken
+}
+
+// checkJS reports the first syntax error of a JavaScript expression
+func checkJS(src string) error {
+	c := &jsChecker{src: src}
+	for {
+		tok, err := c.next()
+		if err != nil {
+			return err
+		}
+		if tok.kind == jsNone {
+			break
+		}
+		if err := c.check(tok); err != nil {
+			return err
+		}
+		c.prev = tok
+	}
+	if len(c.stack) > 0 {
+		open := c.stack[len(c.stack)-1]
+		if open == '`' {
+			return fmt.Errorf("unterminated template literal")
+		}
+		return fmt.Errorf("missing %q", closingBracket(open))
+	}
+	if c.dangling() {
+		return fmt.Errorf("expression ends with %q", c.prev.text)
+	}
+	return nil
+}
+
+// closingBracket returns the bracket closing open
+func closingBracket(open byte) string {
+	switch open {
+	case '(':
+		return ")"
+	case '[':
+		return "]"
+	}
+	return "}"
+}
+
+// operand reports whether a token stands for a value
+func (t jsToken) operand() bool {
+	switch t.kind {
+	case jsIdent, jsNumber, jsString, jsTemplate, jsRegexp:
+		return true
+	}
+	return false
+}
+
+// dangling reports whether the previous token is an operator waiting for an
+// operand
+func (c *jsChecker) dangling() bool {
+	if c.prev.kind != jsPunct {
+		return false
+	}
+	switch c.prev.text {
+	case ")", "]", "}", ";", "++", "--":
+		return false
+	}
+	return true
+}
+
+// check validates a token against the one before it
+func (c *jsChecker) check(tok jsToken) error {
+	prev := c.prev
+	unexpected := fmt.Errorf("unexpected %q at column %d", tok.text, c.column(tok))
+	if tok.kind == jsPunct {
+		switch tok.text {
+		case ")", "]", "}":
+			if c.dangling() && prev.text != "(" && prev.text != "[" && prev.text != "{" && prev.text != "," {
+				return unexpected
+			}
+			return nil
+		case ",":
+			// Only arrays have holes
+			hole := (prev.text == "[" || prev.text == ",") && len(c.stack) > 0 && c.stack[len(c.stack)-1] == '['
+			if prev.kind == jsNone || c.dangling() && !hole {
+				return unexpected
+			}
+			return nil
+		}
+		if jsBinaryOnly[tok.text] && (prev.kind == jsNone || c.dangling()) {
+			return unexpected
+		}
+		return nil
+	}
+	if tok.kind == jsTemplate && tok.text[0] == '}' && c.dangling() {
+		// A substitution ending without its value
+		return fmt.Errorf("unexpected \"}\" at column %d", c.column(tok))
+	}
+	// Values follow one another only across lines, where a semicolon is
+	// inserted, or after a keyword
+	if tok.kind != jsTemplate && tok.operand() && prev.operand() && prev.kind != jsTemplate && !tok.newline {
+		return unexpected
+	}
+	return nil
+}
+
+// column returns the 1-based column of a token just scanned
+func (c *jsChecker) column(tok jsToken) int {
+	return c.pos - len(tok.text) + 1
+}
+
+// regexpAllowed reports whether a slash starts a regular expression rather
+// than a division
+func (c *jsChecker) regexpAllowed() bool {
+	switch c.prev.kind {
+	case jsIdent, jsNumber, jsString, jsTemplate, jsRegexp:
+		return false
+	case jsPunct:
+		return c.prev.text != ")" && c.prev.text != "]" && c.prev.text != "}"
+	}
+	return true
+}
+
+// next scans the next token, returning a jsNone token at the end
+func (c *jsChecker) next() (jsToken, error) {
+	newline := false
+	for c.pos < len(c.src) {
+		ch := c.src[c.pos]
+		switch {
+		case ch == '\n' || ch == '\r':
+			newline = true
+			c.pos++
+		case ch == ' ' || ch == '\t' || ch == '\f' || ch == '\v':
+			c.pos++
+		case strings.HasPrefix(c.src[c.pos:], "//"):
+			if end := strings.IndexByte(c.src[c.pos:], '\n'); end >= 0 {
+				c.pos += end
+			} else {
+				c.pos = len(c.src)
+			}
+		case strings.HasPrefix(c.src[c.pos:], "/*"):
+			end := strings.Index(c.src[c.pos+2:], "*/")
+			if end < 0 {
+				return jsToken{}, fmt.Errorf("unterminated comment")
+			}
+			if strings.Contains(c.src[c.pos:c.pos+2+end], "\n") {
+				newline = true
+			}
+			c.pos += end + 4
+		default:
+			tok, err := c.scan()
+			tok.newline = newline
+			return tok, err
+		}
+	}
+	return jsToken{}, nil
+}
+
+// scan scans the token at the current position
+func (c *jsChecker) scan() (jsToken, error) {
+	start := c.pos
+	ch := c.src[c.pos]
+	r, size := utf8.DecodeRuneInString(c.src[c.pos:])
+	switch {
+	case ch == '"' || ch == '\'':
+		return c.scanString(ch)
+	case ch == '`':
+		c.pos++
+		return c.scanTemplate(start)
+	case ch == '}' && len(c.stack) > 0 && c.stack[len(c.stack)-1] == '`':
+		// End of a template substitution
+		c.stack = c.stack[:len(c.stack)-1]
+		c.pos++
+		return c.scanTemplate(start)
+	case ch >= '0' && ch <= '9' || ch == '.' && c.pos+1 < len(c.src) && c.src[c.pos+1] >= '0' && c.src[c.pos+1] <= '9':
+		for c.pos < len(c.src) && (isJSIdentByte(c.src[c.pos]) || c.src[c.pos] == '.') {
+			c.pos++
+		}
+		return jsToken{kind: jsNumber, text: c.src[start:c.pos]}, nil
+	case r == '$' || r == '_' || r == '#' || unicode.IsLetter(r):
+		c.pos += size
+		for c.pos < len(c.src) {
+			r, size := utf8.DecodeRuneInString(c.src[c.pos:])
+			if r != '$' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			c.pos += size
+		}
+		text := c.src[start:c.pos]
+		if jsKeywords[text] {
+			return jsToken{kind: jsKeyword, text: text}, nil
+		}
+		return jsToken{kind: jsIdent, text: text}, nil
+	case ch == '/' && c.regexpAllowed():
+		return c.scanRegexp()
+	}
+
+	for _, p := range jsPunctuators {
+		if strings.HasPrefix(c.src[c.pos:], p) {
+			c.pos += len(p)
+			return jsToken{kind: jsPunct, text: p}, c.bracket(p)
+		}
+	}
+	return jsToken{}, fmt.Errorf("unexpected character %q at column %d", r, c.pos+1)
+}
+
+// bracket tracks the brackets opened and closed by a punctuator
+func (c *jsChecker) bracket(p string) error {
+	switch p {
+	case "(", "[", "{":
+		c.stack = append(c.stack, p[0])
+	case ")", "]", "}":
+		if len(c.stack) == 0 {
+			return fmt.Errorf("unexpected %q at column %d", p, c.pos)
+		}
+		open := c.stack[len(c.stack)-1]
+		if closingBracket(open) != p || open == '`' {
+			return fmt.Errorf("unexpected %q at column %d, expected %q", p, c.pos, closingBracket(open))
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+	return nil
+}
+
+// isJSIdentByte reports whether b may continue an ASCII identifier or number
+func isJSIdentByte(b byte) bool {
+	return b == '_' || b == '$' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// scanString scans a string literal quoted with quote
+func (c *jsChecker) scanString(quote byte) (jsToken, error) {
+	start := c.pos
+	for c.pos++; c.pos < len(c.src); c.pos++ {
+		switch c.src[c.pos] {
+		case '\\':
+			c.pos++
+		case '\n':
+			return jsToken{}, fmt.Errorf("unterminated string at column %d", start+1)
+		case quote:
+			c.pos++
+			return jsToken{kind: jsString, text: c.src[start:c.pos]}, nil
+		}
+	}
+	return jsToken{}, fmt.Errorf("unterminated string at column %d", start+1)
+}
+
+// scanTemplate scans a template literal from after its opening backtick or
+// the end of a substitution. A substitution opens a bracket closed by the
+// } ending it.
+func (c *jsChecker) scanTemplate(start int) (jsToken, error) {
+	for ; c.pos < len(c.src); c.pos++ {
+		switch {
+		case c.src[c.pos] == '\\':
+			c.pos++
+		case c.src[c.pos] == '`':
+			c.pos++
+			return jsToken{kind: jsTemplate, text: c.src[start:c.pos]}, nil
+		case strings.HasPrefix(c.src[c.pos:], "${"):
+			c.pos += 2
+			c.stack = append(c.stack, '`')
+			return jsToken{kind: jsPunct, text: c.src[start:c.pos]}, nil
+		}
+	}
+	return jsToken{}, fmt.Errorf("unterminated template literal at column %d", start+1)
+}
+
+// scanRegexp scans a regular expression literal and its flags
+func (c *jsChecker) scanRegexp() (jsToken, error) {
+	start := c.pos
+	class := false
+	for c.pos++; c.pos < len(c.src); c.pos++ {
+		switch c.src[c.pos] {
+		case '\\':
+			c.pos++
+		case '\n':
+			c.pos = len(c.src)
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if class {
+				continue
+			}
+			for c.pos++; c.pos < len(c.src) && isJSIdentByte(c.src[c.pos]); c.pos++ {
+			}
+			return jsToken{kind: jsRegexp, text: c.src[start:c.pos]}, nil
+		}
+	}
+	return jsToken{}, fmt.Errorf("unterminated regular expression at column %d", start+1)
+}
+
+// xForPattern splits an x-for value into its item and its collection
+var xForPattern = regexp.MustCompile(`^\s*(.+?)\s+(?:in|of)\s+(.+)$`)
+
+// alpineExpression returns the JavaScript held by an Alpine attribute, with
+// false for attributes whose value is not script, such as x-ref or the
+// classes of x-transition
+func alpineExpression(key, val string) (string, bool) {
+	switch {
+	case strings.HasPrefix(key, "@"), strings.HasPrefix(key, "x-on:"),
+		strings.HasPrefix(key, ":"), strings.HasPrefix(key, "x-bind:"),
+		strings.HasPrefix(key, "x-model"), key == "x-mask:dynamic":
+		return val, true
+	}
+	switch key {
+	case "x-data", "x-init", "x-show", "x-if", "x-text", "x-html", "x-effect", "x-id", "x-modelable":
+		return val, true
+	}
+	return "", false
+}
+
+// checkExpressions reports the syntax errors of the scripts held by Alpine
+// and htmx attributes with Options.CheckExpressions: Alpine directives,
+// hx-on handlers and the JSON or js: values of hx-vals and hx-headers.
+// Values holding placeholders are skipped, as they are only complete once
+// filled in.
+func (c *Converter) checkExpressions(nodes []*html.Node) {
+	if !c.opts.CheckExpressions {
+		return
+	}
+	forEachElement(nodes, func(n *html.Node) {
+		for _, attr := range n.Attr {
+			if strings.Contains(attr.Val, "{{") {
+				continue
+			}
+			if err := expressionError(attr.Key, attr.Val); err != nil {
+				c.report(n, SeverityError, "expression-syntax", "%s: %v", attr.Key, err)
+			}
+		}
+	})
+}
+
+// expressionError checks the value of an attribute holding script or JSON
+func expressionError(key, val string) error {
+	if strings.TrimSpace(val) == "" {
+		return nil
+	}
+	switch {
+	case key == "x-for":
+		m := xForPattern.FindStringSubmatch(val)
+		if m == nil {
+			return fmt.Errorf(`expected "item in items", got %q`, val)
+		}
+		if err := checkJS(m[1]); err != nil {
+			return err
+		}
+		return checkJS(m[2])
+	case key == "hx-vals" || key == "hx-headers":
+		value := strings.TrimSpace(val)
+		script := false
+		for _, prefix := range []string{"js:", "javascript:"} {
+			if strings.HasPrefix(value, prefix) {
+				value, script = strings.TrimSpace(strings.TrimPrefix(value, prefix)), true
+			}
+		}
+		// htmx adds the braces of an object left out
+		if !strings.HasPrefix(value, "{") {
+			value = "{" + value + "}"
+		}
+		if script {
+			return checkJS("(" + value + ")")
+		}
+		var values map[string]any
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+		return nil
+	case strings.HasPrefix(key, "hx-on:"):
+		return checkJS(val)
+	}
+	if expr, ok := alpineExpression(key, val); ok {
+		return checkJS(expr)
+	}
+	return nil
+}
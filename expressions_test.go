@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckJS(t *testing.T) {
+	valid := []string{
+		`{ open: false, toggle() { this.open = !this.open } }`,
+		`open = !open; count++`,
+		`$dispatch('notify', { text: 'Saved' })`,
+		"`${count} item${count === 1 ? '' : 's'}`",
+		`items.filter(i => i.done).length > 0`,
+		`if (open) close()`,
+		"open = false\nfocus()",
+		`value.replace(/[a-z]+\/x/g, '')`,
+		`[, second] = pair`,
+		`fn(a, b,)`,
+		`async () => { await $nextTick(); $refs.input.focus() }`,
+		`typeof x === 'undefined' || x instanceof Date`,
+		`{ ...defaults, 'aria-label': label }`,
+		`// comment` + "\n" + `open = true /* block */`,
+	}
+	for _, src := range valid {
+		if err := checkJS(src); err != nil {
+			t.Errorf("checkJS(%q) = %v, expected no error", src, err)
+		}
+	}
+
+	invalid := map[string]string{
+		`{ open: false `:   `missing "}"`,
+		`toggle())`:        `unexpected ")"`,
+		`foo(]`:            `unexpected "]"`,
+		`'unterminated`:    "unterminated string",
+		"`a ${b + }`":      `unexpected "}"`,
+		"`a ${b}":          "unterminated template literal",
+		`open = `:          `ends with "="`,
+		`count + * 2`:      `unexpected "*"`,
+		`open close`:       `unexpected "close"`,
+		`{ a: 1,, }`:       `unexpected ","`,
+		`fn(, a)`:          `unexpected ","`,
+		`x = /abc`:         "unterminated regular expression",
+		`/* open`:          "unterminated comment",
+		`a @ b`:            "unexpected character",
+		`{ items: [1, 2 }`: `expected "]"`,
+		`=> 1`:             `unexpected "=>"`,
+	}
+	for src, want := range invalid {
+		err := checkJS(src)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("checkJS(%q) = %v, expected an error containing %q", src, err, want)
+		}
+	}
+}
+
+func TestConvertCheckExpressions(t *testing.T) {
+	input := `<div x-data="{ open: false">` +
+		`<template x-for="(item, i) in items"><span x-text="item.name"></span></template>` +
+		`<template x-for="item items"></template>` +
+		`<button @click="open = !open" :class="{ active: open }" x-transition:enter="ease-out duration-300">Toggle</button>` +
+		`<button hx-post="/save" hx-vals='{"id": 1}' hx-headers='{"X-Token": }'>Save</button>` +
+		`<button hx-post="/calc" hx-vals="js:{total: calc(}" hx-on:htmx:after-request="done()">Calc</button>` +
+		`<input x-model="{{field}}"></div>`
+
+	converter := NewConverterWithOptions(Options{CheckExpressions: true})
+	if _, err := converter.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	var got []string
+	for _, d := range converter.Diagnostics() {
+		if d.Code == "expression-syntax" {
+			if d.Severity != SeverityError {
+				t.Errorf("Expected an error, got %v", d)
+			}
+			got = append(got, d.Message)
+		}
+	}
+	want := []string{
+		`x-data: missing "}"`,
+		`x-for: expected "item in items", got "item items"`,
+		`hx-headers: invalid JSON`,
+		`hx-vals: unexpected "}"`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d expression errors, got %v", len(want), got)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("Expected error %d to start with %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	plain := NewConverterWithOptions(Options{})
+	if _, err := plain.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, d := range plain.Diagnostics() {
+		if d.Code == "expression-syntax" {
+			t.Errorf("Expected no expression check without CheckExpressions, got %v", d)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// faviconRels are the link relations of favicons and app manifests
+var faviconRels = map[string]bool{
+	"icon": true, "apple-touch-icon": true, "apple-touch-icon-precomposed": true,
+	"mask-icon": true, "manifest": true,
+}
+
+// faviconMetas are the meta names describing icons and app theming, mapped
+// to whether their content is a URL
+var faviconMetas = map[string]bool{
+	"theme-color": false, "msapplication-tilecolor": false, "msapplication-tileimage": true,
+	"msapplication-config": true, "apple-mobile-web-app-title": false, "application-name": false,
+}
+
+// isFaviconTag reports whether a head element belongs to the favicon cluster
+func isFaviconTag(n *html.Node) bool {
+	switch n.Data {
+	case "link":
+		for _, rel := range strings.Fields(strings.ToLower(attrValue(n, "rel"))) {
+			if faviconRels[rel] {
+				return true
+			}
+		}
+	case "meta":
+		_, ok := faviconMetas[strings.ToLower(attrValue(n, "name"))]
+		return ok
+	}
+	return false
+}
+
+// faviconURL returns the attribute of a favicon tag holding a URL
+func faviconURL(n *html.Node) (string, bool) {
+	if n.Data == "link" {
+		return "href", hasAttr(n, "href")
+	}
+	if faviconMetas[strings.ToLower(attrValue(n, "name"))] {
+		return "content", hasAttr(n, "content")
+	}
+	return "", false
+}
+
+// findFavicons groups the icon, manifest and theme-color tags of the head
+// so they are generated as a single Favicons helper
+func (c *Converter) findFavicons(nodes []*html.Node) {
+	c.favicons = nil
+	c.faviconMembers = make(map[*html.Node]bool)
+	c.faviconFunc = nil
+	if !c.opts.GroupFavicons {
+		return
+	}
+
+	var tags []*html.Node
+	forEachElement(nodes, func(n *html.Node) {
+		if n.Parent != nil && n.Parent.Data == "head" && isFaviconTag(n) && c.patches[n] == nil {
+			tags = append(tags, n)
+		}
+	})
+	if len(tags) < 2 {
+		return
+	}
+	c.favicons = tags
+	for _, n := range tags {
+		c.faviconMembers[n] = true
+	}
+
+	// The base path is the directory all icon URLs share
+	var urls []string
+	for _, n := range tags {
+		if key, ok := faviconURL(n); ok {
+			urls = append(urls, attrValue(n, key))
+		}
+	}
+	c.faviconBase = commonDir(urls)
+}
+
+// commonDir returns the longest directory, without its trailing slash, that all urls start with
+func commonDir(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	prefix := urls[0]
+	for _, u := range urls[1:] {
+		for !strings.HasPrefix(u, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		return prefix[:i]
+	}
+	return ""
+}
+
+// faviconsCall generates the Favicons helper and returns the call replacing the favicon tags
+func (c *Converter) faviconsCall() string {
+	if c.faviconFunc == nil {
+		decl := &funcDecl{name: c.uniqueFuncName("Favicons"), result: "Node"}
+		if c.faviconBase != "" {
+			decl.addParam("base", "string")
+		}
+		c.funcs = append(c.funcs, decl)
+		c.faviconFunc = decl
+
+		scope := c.scope
+		c.scope = decl
+		var codes []string
+		for _, n := range c.favicons {
+			codes = append(codes, c.convertElement(n, 2))
+		}
+		c.scope = scope
+		decl.body = listBody("Fragment(", ")", codes)
+	}
+	if c.faviconBase == "" {
+		return c.faviconFunc.name + "()"
+	}
+	return fmt.Sprintf("%s(%s)", c.faviconFunc.name, c.quoteValue(c.faviconBase))
+}
+
+// faviconAttribute converts a favicon URL into an expression on the base path parameter
+func (c *Converter) faviconAttribute(n *html.Node, attr html.Attribute) (string, bool) {
+	if !c.faviconMembers[n] || c.faviconBase == "" {
+		return "", false
+	}
+	if key, ok := faviconURL(n); !ok || key != attr.Key || !strings.HasPrefix(attr.Val, c.faviconBase) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(attr.Val, c.faviconBase)
+	attr.Val = patchParamPlaceholder
+	code := c.convertAttribute(attr, n.Data)
+	return strings.Replace(code, c.quoteValue(patchParamPlaceholder), "base + "+c.quoteValue(rest), 1), true
+}
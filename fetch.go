@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// fetchOptions configure how inputs given as URLs are requested
+type fetchOptions struct {
+	Timeout time.Duration
+	// Headers are sent with every request, as "Name: value"
+	Headers   []string
+	UserAgent string
+}
+
+// isURL reports whether an input argument is an HTTP(S) URL rather than a path
+func isURL(arg string) bool {
+	lower := strings.ToLower(arg)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// urlFilename returns the file name a fetched page is converted as, so that
+// it is named like a saved copy: the last segment of the URL path with an
+// .html extension, or index.html for the root
+func urlFilename(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "index.html"
+	}
+	base := path.Base(strings.TrimSuffix(u.Path, "/"))
+	if base == "" || base == "/" || base == "." {
+		base = "index"
+	}
+	if ext := path.Ext(base); batchExtensions[strings.ToLower(ext)] {
+		return base
+	}
+	return base + ".html"
+}
+
+// parseHeaders checks and splits the configured request headers
+func parseHeaders(headers []string) (http.Header, error) {
+	parsed := make(http.Header)
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: value\"", h)
+		}
+		parsed.Add(name, strings.TrimSpace(value))
+	}
+	return parsed, nil
+}
+
+// fetchURL requests a page and returns its body. Responses other than 2xx
+// are errors, as converting an error page is never what was meant.
+func fetchURL(ctx context.Context, rawURL string, opts fetchOptions) ([]byte, error) {
+	headers, err := parseHeaders(opts.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+	req.Header = headers
+	if opts.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	}
+
+	client := &http.Client{Timeout: opts.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing response of %s: %v\n", rawURL, err)
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.Write([]byte(r.Header.Get("User-Agent") + " " + r.Header.Get("Authorization")))
+		}
+	}))
+	defer server.Close()
+
+	body, err := fetchURL(context.Background(), server.URL+"/pricing", fetchOptions{
+		Headers:   []string{"Authorization: Bearer secret"},
+		UserAgent: "test-agent",
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if string(body) != "test-agent Bearer secret" {
+		t.Errorf("Expected the user agent and headers to be sent, got %q", body)
+	}
+
+	if _, err := fetchURL(context.Background(), server.URL+"/missing", fetchOptions{}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+	if _, err := fetchURL(context.Background(), server.URL+"/slow", fetchOptions{Timeout: 20 * time.Millisecond}); err == nil {
+		t.Error("Expected the request to time out")
+	}
+	if _, err := fetchURL(context.Background(), server.URL, fetchOptions{Headers: []string{"no colon"}}); err == nil {
+		t.Error("Expected an error for an invalid header")
+	}
+}
+
+func TestURLInputs(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com":                  "index.html",
+		"https://example.com/":                 "index.html",
+		"https://example.com/pricing?plan=pro": "pricing.html",
+		"https://example.com/docs/intro/":      "intro.html",
+		"http://example.com/about.htm":         "about.htm",
+	}
+	for url, expected := range tests {
+		if got := urlFilename(url); got != expected {
+			t.Errorf("urlFilename(%q) = %q, expected %q", url, got, expected)
+		}
+	}
+
+	inputs, expanded, err := expandInputs([]string{"https://example.com/pricing?plan=pro"})
+	if err != nil {
+		t.Fatalf("expandInputs failed: %v", err)
+	}
+	if expanded || len(inputs) != 1 || inputs[0].path != "https://example.com/pricing?plan=pro" || inputs[0].rel != "pricing.html" {
+		t.Errorf("Expected the URL to be a single input, got %v (expanded %v)", inputs, expanded)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// wrapperTags are the elements that carry no meaning without attributes
+var wrapperTags = map[string]bool{"div": true, "span": true}
+
+// wrappedElement returns the only element child of a wrapper without
+// attributes whose other children are whitespace
+func wrappedElement(n *html.Node) (*html.Node, bool) {
+	if !wrapperTags[n.Data] || len(n.Attr) > 0 {
+		return nil, false
+	}
+	var only *html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case child.Type == html.ElementNode && only == nil:
+			only = child
+		case child.Type == html.TextNode && strings.TrimSpace(child.Data) == "":
+		default:
+			return nil, false
+		}
+	}
+	return only, only != nil
+}
+
+// flattenWrappers replaces wrappers that have no attributes and a single
+// element child with that child, reporting each collapsed chain
+func (c *Converter) flattenWrappers(roots []*html.Node) {
+	if !c.opts.Flatten {
+		return
+	}
+	isRoot := make(map[*html.Node]bool, len(roots))
+	for _, n := range roots {
+		isRoot[n] = true
+	}
+
+	var wrappers []*html.Node
+	forEachElement(roots, func(n *html.Node) {
+		if _, ok := wrappedElement(n); ok && !isRoot[n] {
+			wrappers = append(wrappers, n)
+		}
+	})
+
+	// Outer wrappers come first, so the tags removed above an element
+	// carry over when the element is itself unwrapped
+	removed := make(map[*html.Node][]string)
+	var order []*html.Node
+	for _, w := range wrappers {
+		child, _ := wrappedElement(w)
+		w.RemoveChild(child)
+		w.Parent.InsertBefore(child, w)
+		w.Parent.RemoveChild(w)
+
+		removed[child] = append(removed[w], w.Data)
+		delete(removed, w)
+		order = append(order, child)
+	}
+
+	for _, n := range order {
+		tags, ok := removed[n]
+		if !ok {
+			continue
+		}
+		delete(removed, n)
+		c.report(n, SeverityInfo, "flattened", "removed %d wrapper element(s) without attributes: %s",
+			len(tags), strings.Join(tags, " > "))
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "go/format"
+
+// formatCode runs generated code through go/format unless NoFormat is set.
+// Code that does not parse, for example because a mapping override names a
+// function that is not valid Go, is returned as it is with a warning.
+func (c *Converter) formatCode(code string) string {
+	if c.opts.NoFormat {
+		return code
+	}
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		c.reportLine(0, SeverityWarning, "format-failed", "generated code could not be formatted: %v", err)
+		return code
+	}
+	return string(formatted)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// formModel is the Go struct generated for a form
+type formModel struct {
+	name   string
+	fields []*formField
+}
+
+// formField is a field of a form struct, bound to the controls sharing a name
+type formField struct {
+	key   string // submitted name
+	field string // Go field name
+	typ   string // string, bool, int, float64 or []string
+	// controls are the elements submitting the field
+	controls []*html.Node
+}
+
+// collectForms builds a struct for every form from the names and types of its controls
+func (c *Converter) collectForms(nodes []*html.Node) {
+	c.forms = nil
+	if !c.opts.FormStructs {
+		return
+	}
+	forEachElement(nodes, func(n *html.Node) {
+		if n.Data != "form" {
+			return
+		}
+		// The Bind helper reserves the name, keeping structs of several forms apart
+		model := &formModel{name: strings.TrimPrefix(c.uniqueFuncName("Bind"+formName(n)), "Bind")}
+		byKey := make(map[string]*formField)
+		fields := make(map[string]bool)
+		forEachElement([]*html.Node{n}, func(control *html.Node) {
+			key := attrValue(control, "name")
+			typ, ok := controlType(control)
+			// The CSRF middleware checks the token, not the handler
+			ok = ok && !c.csrfInputs[control]
+			if key == "" || !ok {
+				if key != "" && attrValue(control, "type") == "file" {
+					c.report(control, SeverityInfo, "form-file-field", "file input %q is not part of the %s struct; read it with r.FormFile", key, model.name)
+				}
+				return
+			}
+			if f, ok := byKey[key]; ok {
+				// Several checkboxes or a list name submit a list
+				if typ == "bool" || f.typ == "bool" || strings.HasSuffix(key, "[]") {
+					f.typ = "[]string"
+				}
+				f.controls = append(f.controls, control)
+				return
+			}
+			if strings.HasSuffix(key, "[]") {
+				typ = "[]string"
+			}
+			f := &formField{key: key, field: uniqueFieldName(fields, goIdentifier(key, true)), typ: typ, controls: []*html.Node{control}}
+			byKey[key] = f
+			model.fields = append(model.fields, f)
+		})
+		if len(model.fields) == 0 {
+			return
+		}
+		c.forms = append(c.forms, model)
+		c.funcs = append(c.funcs, &funcDecl{
+			name:   "Bind" + model.name,
+			params: []funcParam{{name: "r", typ: "*http.Request"}},
+			result: fmt.Sprintf("(%s, error)", model.name),
+			raw:    c.formDecls(model),
+		})
+	})
+}
+
+// uniqueFieldName numbers a field name taken by an earlier field, as
+// first-name and first_name both become FirstName
+func uniqueFieldName(taken map[string]bool, name string) string {
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// formName derives the struct name of a form from its id, name or action.
+// Forms named like nothing but a form, which would collide with the Form
+// element of Plain, get the name FormData.
+func formName(n *html.Node) string {
+	name := attrValue(n, "id")
+	if name == "" {
+		name = attrValue(n, "name")
+	}
+	if name == "" {
+		action := strings.Trim(strings.SplitN(attrValue(n, "action"), "?", 2)[0], "/")
+		name = action[strings.LastIndex(action, "/")+1:]
+	}
+	name = goIdentifier(name, true)
+	if name == "V" {
+		name = ""
+	}
+	name = strings.TrimSuffix(name, "Form") + "Form"
+	if plainName(name) {
+		name += "Data"
+	}
+	return name
+}
+
+// controlType returns the Go type of the value a control submits
+func controlType(n *html.Node) (string, bool) {
+	switch n.Data {
+	case "textarea":
+		return "string", true
+	case "select":
+		if hasAttr(n, "multiple") {
+			return "[]string", true
+		}
+		return "string", true
+	case "input":
+		switch strings.ToLower(attrValue(n, "type")) {
+		case "submit", "button", "reset", "image", "file":
+			return "", false
+		case "checkbox":
+			return "bool", true
+		case "number", "range":
+			for _, key := range []string{"step", "min", "max"} {
+				if strings.Contains(attrValue(n, key), ".") {
+					return "float64", true
+				}
+			}
+			return "int", true
+		}
+		return "string", true
+	}
+	return "", false
+}
+
+// formDecls returns the struct of a form and the helper binding it from a request
+func (c *Converter) formDecls(model *formModel) string {
+	c.stdImports["net/http"] = true
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// %s holds the values submitted by the form\ntype %s struct {\n", model.name, model.name)
+	fieldWidth, typeWidth := 0, 0
+	for _, f := range model.fields {
+		fieldWidth = max(fieldWidth, len(f.field))
+		typeWidth = max(typeWidth, len(f.typ))
+	}
+	for _, f := range model.fields {
+		tags := c.fieldTags(f)
+		if strings.Contains(tags, "`") {
+			tags = strconv.Quote(tags)
+		} else {
+			tags = "`" + tags + "`"
+		}
+		fmt.Fprintf(&buf, "\t%-*s %-*s %s\n", fieldWidth, f.field, typeWidth, f.typ, tags)
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "// Bind%[1]s reads a %[1]s from a submitted request\n", model.name)
+	fmt.Fprintf(&buf, "func Bind%[1]s(r *http.Request) (%[1]s, error) {\n\tvar form %[1]s\n", model.name)
+	buf.WriteString("\tif err := r.ParseForm(); err != nil {\n\t\treturn form, err\n\t}\n")
+	for _, f := range model.fields {
+		key := c.quoteValue(f.key)
+		switch f.typ {
+		case "string":
+			fmt.Fprintf(&buf, "\tform.%s = r.Form.Get(%s)\n", f.field, key)
+		case "[]string":
+			fmt.Fprintf(&buf, "\tform.%s = r.Form[%s]\n", f.field, key)
+		case "bool":
+			fmt.Fprintf(&buf, "\tform.%s = r.Form.Get(%s) != \"\"\n", f.field, key)
+		case "int", "float64":
+			c.stdImports["fmt"] = true
+			c.stdImports["strconv"] = true
+			parse := "strconv.Atoi(v)"
+			if f.typ == "float64" {
+				parse = "strconv.ParseFloat(v, 64)"
+			}
+			fmt.Fprintf(&buf, "\tif v := r.Form.Get(%s); v != \"\" {\n", key)
+			fmt.Fprintf(&buf, "\t\tvalue, err := %s\n", parse)
+			fmt.Fprintf(&buf, "\t\tif err != nil {\n\t\t\treturn form, fmt.Errorf(%s, err)\n\t\t}\n", strconv.Quote(f.key+": %w"))
+			fmt.Fprintf(&buf, "\t\tform.%s = value\n\t}\n", f.field)
+		}
+	}
+	buf.WriteString("\treturn form, nil\n}\n")
+	return buf.String()
+}
+
+// fieldTags returns the struct tags of a form field, with validate rules
+// mirroring the validation attributes of its controls
+func (c *Converter) fieldTags(f *formField) string {
+	tags := fmt.Sprintf("form:%q", strings.TrimSuffix(f.key, "[]"))
+	rules, pattern := fieldRules(f)
+	if len(rules) > 0 {
+		tags += fmt.Sprintf(" validate:%q", strings.Join(rules, ","))
+	}
+	if pattern != "" {
+		// Validators have no regular expression rule, so the pattern gets its own tag
+		tags += fmt.Sprintf(" pattern:%q", "^(?:"+pattern+")$")
+	}
+	return tags
+}
+
+// fieldRules translates the validation attributes of a field's controls into
+// validate rules, returning the pattern the value has to match separately
+func fieldRules(f *formField) ([]string, string) {
+	var required bool
+	var rules []string
+	var pattern string
+	for _, n := range f.controls {
+		required = required || hasAttr(n, "required")
+	}
+	n := f.controls[0]
+	typ := strings.ToLower(attrValue(n, "type"))
+	switch f.typ {
+	case "string":
+		for _, rule := range []struct{ attr, name string }{{"minlength", "min"}, {"maxlength", "max"}} {
+			if v := attrValue(n, rule.attr); isInteger(v) {
+				rules = append(rules, rule.name+"="+v)
+			}
+		}
+		switch typ {
+		case "email", "url":
+			rules = append(rules, typ)
+		}
+		if n.Data == "input" {
+			pattern = attrValue(n, "pattern")
+		}
+	case "int", "float64":
+		for _, key := range []string{"min", "max"} {
+			if v := attrValue(n, key); v != "" {
+				if _, err := strconv.ParseFloat(v, 64); err == nil {
+					rules = append(rules, key+"="+v)
+				}
+			}
+		}
+	}
+
+	// Constraints do not apply to empty values unless the field is required
+	if required {
+		rules = append([]string{"required"}, rules...)
+	} else if len(rules) > 0 {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return rules, pattern
+}
+
+// isInteger reports whether s is a non-negative decimal integer
+func isInteger(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// funcDecl describes a generated Go function returning Plain nodes
+type funcDecl struct {
+	name   string
+	params []funcParam
+	result string
+	body   string
+	// raw holds the complete declarations of helpers that need more than a
+	// return statement
+	raw string
+}
+
+// funcParam is a parameter of a generated function
+type funcParam struct {
+	name string
+	typ  string
+}
+
+// FuncInfo describes a function generated by a conversion
+type FuncInfo struct {
+	Name string `json:"name"`
+	// Params are the parameter declarations, e.g. "title string"
+	Params []string `json:"params,omitempty"`
+	Result string   `json:"result"`
+}
+
+// Functions returns the functions generated by the last conversion, the main function first
+func (c *Converter) Functions() []FuncInfo {
+	if c.mainFunc == nil {
+		return nil
+	}
+	decls := append([]*funcDecl{c.mainFunc}, c.funcs...)
+	infos := make([]FuncInfo, len(decls))
+	for i, f := range decls {
+		infos[i] = f.info()
+	}
+	return infos
+}
+
+// info returns the exported description of the function
+func (f *funcDecl) info() FuncInfo {
+	info := FuncInfo{Name: f.name, Result: f.result}
+	for _, p := range f.params {
+		info.Params = append(info.Params, p.name+" "+p.typ)
+	}
+	return info
+}
+
+// addParam adds a parameter to the function, ignoring duplicates
+func (f *funcDecl) addParam(name, typ string) {
+	for _, p := range f.params {
+		if p.name == name {
+			return
+		}
+	}
+	f.params = append(f.params, funcParam{name: name, typ: typ})
+}
+
+// call returns the expression calling the function with its parameters
+func (f *funcDecl) call() string {
+	names := make([]string, len(f.params))
+	for i, p := range f.params {
+		names[i] = p.name
+	}
+	return fmt.Sprintf("%s(%s)", f.name, strings.Join(names, ", "))
+}
+
+// signature returns the parameter list, grouping consecutive parameters of the same type
+func (f *funcDecl) signature() string {
+	var groups []string
+	for i, p := range f.params {
+		if i+1 < len(f.params) && f.params[i+1].typ == p.typ {
+			groups = append(groups, p.name)
+			continue
+		}
+		groups = append(groups, p.name+" "+p.typ)
+	}
+	return strings.Join(groups, ", ")
+}
+
+// write renders the function declaration, with an editable region before the
+// return statement when editable is set
+func (f *funcDecl) write(buf codeWriter, editable bool) {
+	if f.raw != "" {
+		buf.WriteString(f.raw)
+		return
+	}
+	fmt.Fprintf(buf, "func %s(%s) %s {\n", f.name, f.signature(), f.result)
+	if editable {
+		writeRegion(buf, "\t", f.name)
+	}
+	buf.WriteString("\treturn ")
+	buf.WriteString(f.body)
+	buf.WriteString("\n}\n")
+}
+
+// extractFunc converts a node into its own helper function and returns the call
+// expression used in its place
+func (c *Converter) extractFunc(name string, convert func() string) string {
+	decl := &funcDecl{name: c.uniqueFuncName(name), result: "Node"}
+	c.funcs = append(c.funcs, decl)
+
+	parent := c.scope
+	c.scope = decl
+	decl.body = convert()
+	c.scope = parent
+
+	// The caller has to supply whatever the helper needs
+	if parent != nil {
+		for _, p := range decl.params {
+			parent.addParam(p.name, p.typ)
+		}
+	}
+	return decl.call()
+}
+
+// convertFragmentList converts the root nodes of multi-fragment input. With
+// FragmentFuncs every root element that does not already become a function
+// is extracted into one, so that fragments remain usable on their own.
+func (c *Converter) convertFragmentList(nodes []*html.Node) []string {
+	if !c.opts.FragmentFuncs {
+		return c.convertNodeList(nodes, 2)
+	}
+
+	var codes []string
+	var group []*html.Node
+	for _, n := range nodes {
+		group = append(group, n)
+		if isDirective(n) {
+			continue
+		}
+		if n.Type != html.ElementNode || len(group) > 1 || c.ownsFunc(n) {
+			codes = append(codes, c.convertNodeList(group, 2)...)
+			group = nil
+			continue
+		}
+		group = nil
+
+		decl := len(c.funcs)
+		call := c.extractFunc(c.elementFuncName(n, "Fragment"), func() string {
+			return c.convertNode(n, 1)
+		})
+		if c.funcs[decl].body == "" {
+			// Skipped content leaves nothing to wrap
+			c.funcs = append(c.funcs[:decl], c.funcs[decl+1:]...)
+			continue
+		}
+		codes = append(codes, call)
+	}
+	if len(group) > 0 {
+		codes = append(codes, c.convertNodeList(group, 2)...)
+	}
+	return codes
+}
+
+// ownsFunc reports whether an element is converted into a function of its
+// own or a call regardless of FragmentFuncs
+func (c *Converter) ownsFunc(n *html.Node) bool {
+	if patch, ok := c.patches[n]; ok && (patch.Func != "" || patch.Call != "") {
+		return true
+	}
+	return n == c.consentStub || c.hoistsSVG(n)
+}
+
+// elementFuncName names the function of an element after its id, its first
+// class or its tag. Names that Plain itself exports, such as Header or Nav,
+// get the suffix so as not to clash with the dot import.
+func (c *Converter) elementFuncName(n *html.Node, suffix string) string {
+	name := attrValue(n, "id")
+	if name == "" {
+		if classes := strings.Fields(attrValue(n, "class")); len(classes) > 0 {
+			name = classes[0]
+		}
+	}
+	if name == "" {
+		name = n.Data
+	}
+	name = withExport(goIdentifier(name, true), !c.opts.Unexported)
+	if plainName(name) {
+		name += suffix
+	}
+	return name
+}
+
+// plainName reports whether name is exported by the Plain html package
+func plainName(name string) bool {
+	switch name {
+	case "T", "Raw", "Node", "Fragment", "Element", "Custom", "Data", "Aria", "HeadTitle", "FormLabel":
+		return true
+	}
+	for _, funcs := range []map[string]string{elementFuncs, attributeFuncs, booleanAttributeFuncs, eventHandlerFuncs} {
+		for _, funcName := range funcs {
+			if funcName == name {
+				return true
+			}
+		}
+	}
+	for _, funcs := range elementAttributeFuncs {
+		for _, funcName := range funcs {
+			if funcName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// uniqueFuncName returns name, suffixed with a counter if it is already taken
+func (c *Converter) uniqueFuncName(name string) string {
+	taken := func(candidate string) bool {
+		if c.mainFunc != nil && c.mainFunc.name == candidate {
+			return true
+		}
+		for _, f := range c.funcs {
+			if f.name == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s%d", name, i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// writeFuncs renders the main function followed by any extracted helpers
+func (c *Converter) writeFuncs(buf codeWriter) {
+	c.mainFunc.write(buf, c.opts.EditableRegions)
+	for _, f := range c.funcs {
+		if c.isSeparateSVG(f) {
+			continue
+		}
+		buf.WriteString("\n")
+		f.write(buf, c.opts.EditableRegions)
+	}
+}
+
+// goIdentifier turns arbitrary text into a Go identifier, exported or not
+func goIdentifier(s string, exported bool) string {
+	var words []string
+	var word strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+			continue
+		}
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+
+	var ident strings.Builder
+	for i, w := range words {
+		runes := []rune(w)
+		if i == 0 && !exported {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		ident.WriteString(string(runes))
+	}
+
+	result := ident.String()
+	if result == "" {
+		result = "v"
+		if exported {
+			result = "V"
+		}
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		if exported {
+			result = "N" + result
+		} else {
+			result = "n" + result
+		}
+	}
+	if goKeywords[result] {
+		result += "_"
+	}
+	return result
+}
+
+// goKeywords are reserved words that cannot be used as identifiers
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// generatedHeader is the first line of files written with --generate, in the
+// form Go tools recognize as generated code
+const generatedHeader = "// Code generated by plainkit-converter; DO NOT EDIT."
+
+// generateSeed holds what besides the source decides the generated code: the
+// converter version, the flags set and the files they read. It is mixed into
+// the hash of every source with --generate.
+var generateSeed string
+
+// reportFlags are the flags that leave the generated code alone: they check
+// it or write reports and indexes next to it
+var reportFlags = map[string]bool{
+	"check": true, "semantic": true, "color": true, "timeout": true,
+	"baseline": true, "update-baseline": true, "sarif": true, "report": true,
+	"manifest": true, "registry": true, "catalog": true, "check-links": true,
+	"known-routes": true, "routes-manifest": true, "nav-graph": true, "alpine-report": true,
+}
+
+// newGenerateSeed builds the seed from the flags set on the command line and
+// the config, patch, mapping and theme files they name
+func newGenerateSeed(flags *pflag.FlagSet) (string, error) {
+	var b strings.Builder
+	b.WriteString("plainkit-converter " + version + "\n")
+	flags.Visit(func(f *pflag.Flag) {
+		if !reportFlags[f.Name] {
+			b.WriteString("--" + f.Name + "=" + f.Value.String() + "\n")
+		}
+	})
+	for _, name := range []string{configFile, patchFile, mappingsFile, themeFile} {
+		if name == "" {
+			continue
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		b.WriteString(name + "\n")
+		b.Write(content)
+	}
+	return b.String(), nil
+}
+
+// sourceHash hashes the content of a source along with the generate seed
+func sourceHash(content []byte) string {
+	h := sha256.New()
+	h.Write([]byte(generateSeed))
+	h.Write(content)
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// generatedSource names a source in the header of the file generated from it,
+// relative to the directory of that file as go:generate runs from there
+func generatedSource(inputName, outputPath string) string {
+	if isURL(inputName) || outputPath == "" {
+		return inputName
+	}
+	if rel, err := filepath.Rel(filepath.Dir(outputPath), inputName); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(inputName)
+}
+
+// withGeneratedHeader prefixes generated code with the header naming its
+// source and hash
+func withGeneratedHeader(goCode, source, hash string) string {
+	return fmt.Sprintf("%s\n// Source: %s\n// Hash: %s\n\n%s", generatedHeader, source, hash, goCode)
+}
+
+// generatedHash returns the hash recorded in the header of a file written
+// with --generate, empty when the file does not exist or has no header
+func generatedHash(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != generatedHeader {
+		return ""
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if hash, ok := strings.CutPrefix(line, "// Hash: "); ok {
+			return hash
+		}
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+	}
+	return ""
+}
+
+// emitGenerated writes the code generated from a source with --generate,
+// leaving the output file untouched when its hash shows it was generated
+// from the same source with the same flags
+func emitGenerated(inputName, outputPath string, content []byte, goCode string) error {
+	hash := sourceHash(content)
+	if !checkMode && generatedHash(outputPath) == hash {
+		fmt.Printf("✓ %s is up to date with %s\n", outputPath, inputName)
+		return nil
+	}
+	return emitOutput(inputName, outputPath, withGeneratedHeader(goCode, generatedSource(inputName, outputPath), hash))
+}
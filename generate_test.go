@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEmitGenerated(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "templates", "card.html")
+	output := filepath.Join(dir, "views", "card.go")
+	code := "package views\n\nfunc Card() {}\n"
+
+	if err := emitGenerated(input, output, []byte(`<div class="card"></div>`), code); err != nil {
+		t.Fatalf("Writing failed: %v", err)
+	}
+	written, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The pattern go tools use to recognize generated files
+	if !regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`).Match(written) {
+		t.Errorf("Expected a generated code header, got:\n%s", written)
+	}
+	if !strings.Contains(string(written), "// Source: ../templates/card.html\n") {
+		t.Errorf("Expected the source relative to the output, got:\n%s", written)
+	}
+	if !strings.HasSuffix(string(written), "\n\n"+code) {
+		t.Errorf("Expected the header to be apart from the package clause, got:\n%s", written)
+	}
+
+	// An unchanged source leaves the file alone
+	marked := strings.Replace(string(written), "func Card", "func Marked", 1)
+	if err := os.WriteFile(output, []byte(marked), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := emitGenerated(input, output, []byte(`<div class="card"></div>`), code); err != nil {
+		t.Fatalf("Writing failed: %v", err)
+	}
+	if kept, _ := os.ReadFile(output); string(kept) != marked {
+		t.Errorf("Expected the file to be left alone for an unchanged source")
+	}
+
+	// A changed source or flag rewrites it
+	defer func(seed string) { generateSeed = seed }(generateSeed)
+	for _, tt := range []struct{ seed, source string }{
+		{generateSeed, `<div class="card big"></div>`},
+		{"--htmx=true\n", `<div class="card"></div>`},
+	} {
+		if err := os.WriteFile(output, []byte(marked), 0644); err != nil {
+			t.Fatal(err)
+		}
+		generateSeed = tt.seed
+		if err := emitGenerated(input, output, []byte(tt.source), code); err != nil {
+			t.Fatalf("Writing failed: %v", err)
+		}
+		if rewritten, _ := os.ReadFile(output); string(rewritten) == marked {
+			t.Errorf("Expected the file to be rewritten for seed %q and source %s", tt.seed, tt.source)
+		}
+	}
+}
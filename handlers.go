@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// isEventHandlerAttr reports whether an attribute is an inline DOM event handler such as onclick
+func isEventHandlerAttr(key string) bool {
+	if len(key) <= 2 || !strings.HasPrefix(key, "on") {
+		return false
+	}
+	for _, r := range key[2:] {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	handlerNavigation  = regexp.MustCompile(`^(?:window\.)?location(?:\.href)?\s*=\s*['"]([^'"]+)['"];?$`)
+	handlerFetch       = regexp.MustCompile(`^fetch\(\s*['"]([^'"]+)['"]\s*\)`)
+	handlerReturnFalse = regexp.MustCompile(`;?\s*return\s+false;?\s*$`)
+	handlerPrevent     = regexp.MustCompile(`(?:event|e)\.preventDefault\(\);?\s*`)
+	handlerThis        = regexp.MustCompile(`\bthis\b`)
+	handlerEvent       = regexp.MustCompile(`\bevent\b`)
+)
+
+// alpineHandler translates an inline handler into an Alpine @ attribute key and expression
+func alpineHandler(key, val string) (string, string) {
+	event := strings.TrimPrefix(key, "on")
+	expr := strings.TrimSpace(val)
+
+	var modifiers []string
+	if handlerReturnFalse.MatchString(expr) || handlerPrevent.MatchString(expr) {
+		modifiers = append(modifiers, "prevent")
+		expr = handlerReturnFalse.ReplaceAllString(expr, "")
+		expr = handlerPrevent.ReplaceAllString(expr, "")
+	}
+	expr = handlerThis.ReplaceAllString(expr, "$$el")
+	expr = handlerEvent.ReplaceAllString(expr, "$$event")
+	expr = strings.TrimSuffix(strings.TrimSpace(expr), ";")
+
+	alpineKey := "@" + event
+	if len(modifiers) > 0 {
+		alpineKey += "." + strings.Join(modifiers, ".")
+	}
+	return alpineKey, expr
+}
+
+// handlerSuggestion describes the Alpine or htmx replacement for an inline handler
+func handlerSuggestion(key, val string) string {
+	expr := strings.TrimSpace(val)
+	if m := handlerNavigation.FindStringSubmatch(expr); m != nil {
+		return fmt.Sprintf(`use a link (href=%q) or hx-get=%q hx-push-url="true"`, m[1], m[1])
+	}
+	if m := handlerFetch.FindStringSubmatch(expr); m != nil {
+		return fmt.Sprintf(`use hx-get=%q with hx-trigger=%q`, m[1], strings.TrimPrefix(key, "on"))
+	}
+	alpineKey, alpineExpr := alpineHandler(key, val)
+	return fmt.Sprintf(`use Alpine %s=%q`, alpineKey, alpineExpr)
+}
+
+// inAlpineComponent reports whether n or one of its ancestors has x-data,
+// outside of which Alpine ignores directives
+func inAlpineComponent(n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n.Type == html.ElementNode && hasAttr(n, "x-data") {
+			return true
+		}
+	}
+	return false
+}
+
+// handlerScope returns the element that gets x-data for the handlers
+// rewritten on n: the top-level element of a fragment, or the body of a
+// document
+func handlerScope(n *html.Node, roots map[*html.Node]bool) *html.Node {
+	scope := n
+	for !roots[scope] && scope.Data != "body" && scope.Parent != nil && scope.Parent.Type == html.ElementNode {
+		scope = scope.Parent
+	}
+	return scope
+}
+
+// checkEventHandlers reports inline event handlers with suggested
+// replacements. Rewritten handlers outside an Alpine component get an empty
+// x-data on the top-level element, so that Alpine runs them.
+func (c *Converter) checkEventHandlers(nodes []*html.Node) {
+	if !c.opts.SuggestHandlers && !c.opts.RewriteHandlers {
+		return
+	}
+
+	roots := make(map[*html.Node]bool)
+	for _, n := range nodes {
+		roots[n] = true
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if !isEventHandlerAttr(attr.Key) {
+					continue
+				}
+				if c.opts.RewriteHandlers {
+					alpineKey, expr := alpineHandler(attr.Key, attr.Val)
+					c.report(n, SeverityInfo, "legacy-event-handler", "rewrote %s to Alpine %s=%q", attr.Key, alpineKey, expr)
+					if !inAlpineComponent(n) {
+						scope := handlerScope(n, roots)
+						scope.Attr = append([]html.Attribute{{Key: "x-data"}}, scope.Attr...)
+						c.report(scope, SeverityInfo, "alpine-scope", "added x-data so that Alpine runs the rewritten handlers")
+					}
+				} else {
+					c.report(n, SeverityWarning, "legacy-event-handler", "inline %s handler: %s", attr.Key, handlerSuggestion(attr.Key, attr.Val))
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// headingLevel returns the level of a heading element, or 0
+func headingLevel(n *html.Node) int {
+	if n.Type == html.ElementNode && len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
+		return int(n.Data[1] - '0')
+	}
+	return 0
+}
+
+// relevelHeadings shifts the headings of the input so the highest one is at
+// HeadingLevel, keeping their relative structure
+func (c *Converter) relevelHeadings(nodes []*html.Node) {
+	if c.opts.HeadingLevel == 0 {
+		return
+	}
+
+	var headings []*html.Node
+	top := 7
+	forEachElement(nodes, func(n *html.Node) {
+		if level := headingLevel(n); level > 0 {
+			headings = append(headings, n)
+			top = min(top, level)
+		}
+	})
+	shift := c.opts.HeadingLevel - top
+	if len(headings) == 0 || shift == 0 {
+		return
+	}
+
+	clamped := 0
+	for _, n := range headings {
+		level := headingLevel(n) + shift
+		if level > 6 {
+			level = 6
+			clamped++
+		}
+		n.Data = fmt.Sprintf("h%d", level)
+		n.DataAtom = atom.Lookup([]byte(n.Data))
+	}
+	c.report(nil, SeverityInfo, "heading-relevel", "shifted %d headings from h%d to start at h%d", len(headings), top, c.opts.HeadingLevel)
+	if clamped > 0 {
+		c.report(nil, SeverityWarning, "heading-relevel", "%d headings would be deeper than h6 and were clamped, merging outline levels", clamped)
+	}
+}
+
+// headingSlug derives an id from the text of a heading: lower case letters
+// and digits, with dashes for the runs of anything else
+func headingSlug(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// headingIDs gives the headings that have no id one derived from their text,
+// numbered when an element of the input already has it, and reports how
+// many it added
+func (c *Converter) headingIDs(nodes, headings []*html.Node) int {
+	taken := make(map[string]bool)
+	forEachElement(nodes, func(n *html.Node) {
+		if id := attrValue(n, "id"); id != "" {
+			taken[id] = true
+		}
+	})
+
+	added := 0
+	for _, n := range headings {
+		if attrValue(n, "id") != "" {
+			continue
+		}
+		slug := headingSlug(textContent(n))
+		id := slug
+		for i := 2; taken[id]; i++ {
+			id = fmt.Sprintf("%s-%d", slug, i)
+		}
+		taken[id] = true
+		setAttr(n, "id", id)
+		added++
+	}
+	return added
+}
+
+// addHeadingIDs gives every heading without an id one derived from its text
+// with Options.HeadingIDs, so that the page can be linked to by section, and
+// warns about ids several elements share, which break those links
+func (c *Converter) addHeadingIDs(nodes []*html.Node) {
+	if !c.opts.HeadingIDs {
+		return
+	}
+
+	var headings []*html.Node
+	first := make(map[string]*html.Node)
+	forEachElement(nodes, func(n *html.Node) {
+		if id := attrValue(n, "id"); id != "" {
+			if _, ok := first[id]; ok && headingLevel(n) > 0 {
+				c.report(n, SeverityWarning, "duplicate-id", "id %q is already used by an earlier element; links to it lead there", id)
+			}
+			first[id] = n
+		}
+		if headingLevel(n) > 0 && strings.TrimSpace(textContent(n)) != "" {
+			headings = append(headings, n)
+		}
+	})
+	if added := c.headingIDs(nodes, headings); added > 0 {
+		c.report(nil, SeverityInfo, "heading-id", "added ids to %d headings", added)
+	}
+}
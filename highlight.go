@@ -0,0 +1,87 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// ANSI escape sequences used to highlight generated code
+const (
+	ansiReset   = "\x1b[0m"
+	ansiKeyword = "\x1b[35m"
+	ansiString  = "\x1b[32m"
+	ansiNumber  = "\x1b[36m"
+	ansiComment = "\x1b[90m"
+	ansiCall    = "\x1b[34m"
+)
+
+// colorOutput reports whether code written to stdout is highlighted: only
+// when asked to and stdout is a terminal, so piped output stays plain
+func colorOutput(enabled bool) bool {
+	if !enabled || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// highlightGo wraps the keywords, literals, comments and called functions of
+// Go source in ANSI colors, leaving everything else as it is
+func highlightGo(code string) string {
+	type span struct {
+		offset int
+		tok    token.Token
+		lit    string
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("generated.go", -1, len(code))
+	var s scanner.Scanner
+	// Code that does not scan is highlighted as far as it goes
+	s.Init(file, []byte(code), nil, scanner.ScanComments)
+	var spans []span
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// Inserted by the scanner, not part of the source
+			continue
+		}
+		spans = append(spans, span{offset: file.Offset(pos), tok: tok, lit: lit})
+	}
+
+	var b strings.Builder
+	last := 0
+	for i, sp := range spans {
+		var color, text string
+		switch {
+		case sp.tok.IsKeyword():
+			color, text = ansiKeyword, sp.tok.String()
+		case sp.tok == token.STRING || sp.tok == token.CHAR:
+			color, text = ansiString, sp.lit
+		case sp.tok == token.INT || sp.tok == token.FLOAT || sp.tok == token.IMAG:
+			color, text = ansiNumber, sp.lit
+		case sp.tok == token.COMMENT:
+			color, text = ansiComment, sp.lit
+		case sp.tok == token.IDENT && i+1 < len(spans) && spans[i+1].tok == token.LPAREN:
+			color, text = ansiCall, sp.lit
+		default:
+			continue
+		}
+		if sp.offset < last || sp.offset+len(text) > len(code) || code[sp.offset:sp.offset+len(text)] != text {
+			// Literals such as raw strings with carriage returns differ from the source
+			continue
+		}
+		b.WriteString(code[last:sp.offset])
+		b.WriteString(color)
+		b.WriteString(text)
+		b.WriteString(ansiReset)
+		last = sp.offset + len(text)
+	}
+	b.WriteString(code[last:])
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHighlightGo(t *testing.T) {
+	code := "package main\n\n// Card is a card\nfunc Card() Node {\n\treturn Div(Class(\"card\"), Width(`16`), T(\"x\"))\n}\n"
+	highlighted := highlightGo(code)
+
+	expected := []string{
+		ansiKeyword + "package" + ansiReset,
+		ansiKeyword + "func" + ansiReset + " " + ansiCall + "Card" + ansiReset,
+		ansiComment + "// Card is a card" + ansiReset,
+		ansiString + `"card"` + ansiReset,
+		ansiString + "`16`" + ansiReset,
+		ansiCall + "Div" + ansiReset,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(highlighted, exp) {
+			t.Errorf("Expected highlighted code to contain %q.\nOutput:\n%q", exp, highlighted)
+		}
+	}
+	if strings.Contains(highlighted, ansiCall+"Node") {
+		t.Errorf("Expected the result type not to be highlighted as a call.\nOutput:\n%q", highlighted)
+	}
+
+	plain := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(highlighted, "")
+	if plain != code {
+		t.Errorf("Expected the code to be unchanged apart from colors.\nOutput:\n%s", plain)
+	}
+}
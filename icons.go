@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// iconDecl is an icon moved into the icons package
+type iconDecl struct {
+	name  string
+	nodes []*html.Node
+}
+
+// iconMinRepeats is how often identical inline SVG content has to appear to become an icon
+const iconMinRepeats = 2
+
+// extractIcons moves SVG sprite symbols, and inline SVG content repeated
+// across the page, into functions of the icons package. References through
+// <use href="#id"> become calls to the symbol's function.
+func (c *Converter) extractIcons(nodes []*html.Node) {
+	c.icons = nil
+	c.iconCalls = make(map[*html.Node]*iconDecl)
+	c.iconContent = make(map[*html.Node]*iconDecl)
+	if c.opts.IconsImport == "" {
+		return
+	}
+	if !containsString(c.opts.Imports, c.opts.IconsImport) {
+		c.opts.Imports = append(append([]string{}, c.opts.Imports...), c.opts.IconsImport)
+	}
+
+	// Sprite symbols, named from their id
+	symbols := make(map[string]*iconDecl)
+	viewBoxes := make(map[string]string)
+	var defined []*html.Node
+	forEachElement(nodes, func(n *html.Node) {
+		id := attrValue(n, "id")
+		if n.Data != "symbol" || id == "" {
+			return
+		}
+		icon := c.addIcon(strings.TrimPrefix(id, "icon-"), elementContent(n))
+		symbols[id] = icon
+		viewBoxes[id] = attrValue(n, "viewBox")
+		defined = append(defined, n)
+	})
+	forEachElement(nodes, func(n *html.Node) {
+		if n.Data != "use" {
+			return
+		}
+		id, ok := strings.CutPrefix(useHref(n), "#")
+		icon := symbols[id]
+		if !ok || icon == nil {
+			return
+		}
+		c.iconCalls[n] = icon
+		// The viewBox of the symbol moves to the referencing svg
+		if svg := n.Parent; svg != nil && svg.Data == "svg" && !hasAttr(svg, "viewBox") && viewBoxes[id] != "" {
+			svg.Attr = append(svg.Attr, html.Attribute{Key: "viewBox", Val: viewBoxes[id]})
+		}
+	})
+	for _, n := range defined {
+		removeEmptySprite(n)
+	}
+
+	// Inline SVG content repeated across the page
+	groups := make(map[string][]*html.Node)
+	var order []string
+	forEachElement(nodes, func(n *html.Node) {
+		if n.Data != "svg" || firstElementChild(n) == nil || containsUse(n) {
+			return
+		}
+		key := renderContent(n)
+		if groups[key] == nil {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], n)
+	})
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < iconMinRepeats {
+			continue
+		}
+		icon := c.addIcon(inlineIconName(group[0]), elementContent(group[0]))
+		for _, n := range group {
+			c.iconContent[n] = icon
+		}
+	}
+
+	if len(c.icons) > 0 {
+		names := make([]string, len(c.icons))
+		for i, icon := range c.icons {
+			names[i] = icon.name
+		}
+		c.report(nil, SeverityInfo, "icon-extracted", "moved %d icons into package %s: %s",
+			len(c.icons), importName(c.opts.IconsImport), strings.Join(names, ", "))
+	}
+}
+
+// addIcon registers an icon under a unique exported name
+func (c *Converter) addIcon(name string, nodes []*html.Node) *iconDecl {
+	base := goIdentifier(name, true)
+	name = base
+	for i := 2; ; i++ {
+		taken := false
+		for _, icon := range c.icons {
+			taken = taken || icon.name == name
+		}
+		if !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	icon := &iconDecl{name: name, nodes: nodes}
+	c.icons = append(c.icons, icon)
+	return icon
+}
+
+// iconCall returns the expression calling an icon function
+func (c *Converter) iconCall(icon *iconDecl) string {
+	code := importName(c.opts.IconsImport) + "." + icon.name + "()"
+	c.useQualifier(code)
+	return code
+}
+
+// useHref returns the reference of a use element. The parser turns
+// xlink:href into href in the xlink namespace, so both forms match.
+func useHref(n *html.Node) string {
+	for _, attr := range n.Attr {
+		if attr.Key == "href" {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// containsUse reports whether an element references a symbol
+func containsUse(n *html.Node) bool {
+	found := false
+	forEachElement([]*html.Node{n}, func(el *html.Node) {
+		found = found || el.Data == "use" || el.Data == "symbol"
+	})
+	return found
+}
+
+// elementContent returns the children of n that are not whitespace
+func elementContent(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode && strings.TrimSpace(child.Data) == "" {
+			continue
+		}
+		if child.Type == html.CommentNode {
+			continue
+		}
+		nodes = append(nodes, child)
+	}
+	return nodes
+}
+
+// renderContent renders the content of an element, identifying identical icons
+func renderContent(n *html.Node) string {
+	var buf bytes.Buffer
+	for _, child := range elementContent(n) {
+		_ = html.Render(&buf, child)
+	}
+	return buf.String()
+}
+
+// inlineIconName derives an icon name from an inline svg
+func inlineIconName(n *html.Node) string {
+	if name := attrValue(n, "data-icon"); name != "" {
+		return name
+	}
+	for _, class := range strings.Fields(attrValue(n, "class")) {
+		if name, ok := strings.CutPrefix(class, "icon-"); ok && name != "" {
+			return name
+		}
+	}
+	if label := attrValue(n, "aria-label"); label != "" {
+		return label
+	}
+	if title := childElement(n, "title"); title != nil && strings.TrimSpace(textContent(title)) != "" {
+		return textContent(title)
+	}
+	return "icon"
+}
+
+// removeEmptySprite removes a symbol, and the sprite elements left without content
+func removeEmptySprite(n *html.Node) {
+	for n != nil && n.Parent != nil {
+		parent := n.Parent
+		parent.RemoveChild(n)
+		if firstElementChild(parent) != nil || (parent.Data != "svg" && parent.Data != "defs") {
+			return
+		}
+		n = parent
+	}
+}
+
+// IconsCode returns the source of the icons package holding the icons of the
+// last conversion, or "" when there are none
+func (c *Converter) IconsCode() string {
+	if len(c.icons) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n\t. \"github.com/plainkit/html\"\n)\n", importName(c.opts.IconsImport))
+
+	// Icons take no parameters from the page they came from
+	scope := c.scope
+	c.scope = nil
+	for _, icon := range c.icons {
+		codes := c.convertNodeList(icon.nodes, 2)
+		body := "Fragment()"
+		switch len(codes) {
+		case 0:
+		case 1:
+			body = codes[0]
+		default:
+			body = listBody("Fragment(", ")", codes)
+		}
+		fmt.Fprintf(&buf, "\nfunc %s() Node {\n\treturn %s\n}\n", icon.name, body)
+	}
+	c.scope = scope
+	return c.formatCode(c.qualifyOutput(buf.String()))
+}
+
+// iconsImportPath returns the import path of the package in dir, using the
+// module path of the nearest go.mod above it
+func iconsImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			module := modulePath(string(data))
+			if module == "" {
+				return "", fmt.Errorf("%s has no module directive", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("no go.mod found above %s to derive the icons import path", dir)
+		}
+	}
+}
+
+// modulePath returns the module path declared in a go.mod file
+func modulePath(gomod string) string {
+	for _, line := range strings.Split(gomod, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// indicatorIDPlaceholder stands in for an indicator's id while its helper body is generated
+const indicatorIDPlaceholder = "\x00plainkit-indicator-id\x00"
+
+// findIndicators returns the loading indicators of a tree: elements with the
+// htmx-indicator class and elements targeted by hx-indicator selectors
+func findIndicators(nodes []*html.Node) []*html.Node {
+	ids := make(map[string]bool)
+	classes := map[string]bool{"htmx-indicator": true}
+	forEachElement(nodes, func(n *html.Node) {
+		for _, selector := range strings.Split(attrValue(n, "hx-indicator"), ",") {
+			selector = strings.TrimSpace(selector)
+			switch {
+			case strings.HasPrefix(selector, "#"):
+				ids[selector[1:]] = true
+			case strings.HasPrefix(selector, "."):
+				classes[selector[1:]] = true
+			}
+		}
+	})
+
+	var indicators []*html.Node
+	forEachElement(nodes, func(n *html.Node) {
+		if ids[attrValue(n, "id")] {
+			indicators = append(indicators, n)
+			return
+		}
+		for _, class := range strings.Fields(attrValue(n, "class")) {
+			if classes[class] {
+				indicators = append(indicators, n)
+				return
+			}
+		}
+	})
+	return indicators
+}
+
+// normalizeIndicators gives every loading indicator consistent live-region
+// attributes and marks it for conversion through a shared helper
+func (c *Converter) normalizeIndicators(nodes []*html.Node) {
+	c.indicators = make(map[*html.Node]bool)
+	c.indicatorFuncs = make(map[string]*funcDecl)
+	if !c.opts.NormalizeIndicators {
+		return
+	}
+
+	for _, n := range findIndicators(nodes) {
+		c.indicators[n] = true
+		var added []string
+		if !hasAttr(n, "role") {
+			n.Attr = append(n.Attr, html.Attribute{Key: "role", Val: "status"})
+			added = append(added, `role="status"`)
+		}
+		if !hasAttr(n, "aria-live") {
+			n.Attr = append(n.Attr, html.Attribute{Key: "aria-live", Val: "polite"})
+			added = append(added, `aria-live="polite"`)
+		}
+		if len(added) > 0 {
+			c.report(n, SeverityInfo, "indicator-aria-live", "added %s to loading indicator", strings.Join(added, " "))
+		}
+	}
+}
+
+// indicatorCall converts a loading indicator into a call to a shared helper.
+// Indicators with the same markup apart from their id share one helper.
+func (c *Converter) indicatorCall(n *html.Node) string {
+	idIndex := -1
+	for i, attr := range n.Attr {
+		if attr.Key == "id" {
+			idIndex = i
+		}
+	}
+
+	var id string
+	if idIndex >= 0 {
+		id = n.Attr[idIndex].Val
+		n.Attr[idIndex].Val = indicatorIDPlaceholder
+	}
+	parent := c.scope
+	c.scope = &funcDecl{}
+	body := c.convertElement(n, 1)
+	c.scope = parent
+	if idIndex >= 0 {
+		n.Attr[idIndex].Val = id
+	}
+
+	placeholder := c.quoteValue(indicatorIDPlaceholder)
+	body = strings.ReplaceAll(body, placeholder, "id")
+
+	decl, ok := c.indicatorFuncs[body]
+	if !ok {
+		decl = &funcDecl{name: c.uniqueFuncName("LoadingIndicator"), result: "Node", body: body}
+		if idIndex >= 0 {
+			decl.addParam("id", "string")
+		}
+		c.indicatorFuncs[body] = decl
+		c.funcs = append(c.funcs, decl)
+	}
+
+	if idIndex >= 0 {
+		return decl.name + "(" + c.quoteValue(id) + ")"
+	}
+	return decl.name + "()"
+}
+
+// forEachElement calls fn for every element in the trees rooted at nodes
+func forEachElement(nodes []*html.Node, fn func(*html.Node)) {
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			fn(n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
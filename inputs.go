@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// batchExtensions are the extensions of the files collected from directories
+// and glob patterns
+var batchExtensions = map[string]bool{".html": true, ".htm": true}
+
+// inputFile is an input to convert, with its path relative to the directory
+// or pattern prefix it was collected from
+type inputFile struct {
+	path string
+	rel  string
+}
+
+// expandInputs resolves the command line arguments into input files. A
+// directory stands for the HTML files it contains, "dir/..." for those of its
+// whole tree and a pattern with *, ** or ? for the HTML files it matches;
+// http(s) URLs are fetched and other arguments are taken as files. expanded reports whether any argument
+// was a directory or pattern.
+func expandInputs(args []string) (inputs []inputFile, expanded bool, err error) {
+	seen := make(map[string]bool)
+	add := func(file, rel string) {
+		if key := filepath.Clean(file); !seen[key] {
+			seen[key] = true
+			inputs = append(inputs, inputFile{path: file, rel: filepath.ToSlash(rel)})
+		}
+	}
+
+	for _, arg := range args {
+		var root string
+		var match func(rel string) bool
+		recursive := true
+		slashed := filepath.ToSlash(arg)
+		switch {
+		case isURL(arg):
+			// URLs are fetched as they are, ? and all
+			add(arg, urlFilename(arg))
+			continue
+		case slashed == "..." || strings.HasSuffix(slashed, "/..."):
+			root = strings.TrimSuffix(strings.TrimSuffix(slashed, "..."), "/")
+			if root == "" {
+				root = "."
+			}
+			match = func(string) bool { return true }
+		case strings.ContainsAny(slashed, "*?"):
+			re, err := globRegexp(path.Clean(slashed))
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid input pattern %q: %w", arg, err)
+			}
+			root = globPrefix(path.Clean(slashed))
+			match = func(rel string) bool {
+				if root != "." {
+					rel = root + "/" + rel
+				}
+				return re.MatchString(rel)
+			}
+		default:
+			info, err := os.Stat(arg)
+			if err != nil || !info.IsDir() {
+				// Missing files are reported when they are read
+				add(arg, filepath.Base(arg))
+				continue
+			}
+			root = slashed
+			match = func(string) bool { return true }
+			recursive = false
+		}
+
+		expanded = true
+		found := false
+		err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(filepath.FromSlash(root), p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				// Hidden directories such as .git are never searched
+				if rel != "." && (!recursive || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if batchExtensions[strings.ToLower(filepath.Ext(p))] && match(rel) {
+				add(p, rel)
+				found = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read inputs of %s: %w", arg, err)
+		}
+		if !found {
+			return nil, false, fmt.Errorf("no HTML files match %s", arg)
+		}
+	}
+	return inputs, expanded, nil
+}
+
+// outDirPath returns the output path of an input converted into dir: its
+// directory relative to the argument it was collected from is kept and its
+// file name becomes a Go file name, e.g. "blog/Post-List.html" is written to
+// "<dir>/blog/post_list.go"
+func outDirPath(dir string, input inputFile) string {
+	ext := path.Ext(input.rel)
+	base := strings.TrimSuffix(path.Base(input.rel), ext)
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '.' {
+			return '_'
+		}
+		return r
+	}, strings.ToLower(base))
+	return filepath.Join(dir, filepath.FromSlash(path.Dir(input.rel)), name+".go")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandInputs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"index.html", "blog/Post-List.html", "blog/drafts/old.htm", ".git/hooks.html", "notes.txt"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<p>x</p>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := filepath.ToSlash(root)
+
+	tests := []struct {
+		args []string
+		rels []string
+	}{
+		{[]string{dir + "/..."}, []string{"blog/Post-List.html", "blog/drafts/old.htm", "index.html"}},
+		{[]string{dir}, []string{"index.html"}},
+		{[]string{dir + "/blog/*.html"}, []string{"Post-List.html"}},
+		{[]string{dir + "/**/*.html", dir + "/index.html"}, []string{"blog/Post-List.html", "index.html"}},
+	}
+	for _, tt := range tests {
+		inputs, expanded, err := expandInputs(tt.args)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		var rels []string
+		for _, in := range inputs {
+			rels = append(rels, in.rel)
+		}
+		if !expanded || !slices.Equal(rels, tt.rels) {
+			t.Errorf("%v: expected %v, got %v", tt.args, tt.rels, rels)
+		}
+	}
+
+	if inputs, expanded, err := expandInputs([]string{"missing.html"}); err != nil || expanded || len(inputs) != 1 {
+		t.Errorf("Expected a plain file argument to be kept as is, got %v, %v, %v", inputs, expanded, err)
+	}
+	if _, _, err := expandInputs([]string{dir + "/*.xml"}); err == nil {
+		t.Error("Expected an error for a pattern without matches")
+	}
+}
+
+func TestOutDirPath(t *testing.T) {
+	got := outDirPath("views", inputFile{path: "templates/blog/Post-List.html", rel: "blog/Post-List.html"})
+	if want := filepath.Join("views", "blog", "post_list.go"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestResolveOutputs(t *testing.T) {
+	inputs := []inputFile{
+		{path: "templates/index.html", rel: "index.html"},
+		{path: "templates/blog/post-list.html", rel: "blog/post-list.html"},
+	}
+	paths, err := resolveOutputs(inputs, nil, "views")
+	if err != nil {
+		t.Fatalf("Resolving outputs failed: %v", err)
+	}
+	want := []string{filepath.Join("views", "index.go"), filepath.Join("views", "blog", "post_list.go")}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Expected %v, got %v", want, paths)
+	}
+
+	// Colliding inputs are rejected before any of them is converted
+	inputs = append(inputs, inputFile{path: "templates/blog/post_list.htm", rel: "blog/post_list.htm"})
+	if _, err := resolveOutputs(inputs, nil, "views"); err == nil {
+		t.Error("Expected an error for inputs written to the same file")
+	}
+	if _, err := resolveOutputs(inputs[:1], nil, ""); err == nil {
+		t.Error("Expected an error for an input without an output route")
+	}
+}
+
+func TestConvertSourceFileNames(t *testing.T) {
+	defer func() { converted = nil }()
+
+	// Snippets read from a file are named after it, which changed the
+	// Component() of earlier versions; --func keeps the old name
+	tests := []struct {
+		input string
+		opts  Options
+		want  string
+	}{
+		{"templates/card.html", Options{}, "func Card() Node {"},
+		{"templates/card.html", Options{FuncName: "Component"}, "func Component() Node {"},
+		{"stdin", Options{}, "func Component() Node {"},
+	}
+	for _, tt := range tests {
+		code, err := convertSource(context.Background(), tt.opts, tt.input, []byte(`<div class="card">Hi</div>`))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		if !strings.Contains(code, tt.want) {
+			t.Errorf("%s: expected %q.\nOutput:\n%s", tt.input, tt.want, code)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// langContext returns the nearest lang and dir values in effect for a node
+func langContext(n *html.Node) (lang, dir string) {
+	for current := n; current != nil; current = current.Parent {
+		if current.Type != html.ElementNode {
+			continue
+		}
+		for _, attr := range current.Attr {
+			switch attr.Key {
+			case "lang", "xml:lang":
+				if lang == "" {
+					lang = attr.Val
+				}
+			case "dir":
+				if dir == "" {
+					dir = attr.Val
+				}
+			}
+		}
+		if lang != "" && dir != "" {
+			break
+		}
+	}
+	return lang, dir
+}
+
+// documentLangContext returns the lang and dir declared on the html element, if any
+func documentLangContext(n *html.Node) (lang, dir string) {
+	for current := n; current != nil; current = current.Parent {
+		if current.Type == html.ElementNode && current.Data == "html" {
+			return langContext(current)
+		}
+	}
+	return "", ""
+}
+
+// langAnnotation returns a trailing comment describing the language context of a
+// text node, or an empty string when it matches the document's language
+func (c *Converter) langAnnotation(n *html.Node) string {
+	lang, dir := langContext(n)
+	docLang, docDir := documentLangContext(n)
+
+	var parts []string
+	if lang != "" && !strings.EqualFold(lang, docLang) {
+		parts = append(parts, "lang="+lang)
+	}
+	if dir != "" && !strings.EqualFold(dir, docDir) {
+		parts = append(parts, "dir="+dir)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	// Attribute values are arbitrary text, so keep them from closing the comment early
+	annotation := strings.ReplaceAll(strings.Join(parts, " "), "*/", "* /")
+	return fmt.Sprintf(" /* %s */", annotation)
+}
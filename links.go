@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// LinkInfo is a link of a converted page to another page of the same site,
+// or a form submitting to a path of the site
+type LinkInfo struct {
+	// Href is empty for forms submitting to their own page
+	Href string `json:"href"`
+	// Method is the upper case method of a form, empty for links
+	Method string `json:"method,omitempty"`
+	// Node is a selector-like path to the element holding the link
+	Node string `json:"node"`
+}
+
+// Links returns the internal links of the last converted input: the hrefs of
+// its links and image map areas, and the actions of its forms, that lead to a
+// path of the same site
+func (c *Converter) Links() []LinkInfo {
+	return c.links
+}
+
+// collectLinks records the internal links of the input for Links
+func (c *Converter) collectLinks(nodes []*html.Node) {
+	c.links = nil
+	forEachElement(nodes, func(n *html.Node) {
+		switch n.Data {
+		case "a", "area":
+			if href := strings.TrimSpace(attrValue(n, "href")); internalLink(href) {
+				c.links = append(c.links, LinkInfo{Href: href, Node: nodePath(n)})
+			}
+		case "form":
+			method := strings.ToUpper(strings.TrimSpace(attrValue(n, "method")))
+			if method == "" || method == "DIALOG" {
+				method = "GET"
+			}
+			if action := strings.TrimSpace(attrValue(n, "action")); action == "" || internalLink(action) {
+				c.links = append(c.links, LinkInfo{Href: action, Method: method, Node: nodePath(n)})
+			}
+		}
+	})
+}
+
+// internalLink reports whether an href leads to a path of the same site.
+// Links with a scheme or host, links within the page and hrefs holding
+// placeholders filled in at runtime are not.
+func internalLink(href string) bool {
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "?") ||
+		strings.HasPrefix(href, "//") || placeholderPattern.MatchString(href) || propPattern.MatchString(href) {
+		return false
+	}
+	u, err := url.Parse(href)
+	return err == nil && u.Scheme == "" && u.Host == "" && u.Path != ""
+}
+
+// linkTarget resolves an internal link of the page at pagePath, such as
+// "/blog/index.html", to the path it leads to
+func linkTarget(pagePath, href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	target := u.Path
+	if !strings.HasPrefix(target, "/") {
+		target = path.Join(path.Dir(pagePath), target)
+		if strings.HasSuffix(u.Path, "/") {
+			target += "/"
+		}
+	}
+	return target
+}
+
+// pagePath returns the site path a converted input is served at: the path
+// of a fetched URL, or the path of a file relative to its input directory
+func pagePath(comp convertedComponent) string {
+	if isURL(comp.File) {
+		if u, err := url.Parse(comp.File); err == nil && u.Path != "" {
+			return u.Path
+		}
+		return "/"
+	}
+	rel := comp.Rel
+	if rel == "" {
+		rel = path.Base(strings.ReplaceAll(comp.File, "\\", "/"))
+	}
+	return "/" + strings.TrimPrefix(rel, "/")
+}
+
+// sitePaths indexes the paths of converted pages, under every path a static
+// server would serve them at: /about.html, /about and /docs/ for
+// /docs/index.html
+func sitePaths(components []convertedComponent) map[string]bool {
+	paths := make(map[string]bool)
+	for _, comp := range components {
+		p := pagePath(comp)
+		paths[p] = true
+		ext := path.Ext(p)
+		if ext == ".html" || ext == ".htm" {
+			trimmed := strings.TrimSuffix(p, ext)
+			paths[trimmed] = true
+			if path.Base(trimmed) == "index" {
+				dir := path.Dir(trimmed)
+				paths[dir] = true
+				paths[strings.TrimSuffix(dir, "/")+"/"] = true
+			}
+		}
+	}
+	return paths
+}
+
+// loadRoutes reads a list of known routes, one per line. Blank lines and
+// lines starting with # are ignored.
+func loadRoutes(filename string) ([]string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes: %w", err)
+	}
+	var routes []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			return nil, fmt.Errorf("%s: route %q must start with /", filename, line)
+		}
+		routes = append(routes, line)
+	}
+	return routes, nil
+}
+
+// matchRoute reports whether a path matches a known route. Segments written
+// as :name, {name} or * match any single segment, and a final ** any rest of
+// the path; a trailing slash makes no difference.
+func matchRoute(route, target string) bool {
+	routeSegs := strings.Split(strings.Trim(route, "/"), "/")
+	targetSegs := strings.Split(strings.Trim(target, "/"), "/")
+	for i, seg := range routeSegs {
+		if seg == "**" && i == len(routeSegs)-1 {
+			return true
+		}
+		if i >= len(targetSegs) {
+			return false
+		}
+		wildcard := seg == "*" || strings.HasPrefix(seg, ":") ||
+			strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+		if !wildcard && seg != targetSegs[i] {
+			return false
+		}
+	}
+	return len(routeSegs) == len(targetSegs)
+}
+
+// checkLinks reports the internal links of the converted pages that lead
+// neither to another converted page nor to one of the known routes
+func checkLinks(components []convertedComponent, routes []string) []fileDiagnostic {
+	paths := sitePaths(components)
+	var dead []fileDiagnostic
+	for _, comp := range components {
+		page := pagePath(comp)
+		for _, link := range comp.Links {
+			if link.Method != "" {
+				// Forms submit to the backend, not to converted pages
+				continue
+			}
+			target := linkTarget(page, link.Href)
+			if paths[target] || paths[strings.TrimSuffix(target, "/")] {
+				continue
+			}
+			known := false
+			for _, route := range routes {
+				known = known || matchRoute(route, target)
+			}
+			if known {
+				continue
+			}
+			dead = append(dead, fileDiagnostic{File: comp.File, Diagnostic: Diagnostic{
+				Severity: SeverityWarning,
+				Code:     "dead-link",
+				Message:  fmt.Sprintf("link to %s leads to %s, which is neither a converted page nor a known route", link.Href, target),
+				Node:     link.Node,
+			}})
+		}
+	}
+	return dead
+}
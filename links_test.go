@@ -0,0 +1,132 @@
+package main
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	pages := map[string]string{
+		"index.html":      `<a href="/about">About</a><a href="blog/">Blog</a><a href="/blog/first.html#top">First</a><a href="/contact">Contact</a><a href="https://example.com">Out</a><a href="#main">Skip</a><a href="/users/42">User</a>`,
+		"about.html":      `<p>About</p>`,
+		"blog/index.html": `<a href="/">Home</a>`,
+		"blog/first.html": `<a href="../index.html">Home</a><a href="second.html">Next</a><a href="{{next}}">Prop</a>`,
+	}
+	var components []convertedComponent
+	for rel, input := range pages {
+		converter := NewConverterWithOptions(Options{Props: true})
+		if _, err := converter.Convert(input); err != nil {
+			t.Fatalf("Conversion of %s failed: %v", rel, err)
+		}
+		components = append(components, convertedComponent{File: "site/" + rel, Rel: rel, Links: converter.Links()})
+	}
+
+	dead := checkLinks(components, []string{"/users/:id"})
+	var got []string
+	for _, d := range dead {
+		if d.Code != "dead-link" {
+			t.Errorf("Unexpected diagnostic %v", d)
+		}
+		got = append(got, d.File+" "+strings.Fields(d.Message)[2])
+	}
+	want := map[string]bool{"site/index.html /contact": true, "site/blog/first.html second.html": true}
+	if len(got) != len(want) {
+		t.Fatalf("Expected dead links %v, got %v", want, got)
+	}
+	for _, g := range got {
+		if !want[g] {
+			t.Errorf("Unexpected dead link %s", g)
+		}
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	tests := []struct {
+		route, path string
+		match       bool
+	}{
+		{"/", "/", true},
+		{"/users/:id", "/users/42", true},
+		{"/users/{id}/", "/users/42", true},
+		{"/users/*", "/users", false},
+		{"/users/:id", "/users/42/edit", false},
+		{"/docs/**", "/docs/a/b", true},
+		{"/docs", "/blog", false},
+	}
+	for _, tt := range tests {
+		if got := matchRoute(tt.route, tt.path); got != tt.match {
+			t.Errorf("matchRoute(%q, %q) = %v, expected %v", tt.route, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestBuildRoutes(t *testing.T) {
+	pages := []struct{ rel, input string }{
+		{"index.html", `<main><a href="/about.html">About</a><a href="blog/">Blog</a><form action="/subscribe" method="post"></form><form></form></main>`},
+		{"about.html", `<a href="/contact?from=about">Contact</a>`},
+		{"blog/index.html", `<a href="/">Home</a>`},
+	}
+	var components []convertedComponent
+	for _, page := range pages {
+		converter := NewConverterWithOptions(Options{Filename: page.rel})
+		if _, err := converter.Convert(page.input); err != nil {
+			t.Fatalf("Conversion of %s failed: %v", page.rel, err)
+		}
+		components = append(components, convertedComponent{File: page.rel, Rel: page.rel, Func: converter.Functions()[0], Links: converter.Links()})
+	}
+
+	code := routesCode(buildRoutes(components), "views")
+	expected := []string{
+		`{Path: "/", Methods: []string{"GET"}, Component: "Index"},`,
+		`{Path: "/about", Methods: []string{"GET"}, Component: "About"},`,
+		`{Path: "/blog/", Methods: []string{"GET"}, Component: "Index"},`,
+		`{Path: "/contact", Methods: []string{"GET"}},`,
+		`{Path: "/subscribe", Methods: []string{"POST"}},`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(code, exp) {
+			t.Errorf("Expected routes to contain %q, but they don't.\nCode:\n%s", exp, code)
+		}
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("Routes code does not parse: %v\nCode:\n%s", err, code)
+	}
+}
+
+func TestBuildNavGraph(t *testing.T) {
+	pages := []struct{ rel, input string }{
+		{"index.html", `<main hx-push-url="true"><button hx-get="/users">Users</button><div hx-push-url="false"><button hx-post="search">Search</button></div></main>`},
+		{"users.html", `<main><a hx-get="/users/42" hx-push-url="/profile">User</a><button hx-delete="">Clear</button><img hx-get="https://example.com/feed"></main>`},
+	}
+	var components []convertedComponent
+	for _, page := range pages {
+		converter := NewConverterWithOptions(Options{Filename: page.rel})
+		if _, err := converter.Convert(page.input); err != nil {
+			t.Fatalf("Conversion of %s failed: %v", page.rel, err)
+		}
+		components = append(components, convertedComponent{File: page.rel, Rel: page.rel, Func: converter.Functions()[0], Requests: converter.HxRequests()})
+	}
+
+	dot := navGraphDOT(buildNavGraph(components))
+	expected := []string{
+		`"/" [shape=box, label="/\nIndex"];`,
+		`"/users" [shape=box, label="/users\nUsers"];`,
+		`"/search" [shape=ellipse];`,
+		`"/" -> "/search" [label="POST"];`,
+		`"/" -> "/users" [label="GET"];`,
+		`"/" -> "/users" [label="push", style=dashed];`,
+		`"/users" -> "/profile" [label="push", style=dashed];`,
+		`"/users" -> "/users" [label="DELETE"];`,
+		`"/users" -> "/users/42" [label="GET"];`,
+		`"/users" -> "https://example.com/feed" [label="GET"];`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(dot, exp) {
+			t.Errorf("Expected graph to contain %q, but it doesn't.\nGraph:\n%s", exp, dot)
+		}
+	}
+	if strings.Contains(dot, `"/search" [label="push"`) {
+		t.Errorf("hx-push-url=\"false\" should stop the inherited push.\nGraph:\n%s", dot)
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -13,13 +16,125 @@ var (
 	useHTMX     bool
 	useAlpine   bool
 	showVersion bool
+
+	annotateLang   bool
+	hoistConstants int
+	dedupe         int
+	stripNonce     bool
+	cspPolicy      string
+	emailMode      bool
+	fragment       bool
+	fragmentFuncs  bool
+	noFormat       bool
+	qualified      bool
+	htmlAlias      string
+	standalone     bool
+	colorize       bool
+	snippetFormat  string
+	fetchTimeout   time.Duration
+	fetchHeaders   []string
+	userAgent      string
+	playground     bool
+	stdinFilename  string
+	configFile     string
+	routeRules     []string
+	sarifFile      string
+	reportFile     string
+	outDir         string
+	baselineFile   string
+	updateBaseline bool
+	suggestHandler bool
+	rewriteHandler bool
+	eventHandlers  bool
+	checkExprs     bool
+	generateMode   bool
+	classConds     bool
+	dataRefs       bool
+	registryFile   string
+	catalog        bool
+	manifestFile   string
+	checkMode      bool
+	semanticCheck  bool
+	normIndicators bool
+	parameterize   bool
+	props          bool
+	toc            bool
+	headingIDs     bool
+	comments       string
+	checkLinksMode bool
+	routesFile     string
+	routesManifest string
+	navGraphFile   string
+	alpineFile     string
+	stripArtifacts bool
+	profileName    string
+	classVariants  int
+	defines        []string
+	defineConsts   bool
+	patchFile      string
+	mappingsFile   string
+	packageName    string
+	funcName       string
+	paramNames     string
+	exported       bool
+	unexported     bool
+	replaceRules   []string
+	editable       bool
+	normalizeEnums bool
+	componentPer   string
+	split          bool
+	validate       bool
+	parserReport   bool
+	textMode       string
+	mode           string
+	appendTo       string
+	themeFile      string
+	themeTokens    bool
+	themeCoverage  bool
+	darkVariants   bool
+	flatten        bool
+	stripAttrs     []string
+	iconsDir       string
+	hoistSVG       bool
+	svgFile        string
+	favicons       bool
+	stubSnippets   bool
+	stripConsent   bool
+	consentStub    string
+	a11yFix        bool
+	decorative     string
+	headingDepth   int
+	formStructs    bool
+	metaHelpers    bool
+	pinScripts     bool
+	pageMeta       bool
+	csrfHelper     string
+
+	// reported collects the diagnostics of every converted input, and
+	// converted records the main function generated from each input
+	reported  []fileDiagnostic
+	converted []convertedComponent
+
+	// baseline holds the known diagnostics loaded from --baseline, and known
+	// collects the diagnostics it suppressed
+	baseline *diagnosticBaseline
+	known    []fileDiagnostic
+
+	// knownRoutes are the site paths loaded from --known-routes that links
+	// may lead to besides the converted pages
+	knownRoutes []string
+
+	// outdated lists the outputs that differ from the generated code in check mode
+	outdated []string
 )
 
 const version = "1.0.0"
 
 var rootCmd = &cobra.Command{
-	Use:   "plainkit-converter [input]",
+	Use:   "plainkit-converter [input...]",
 	Short: "Convert HTML to Plain Go code",
+	// Inputs are file paths, so they must not be mistaken for subcommands
+	Args: cobra.ArbitraryArgs,
 	Long: `Plain Converter transforms HTML files into Go code using the Plain HTML library.
 
 It supports standard HTML, htmx attributes, and Alpine.js directives.
@@ -41,7 +156,13 @@ Examples:
   plainkit-converter index.html -o component.go
 
   # Convert with both htmx and Alpine.js
-  plainkit-converter --htmx --alpine index.html`,
+  plainkit-converter --htmx --alpine index.html
+
+  # Convert every HTML file below templates/ into views/
+  plainkit-converter --out-dir views ./templates/...
+
+  # Convert several files, routing each to an output path
+  plainkit-converter --route 'pages/**.html -> views/{dir}/{base}.go' pages/*.html`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if showVersion {
@@ -49,23 +170,88 @@ Examples:
 			return nil
 		}
 
-		var input io.Reader
+		cfg := &Config{}
+		if configFile != "" {
+			var err error
+			if cfg, err = loadConfig(configFile); err != nil {
+				return err
+			}
+		}
+		opts, err := converterOptions(cfg)
+		if err != nil {
+			return err
+		}
+		router, err := newOutputRouter(append(cfg.Routes, routeRules...))
+		if err != nil {
+			return err
+		}
+		if routesFile != "" {
+			if knownRoutes, err = loadRoutes(routesFile); err != nil {
+				return err
+			}
+		}
+		if updateBaseline && baselineFile == "" {
+			return fmt.Errorf("--update-baseline needs a --baseline file")
+		}
+		if baselineFile != "" && !updateBaseline {
+			if baseline, err = loadBaseline(baselineFile); err != nil {
+				return err
+			}
+		}
+
+		inputs, expanded, err := expandInputs(args)
+		if err != nil {
+			return err
+		}
+		// Directories, patterns and --out-dir always write one file per input
+		batch := len(inputs) > 1 || expanded || outDir != ""
+
+		if funcName != "" && len(inputs) > 1 {
+			return fmt.Errorf("--func names a single function and cannot be used with multiple inputs")
+		}
+
+		if playground && (batch || outputFile != "" || appendTo != "" || snippetFormat != "" || checkMode || len(router) > 0) {
+			return fmt.Errorf("--playground prints a share link for a single input and cannot be combined with other outputs")
+		}
+
+		if generateMode {
+			if appendTo != "" || snippetFormat != "" || playground {
+				return fmt.Errorf("--generate writes whole Go files and cannot be combined with --append-to, --snippet or --playground")
+			}
+			if generateSeed, err = newGenerateSeed(cmd.Flags()); err != nil {
+				return err
+			}
+		}
+
+		if appendTo != "" {
+			if outputFile != "" || batch {
+				return fmt.Errorf("--append-to takes a single input and cannot be combined with --output")
+			}
+			if editable {
+				return fmt.Errorf("--append-to cannot be combined with --editable")
+			}
+		}
+
+		if batch || (len(inputs) == 1 && len(router) > 0 && outputFile == "" && appendTo == "") {
+			if outputFile != "" {
+				return fmt.Errorf("--output cannot be used with multiple inputs; use --out-dir or --route instead")
+			}
+			if err := convertFiles(cmd.Context(), opts, inputs, router); err != nil {
+				return err
+			}
+			return writeReports()
+		}
+
+		var htmlContent []byte
 		var inputName string
 
 		// Determine input source
-		if len(args) > 0 {
-			// Read from file
-			inputName = args[0]
-			file, err := os.Open(inputName)
-			if err != nil {
-				return fmt.Errorf("failed to open input file: %w", err)
+		if len(inputs) > 0 {
+			// Read from file or URL
+			inputName = inputs[0].path
+			if htmlContent, err = readInput(cmd.Context(), inputName); err != nil {
+				return err
 			}
-			defer func() {
-				if err := file.Close(); err != nil {
-					fmt.Fprintf(os.Stderr, "Error closing file: %v\n", err)
-				}
-			}()
-			input = file
 		} else {
 			// Read from stdin
 			stat, _ := os.Stdin.Stat()
@@ -73,50 +259,569 @@ Examples:
 				// No stdin input
 				return fmt.Errorf("no input provided. Use a file argument or pipe HTML to stdin")
 			}
-			input = os.Stdin
 			inputName = "stdin"
-		}
-
-		// Read input
-		htmlContent, err := io.ReadAll(input)
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
+			if stdinFilename != "" {
+				inputName = stdinFilename
+			}
+			if htmlContent, err = io.ReadAll(os.Stdin); err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
 		}
 
 		// Convert HTML to Plain
-		converter := NewConverter(useHTMX, useAlpine)
-		goCode, err := converter.Convert(string(htmlContent))
+		goCode, err := convertSource(cmd.Context(), opts, inputName, htmlContent)
 		if err != nil {
-			return fmt.Errorf("conversion failed: %w", err)
+			return err
 		}
 
 		// Determine output
-		if outputFile != "" {
-			// Write to file
-			err = os.WriteFile(outputFile, []byte(goCode), 0644)
+		if playground {
+			program, err := playgroundProgram(goCode, converted[len(converted)-1].Func)
 			if err != nil {
-				return fmt.Errorf("failed to write output file: %w", err)
+				return err
 			}
-			fmt.Printf("✓ Converted %s → %s\n", inputName, outputFile)
+			link, err := sharePlayground(cmd.Context(), program, fetchTimeout)
+			if err != nil {
+				return err
+			}
+			fmt.Println(link)
+		} else if appendTo != "" {
+			merged, err := appendToFile(appendTo, goCode)
+			if err != nil {
+				return err
+			}
+			recordOutput(appendTo)
+			if err := emitOutput(inputName, appendTo, merged); err != nil {
+				return err
+			}
+		} else if outputFile != "" {
+			// Write to file
+			recordOutput(outputFile)
+			if generateMode {
+				if err := emitGenerated(inputName, outputFile, htmlContent, goCode); err != nil {
+					return err
+				}
+			} else if err := emitOutput(inputName, outputFile, goCode); err != nil {
+				return err
+			}
+		} else if checkMode {
+			return fmt.Errorf("--check needs an output file (-o or --route) to compare against")
 		} else {
 			// Write to stdout
+			if generateMode {
+				goCode = withGeneratedHeader(goCode, inputName, sourceHash(htmlContent))
+			}
+			if colorOutput(colorize) && snippetFormat == "" {
+				goCode = highlightGo(goCode)
+			}
 			fmt.Print(goCode)
 		}
 
-		return nil
+		return writeReports()
 	},
 }
 
+// converterOptions builds the conversion options from the command line flags and config
+func converterOptions(cfg *Config) (Options, error) {
+	opts := Options{
+		HTMX:            useHTMX,
+		Alpine:          useAlpine,
+		Package:         packageName,
+		FuncName:        funcName,
+		Unexported:      unexported || !exported,
+		AnnotateLang:    annotateLang,
+		HoistConstants:  hoistConstants,
+		Dedupe:          dedupe,
+		StripNonce:      stripNonce,
+		CSP:             cspPolicy,
+		Email:           emailMode,
+		FragmentWrapper: fragment,
+		FragmentFuncs:   fragmentFuncs,
+		NoFormat:        noFormat,
+		Qualified:       qualified,
+		HTMLAlias:       htmlAlias,
+		Standalone:      standalone,
+		SuggestHandlers: suggestHandler,
+		RewriteHandlers: rewriteHandler,
+		Events:          eventHandlers,
+		ClassConditions: classConds,
+		AlpineData:      dataRefs,
+
+		NormalizeIndicators: normIndicators,
+		Parameterize:        parameterize,
+		Props:               props,
+		TOC:                 toc,
+		HeadingIDs:          headingIDs,
+		Comments:            comments,
+		ParamNames:          paramNames,
+
+		StripDesignArtifacts: stripArtifacts,
+		Profile:              profileName,
+		ClassVariants:        classVariants,
+		DefineConsts:         defineConsts,
+		EditableRegions:      editable,
+		NormalizeEnums:       normalizeEnums,
+		ComponentPer:         componentPer,
+		Split:                split,
+		Validate:             validate,
+		CheckExpressions:     checkExprs,
+		ReportMutations:      parserReport,
+		TextMode:             textMode,
+		Mode:                 mode,
+		ThemeTokens:          themeTokens,
+		ThemeCoverage:        themeCoverage,
+		DarkVariants:         darkVariants,
+		Flatten:              flatten,
+		StripAttributes:      stripAttrs,
+		HoistSVG:             hoistSVG || svgFile != "",
+		SeparateSVG:          svgFile != "",
+		GroupFavicons:        favicons,
+		StubSnippets:         stubSnippets,
+		StripConsent:         stripConsent || consentStub != "",
+		ConsentStub:          consentStub,
+		A11yFix:              a11yFix,
+		DecorativeImages:     decorative,
+		HeadingLevel:         headingDepth,
+		FormStructs:          formStructs,
+		MetaHelpers:          metaHelpers,
+		PinScripts:           pinScripts,
+		PageMeta:             pageMeta,
+		CSRFHelper:           csrfHelper,
+	}
+	if err := validateNames(packageName, funcName); err != nil {
+		return Options{}, err
+	}
+	if err := validateAlias(htmlAlias); err != nil {
+		return Options{}, err
+	}
+	if standalone && qualified {
+		return Options{}, fmt.Errorf("--standalone cannot be combined with --qualified")
+	}
+	if err := validateSnippet(snippetFormat); err != nil {
+		return Options{}, err
+	}
+	if _, err := parseHeaders(fetchHeaders); err != nil {
+		return Options{}, err
+	}
+	if headingDepth < 0 || headingDepth > 6 {
+		return Options{}, fmt.Errorf("--heading-level must be between 1 and 6")
+	}
+	if iconsDir != "" {
+		imp, err := iconsImportPath(iconsDir)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.IconsImport = imp
+	}
+	if themeFile != "" {
+		theme, err := loadTheme(themeFile)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Theme = theme
+	}
+	if mappingsFile != "" {
+		mappings, err := loadMappings(mappingsFile)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Mappings = mappings
+	}
+	if patchFile != "" {
+		patches, err := loadPatches(patchFile)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Patches = append(opts.Patches, patches...)
+	}
+	for _, rule := range replaceRules {
+		patch, err := parseReplacement(rule)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Patches = append(opts.Patches, patch)
+	}
+	for _, def := range defines {
+		name, value, err := parseDefine(def)
+		if err != nil {
+			return Options{}, err
+		}
+		if opts.Defines == nil {
+			opts.Defines = make(map[string]string)
+		}
+		opts.Defines[name] = value
+	}
+	if err := cfg.apply(&opts); err != nil {
+		return Options{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return opts, nil
+}
+
+// convertSource converts HTML content and prints its diagnostics to stderr
+func convertSource(ctx context.Context, opts Options, inputName string, htmlContent []byte) (string, error) {
+	opts.Filename = inputName
+	if isURL(inputName) {
+		// Fetched pages are named like saved copies and keep their SEO metadata
+		opts.Filename = urlFilename(inputName)
+		opts.PageMeta = true
+	}
+	converter := NewConverterWithOptions(opts)
+	goCode, err := converter.ConvertContext(ctx, string(htmlContent))
+	if err != nil {
+		return "", fmt.Errorf("conversion of %s failed: %w", inputName, err)
+	}
+
+	for _, d := range converter.Diagnostics() {
+		recordDiagnostic(fileDiagnostic{File: inputName, Diagnostic: d})
+	}
+	if code := converter.IconsCode(); code != "" {
+		// Icons of several inputs share one file; icons of the same name are replaced
+		path := filepath.Join(iconsDir, importName(opts.IconsImport)+".go")
+		merged, err := appendToFile(path, code)
+		if err != nil {
+			return "", err
+		}
+		if err := emitOutput(inputName, path, merged); err != nil {
+			return "", err
+		}
+	}
+	if code := converter.SVGCode(); code != "" {
+		merged, err := appendToFile(svgFile, code)
+		if err != nil {
+			return "", err
+		}
+		if err := emitOutput(inputName, svgFile, merged); err != nil {
+			return "", err
+		}
+	}
+	if funcs := converter.Functions(); len(funcs) > 0 {
+		converted = append(converted, convertedComponent{File: inputName, Package: converter.packageName(), HTML: string(htmlContent), Func: funcs[0], Funcs: len(funcs), Links: converter.Links(), Requests: converter.HxRequests(), Alpine: converter.AlpineUsage()})
+	}
+	if snippetFormat != "" {
+		if goCode, err = editorSnippet(snippetFormat, goCode, inputName); err != nil {
+			return "", fmt.Errorf("%s: %w", inputName, err)
+		}
+	}
+	return goCode, nil
+}
+
+// recordDiagnostic prints a diagnostic and collects it for the reports,
+// unless the baseline knows it
+func recordDiagnostic(fd fileDiagnostic) {
+	if baseline.known(fd) {
+		known = append(known, fd)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n", fd.File, fd.Diagnostic)
+	reported = append(reported, fd)
+}
+
+// recordOutput notes where the code of the most recently converted input was written
+func recordOutput(path string) {
+	if len(converted) > 0 {
+		converted[len(converted)-1].Output = path
+	}
+}
+
+// generatedPackage returns the package of the converted files, which the
+// registry and Go routes are declared in
+func generatedPackage() string {
+	if len(converted) > 0 {
+		return converted[0].Package
+	}
+	if packageName == "" {
+		return "main"
+	}
+	return packageName
+}
+
+// writeReports writes the reports and indexes requested on the command line
+func writeReports() error {
+	if checkLinksMode || routesFile != "" {
+		for _, fd := range checkLinks(converted, knownRoutes) {
+			recordDiagnostic(fd)
+		}
+	}
+	if sarifFile != "" {
+		if err := writeSARIF(sarifFile, reported); err != nil {
+			return err
+		}
+	}
+	if reportFile != "" {
+		if err := writeReport(reportFile, converted, reported); err != nil {
+			return err
+		}
+	}
+	// The manifest indexes the output files, so --check leaves it alone like
+	// them; the registry and Go routes are compared by emitOutput instead
+	if manifestFile != "" && !checkMode {
+		if err := writeManifest(manifestFile, converted); err != nil {
+			return err
+		}
+	}
+	if routesManifest != "" {
+		if err := writeRoutes(routesManifest, converted, generatedPackage()); err != nil {
+			return err
+		}
+	}
+	if alpineFile != "" {
+		if err := writeAlpineReport(alpineFile, converted); err != nil {
+			return err
+		}
+	}
+	if navGraphFile != "" {
+		if err := writeNavGraph(navGraphFile, converted); err != nil {
+			return err
+		}
+	}
+	if registryFile != "" {
+		code, warnings := buildRegistry(converted, generatedPackage(), catalog)
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "registry: %s\n", w)
+		}
+		if qualified {
+			// Components are declared in the converted files of the package
+			local := make(map[string]bool)
+			for _, comp := range converted {
+				local[comp.Func.Name] = true
+			}
+			var err error
+			if code, err = qualifyCode(code, htmlAlias, local); err != nil {
+				return err
+			}
+		}
+		if err := emitOutput("registry", registryFile, code); err != nil {
+			return err
+		}
+	}
+	if baselineFile != "" {
+		if baseline == nil {
+			// A missing baseline is created, and --update-baseline replaces it
+			all := append(known, reported...)
+			if err := writeBaseline(baselineFile, all); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Recorded %d diagnostic(s) in %s\n", len(all), baselineFile)
+		} else if len(known) > 0 {
+			fmt.Fprintf(os.Stderr, "%d known diagnostic(s) suppressed by %s\n", len(known), baselineFile)
+		}
+	}
+	if len(outdated) > 0 {
+		return fmt.Errorf("%d generated file(s) are out of date", len(outdated))
+	}
+	if baseline != nil {
+		if n := newFindings(reported); n > 0 {
+			return fmt.Errorf("%d warning(s) or error(s) are not in the baseline %s", n, baselineFile)
+		}
+	}
+	return nil
+}
+
+// convertFiles converts several input files, writing each to the path chosen
+// by the router or, when no route matches, below --out-dir
+func convertFiles(ctx context.Context, opts Options, inputs []inputFile, router outputRouter) error {
+	// Every output path is known before anything is written, so that a
+	// collision leaves no partial tree behind
+	outputPaths, err := resolveOutputs(inputs, router, outDir)
+	if err != nil {
+		return err
+	}
+	for i, input := range inputs {
+		inputName, outputPath := input.path, outputPaths[i]
+		htmlContent, err := readInput(ctx, inputName)
+		if err != nil {
+			return err
+		}
+		goCode, err := convertSource(ctx, opts, inputName, htmlContent)
+		if err != nil {
+			return err
+		}
+
+		recordOutput(outputPath)
+		converted[len(converted)-1].Rel = input.rel
+		if generateMode {
+			if err := emitGenerated(inputName, outputPath, htmlContent, goCode); err != nil {
+				return err
+			}
+		} else if err := emitOutput(inputName, outputPath, goCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveOutputs returns the output path of every input, rejecting inputs
+// without a route and inputs that would be written to the same file
+func resolveOutputs(inputs []inputFile, router outputRouter, dir string) ([]string, error) {
+	paths := make([]string, len(inputs))
+	sources := make(map[string]string)
+	for i, input := range inputs {
+		outputPath, ok := router.resolve(input.path)
+		if !ok && dir != "" {
+			outputPath, ok = outDirPath(dir, input), true
+		}
+		if !ok {
+			return nil, fmt.Errorf("no output route matches %s; use --out-dir or --route", input.path)
+		}
+		if other, taken := sources[outputPath]; taken {
+			return nil, fmt.Errorf("%s and %s would both be written to %s", other, input.path, outputPath)
+		}
+		sources[outputPath] = input.path
+		paths[i] = outputPath
+	}
+	return paths, nil
+}
+
+// readInput reads an input file, or fetches it when it is a URL
+func readInput(ctx context.Context, name string) ([]byte, error) {
+	if isURL(name) {
+		return fetchURL(ctx, name, fetchOptions{Timeout: fetchTimeout, Headers: fetchHeaders, UserAgent: userAgent})
+	}
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	return content, nil
+}
+
+// emitOutput writes generated code to its output file, or in check mode
+// verifies that the file is already up to date
+func emitOutput(inputName, outputPath, goCode string) error {
+	if editable {
+		var err error
+		if goCode, err = preserveRegions(outputPath, goCode); err != nil {
+			return err
+		}
+	}
+
+	if checkMode {
+		upToDate, err := outputUpToDate(outputPath, goCode, semanticCheck)
+		if err != nil {
+			return err
+		}
+		if !upToDate {
+			outdated = append(outdated, outputPath)
+			fmt.Fprintf(os.Stderr, "✗ %s is out of date with %s\n", outputPath, inputName)
+		}
+		return nil
+	}
+
+	if err := writeOutputFile(outputPath, goCode); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Converted %s → %s\n", inputName, outputPath)
+	return nil
+}
+
+// writeOutputFile writes generated code, creating parent directories as needed
+func writeOutputFile(path, goCode string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(goCode), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+	rootCmd.Flags().StringVar(&packageName, "package", "", "Package of the generated code (default: main)")
+	rootCmd.Flags().StringVar(&funcName, "func", "", "Name of the generated function in place of Page, Component or the name derived from the input file")
+	rootCmd.Flags().BoolVar(&exported, "exported", true, "Start the generated function name with an upper case letter")
+	rootCmd.Flags().BoolVar(&unexported, "unexported", false, "Start the generated function name with a lower case letter")
+	rootCmd.MarkFlagsMutuallyExclusive("exported", "unexported")
 	rootCmd.Flags().BoolVar(&useHTMX, "htmx", false, "Enable htmx attribute conversion")
 	rootCmd.Flags().BoolVar(&useAlpine, "alpine", false, "Enable Alpine.js attribute conversion")
+	rootCmd.Flags().BoolVar(&annotateLang, "annotate-lang", false, "Annotate text nodes with their lang/dir context")
+	rootCmd.Flags().IntVar(&hoistConstants, "hoist-constants", 0, "Hoist attribute values repeated at least N times into constants")
+	rootCmd.Flags().IntVar(&dedupe, "dedupe", 0, "Convert sibling subtrees repeated at least N times through one parameterized helper")
+	rootCmd.Flags().BoolVar(&stripNonce, "strip-nonce", false, "Replace nonce values with a nonce parameter")
+	rootCmd.Flags().StringVar(&cspPolicy, "csp", "", "Report inline scripts and styles blocked by this Content-Security-Policy")
+	rootCmd.Flags().BoolVar(&emailMode, "email", false, "Check markup against email-client constraints")
+	rootCmd.Flags().BoolVar(&fragment, "fragment", false, "Wrap multiple root elements in Fragment() instead of returning []Node")
+	rootCmd.Flags().BoolVar(&fragmentFuncs, "fragment-funcs", false, "Give each root element of multi-fragment input a function of its own, referenced by the main function")
+	rootCmd.Flags().BoolVar(&noFormat, "no-format", false, "Write the generated code as rendered instead of running it through gofmt")
+	rootCmd.Flags().BoolVar(&qualified, "qualified", false, "Import plainkit/html by name and qualify its identifiers (html.Div) instead of using a dot import")
+	rootCmd.Flags().StringVar(&htmlAlias, "html-alias", "html", "Package name of plainkit/html in --qualified mode")
+	rootCmd.Flags().BoolVar(&standalone, "standalone", false, "Replace the Plain imports with local stubs so the file compiles on its own, for experiments only")
+	rootCmd.Flags().BoolVar(&colorize, "color", false, "Syntax-highlight generated code written to a terminal; piped output and NO_COLOR stay plain")
+	rootCmd.Flags().StringVar(&snippetFormat, "snippet", "", "Write an editor snippet instead of a Go file, with the function name and parameters as tab stops: vscode or jetbrains")
+	rootCmd.Flags().DurationVar(&fetchTimeout, "timeout", 30*time.Second, "Time limit for HTTP requests: fetching inputs given as http(s) URLs and --playground uploads")
+	rootCmd.Flags().StringArrayVar(&fetchHeaders, "header", nil, "Request header sent when fetching URL inputs, as 'Name: value' (repeatable)")
+	rootCmd.Flags().StringVar(&userAgent, "user-agent", "plainkit-converter/"+version, "User-Agent sent when fetching URL inputs")
+	rootCmd.Flags().BoolVar(&playground, "playground", false, "Upload the component, wrapped in a main that prints it, to the Go Playground and print the share link")
+	rootCmd.Flags().StringVar(&stdinFilename, "stdin-filename", "", "File name to assume for stdin input (used for naming, diagnostics and syntax detection)")
+	rootCmd.Flags().StringVar(&configFile, "config", "", "Configuration file (YAML or JSON)")
+	rootCmd.Flags().StringVar(&outDir, "out-dir", "", "Write each input to a Go file below this directory, keeping its relative directory, e.g. blog/post-list.html to blog/post_list.go")
+	rootCmd.Flags().StringArrayVar(&routeRules, "route", nil, "Output routing rule 'pattern -> template' (repeatable)")
+	rootCmd.Flags().StringVar(&sarifFile, "sarif", "", "Write diagnostics to a SARIF file")
+	rootCmd.Flags().StringVar(&baselineFile, "baseline", "", "Suppress the diagnostics recorded in this JSON file and fail on new warnings and errors; the file is created when missing")
+	rootCmd.Flags().BoolVar(&updateBaseline, "update-baseline", false, "Rewrite the --baseline file with the diagnostics of this run")
+	rootCmd.Flags().StringVar(&reportFile, "report", "", "Write an HTML report with per-file statistics, diagnostics by severity and migration progress")
+	rootCmd.Flags().BoolVar(&suggestHandler, "suggest-handlers", false, "Report inline on* handlers with Alpine/htmx replacement suggestions")
+	rootCmd.Flags().BoolVar(&rewriteHandler, "rewrite-handlers", false, "Rewrite inline on* handlers into Alpine @ attributes")
+	rootCmd.Flags().BoolVar(&eventHandlers, "events", false, "Convert inline on* handlers with the Plain event helpers, such as OnClick, instead of Custom")
+	rootCmd.Flags().BoolVar(&classConds, "class-conditions", false, "Convert Alpine :class object literals into a helper call pairing each class with its condition")
+	rootCmd.Flags().BoolVar(&dataRefs, "alpine-data", false, "Convert x-data values naming a component registered with Alpine.data() into an alpineData helper call")
+	rootCmd.Flags().BoolVar(&checkExprs, "check-expressions", false, "Report syntax errors in Alpine expressions, hx-on handlers and the JSON of hx-vals and hx-headers")
+	rootCmd.Flags().StringVar(&registryFile, "registry", "", "Write a Go file registering every converted component")
+	rootCmd.Flags().BoolVar(&catalog, "catalog", false, "Add a Catalog() page rendering every component to the registry")
+	rootCmd.Flags().BoolVar(&checkLinksMode, "check-links", false, "Report internal links that lead neither to another converted page nor to a known route")
+	rootCmd.Flags().StringVar(&routesFile, "known-routes", "", "File listing site routes that links may lead to besides the converted pages, one per line, e.g. /blog/:slug (implies --check-links)")
+	rootCmd.Flags().StringVar(&routesManifest, "routes-manifest", "", "Write the paths of the converted pages and of the links and form actions they contain, with their methods, to a JSON file or a Go file when it ends in .go")
+	rootCmd.Flags().StringVar(&navGraphFile, "nav-graph", "", "Write the graph of the htmx requests of the converted pages and the URLs they push into the history to a JSON file, or a Graphviz file when it ends in .dot or .gv")
+	rootCmd.Flags().StringVar(&alpineFile, "alpine-report", "", "Write the Alpine stores, dispatched events and refs the converted components use, with the files using them, to a JSON file")
+	rootCmd.Flags().StringVar(&manifestFile, "manifest", "", "Write a JSON manifest describing every converted component")
+	rootCmd.Flags().BoolVar(&generateMode, "generate", false, "Write files for go:generate: a generated-code header naming the source and its hash, and no rewrite of outputs whose hash is unchanged")
+	rootCmd.Flags().BoolVar(&checkMode, "check", false, "Verify output files are up to date instead of writing them")
+	rootCmd.Flags().BoolVar(&semanticCheck, "semantic", false, "With --check, compare the HTML the generated functions render, ignoring formatting, quoting, comments, declaration order, imports and hoisted constants")
+	rootCmd.Flags().BoolVar(&normIndicators, "normalize-indicators", false, "Convert htmx loading indicators through a shared LoadingIndicator() helper")
+	rootCmd.Flags().BoolVar(&parameterize, "parameterize", false, "Turn per-page values such as the title and meta description into parameters, and details groups, pagination and breadcrumbs into helpers")
+	rootCmd.Flags().BoolVar(&props, "props", false, "Turn {{name}} in text and attribute values, and data-prop and data-prop-* attributes, into parameters, typed from the sample value with --parameterize")
+	rootCmd.Flags().StringVar(&paramNames, "param-names", ParamNamesMerge, "Naming of --parameterize parameters: merge (values of the same name share one), unique (differing values get numbered names) or source (ogTitle, twitterDescription)")
+	rootCmd.Flags().BoolVar(&stripArtifacts, "strip-design-artifacts", false, "Remove Webflow/Figma/Framer export attributes")
+	rootCmd.Flags().StringVar(&profileName, "profile", "", "Clean up a site builder export before conversion (webflow, framer, bootstrap)")
+	rootCmd.Flags().IntVar(&classVariants, "class-variants", 0, "Extract class lists repeated at least N times into class constants or per-tag variants maps")
+	rootCmd.Flags().StringArrayVar(&defines, "define", nil, "Resolve ${NAME} placeholders, as NAME=value (repeatable)")
+	rootCmd.Flags().BoolVar(&defineConsts, "define-consts", false, "Emit defined values as Go constants instead of inlining them")
+	rootCmd.Flags().StringVar(&mappingsFile, "mappings", "", "YAML file adding or replacing element and attribute to function mappings")
+	rootCmd.Flags().StringVar(&patchFile, "patch", "", "YAML file mapping CSS selectors to overrides of the generated code")
+	rootCmd.Flags().StringArrayVar(&replaceRules, "replace", nil, "Replace elements with a component call, as 'selector -> call' (repeatable)")
+	rootCmd.Flags().BoolVar(&editable, "editable", false, "Emit editable regions and keep their contents when regenerating output files")
+	rootCmd.Flags().BoolVar(&normalizeEnums, "normalize-enums", false, "Lowercase enumerated attribute values such as method=\"POST\"")
+	rootCmd.Flags().StringVar(&componentPer, "component-per", "", "Generate one function per region matching this selector, e.g. \"#hero, #faq\"")
+	rootCmd.Flags().BoolVar(&split, "split", false, "Generate functions for the header, nav, main, aside, footer and sections with an id of a page, composed by Page()")
+	rootCmd.Flags().BoolVar(&validate, "validate", false, "Report invalid nesting and duplicate elements that the parser would silently repair")
+	rootCmd.Flags().BoolVar(&parserReport, "parser-mutations", false, "Report elements the HTML parser moved, inserted or dropped compared to the source")
+	rootCmd.Flags().StringVar(&mode, "mode", ModeAuto, "Convert the input as a full page or a fragment: auto, page or fragment")
+	rootCmd.Flags().StringVar(&comments, "comments", CommentsDrop, "HTML comment handling: drop, code (Go comments above the following call) or node (Comment nodes rendered into the page)")
+	rootCmd.Flags().StringVar(&textMode, "text-mode", TextTrim, "Text node handling: trim, collapse or verbatim")
+	rootCmd.Flags().StringVar(&appendTo, "append-to", "", "Merge the generated function and imports into an existing Go file, replacing a function of the same name")
+	rootCmd.Flags().StringVar(&themeFile, "theme", "", "CSS file whose custom properties var() references are checked against")
+	rootCmd.Flags().BoolVar(&themeTokens, "theme-tokens", false, "Emit a map of the CSS custom properties the component references")
+	rootCmd.Flags().BoolVar(&themeCoverage, "theme-coverage", false, "Report how many elements with Tailwind color classes have dark: variants")
+	rootCmd.Flags().BoolVar(&darkVariants, "dark-variants", false, "Keep dark: classes apart from base classes through a withDark helper")
+	rootCmd.Flags().BoolVar(&flatten, "flatten", false, "Remove div and span wrappers that have no attributes and a single element child")
+	rootCmd.Flags().StringSliceVar(&stripAttrs, "strip", nil, "Drop these attributes, e.g. data-test,data-gtm-* or the presets testing and analytics")
+	rootCmd.Flags().StringVar(&iconsDir, "icons", "", "Move SVG sprite symbols and repeated inline icons into a package in this directory")
+	rootCmd.Flags().BoolVar(&hoistSVG, "hoist-svg", false, "Move every inline svg into a function of its own, such as IconLogo or IllustrationHero")
+	rootCmd.Flags().StringVar(&svgFile, "svg-file", "", "Write the functions of hoisted SVGs to this file instead of the main output (implies --hoist-svg)")
+	rootCmd.Flags().BoolVar(&favicons, "favicons", false, "Replace the icon, manifest and theme-color tags of the head with a Favicons(basePath) helper")
+	rootCmd.Flags().BoolVar(&stubSnippets, "stub-snippets", false, "Replace Google tag, Tag Manager, Hotjar and Intercom snippets with analytics helper calls")
+	rootCmd.Flags().BoolVar(&stripConsent, "strip-consent", false, "Remove cookie banners and consent management scripts (OneTrust, Cookiebot, ...)")
+	rootCmd.Flags().StringVar(&consentStub, "consent-stub", "", "Replace the first consent banner with this call, e.g. \"views.CookieConsent()\" (implies --strip-consent)")
+	rootCmd.Flags().BoolVar(&a11yFix, "a11y-fix", false, "Apply safe accessibility fixes: empty alt on decorative images, button types in forms, label ids")
+	rootCmd.Flags().StringVar(&decorative, "decorative", "", "CSS selector for images that --a11y-fix marks as decorative, e.g. \"img.divider\"")
+	rootCmd.Flags().BoolVar(&headingIDs, "heading-ids", false, "Give headings without an id one derived from their text, such as getting-started, for deep links")
+	rootCmd.Flags().BoolVar(&toc, "toc", false, "Add a TableOfContents helper linking to the headings, giving headings without an id one derived from their text")
+	rootCmd.Flags().IntVar(&headingDepth, "heading-level", 0, "Shift headings so the component's highest heading is at this level (1-6)")
+	rootCmd.Flags().StringVar(&csrfHelper, "csrf-helper", "", "Replace hidden CSRF token inputs with this call, e.g. \"views.CSRFField()\", instead of a csrfToken parameter")
+	rootCmd.Flags().BoolVar(&pageMeta, "page-meta", false, "Declare the page's title, description, OpenGraph image and other SEO metadata in a variable next to it")
+	rootCmd.Flags().BoolVar(&pinScripts, "pin-scripts", false, "Keep scripts written in the head there when earlier content made the parser start the body")
+	rootCmd.Flags().BoolVar(&metaHelpers, "meta-helpers", false, "Replace the standard viewport meta and theme-color metas with Viewport and ThemeColor helpers, and warn about pages without a viewport")
+	rootCmd.Flags().BoolVar(&formStructs, "form-structs", false, "Generate a struct and a Bind helper for every form from the names and types of its controls")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version")
 }
-
-func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
-}
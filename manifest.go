@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestEntry describes one converted component for catalog and review tools
+type manifestEntry struct {
+	Name    string   `json:"name"`
+	Params  []string `json:"params,omitempty"`
+	Result  string   `json:"result"`
+	Source  string   `json:"source"`
+	Output  string   `json:"output,omitempty"`
+	Preview string   `json:"preview"`
+}
+
+// buildManifest describes every converted component
+func buildManifest(components []convertedComponent) []manifestEntry {
+	entries := make([]manifestEntry, 0, len(components))
+	for _, comp := range components {
+		entries = append(entries, manifestEntry{
+			Name:    comp.Func.Name,
+			Params:  comp.Func.Params,
+			Result:  comp.Func.Result,
+			Source:  filepath.ToSlash(comp.File),
+			Output:  filepath.ToSlash(comp.Output),
+			Preview: comp.HTML,
+		})
+	}
+	return entries
+}
+
+// writeManifest writes the component manifest as JSON
+func writeManifest(path string, components []convertedComponent) error {
+	data, err := json.MarshalIndent(struct {
+		Components []manifestEntry `json:"components"`
+	}{buildManifest(components)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
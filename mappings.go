@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mappingsFormat    string
+	mappingsOverrides string
+)
+
+var mappingsCmd = &cobra.Command{
+	Use:   "mappings",
+	Short: "Inspect the element and attribute mappings of the converter",
+}
+
+var mappingsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print every element and attribute the converter maps, and to what",
+	Long: `Print every element and attribute the converter knows how to map, along with
+the Plain function it becomes, so that documentation and other tools can stay
+in sync and the mappings of two versions can be diffed.
+
+Example:
+  plainkit-converter mappings export --format json > mappings.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mappingsFormat != "json" {
+			return fmt.Errorf("unsupported format %q: only json is available", mappingsFormat)
+		}
+		export := exportMappings()
+		if mappingsOverrides != "" {
+			overrides, err := loadMappings(mappingsOverrides)
+			if err != nil {
+				return err
+			}
+			export.apply(overrides)
+		}
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode mappings: %w", err)
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	},
+}
+
+// mappingEntry describes how one element or attribute is converted. A * in
+// the name stands for any other name, so "data-*" covers every data attribute.
+type mappingEntry struct {
+	Name string `json:"name"`
+	// Element limits an attribute mapping to one element
+	Element string `json:"element,omitempty"`
+	// Context describes when the mapping applies, if not always
+	Context string `json:"context,omitempty"`
+	Func    string `json:"func"`
+	// Args lists what the function is called with: the attribute "value",
+	// nothing, or the part of the name matched by * and the value
+	Args []string `json:"args,omitempty"`
+}
+
+// mappingExport lists the mappings of every kind, sorted by name
+type mappingExport struct {
+	Version    string         `json:"version"`
+	Elements   []mappingEntry `json:"elements"`
+	Attributes []mappingEntry `json:"attributes"`
+	HTMX       []mappingEntry `json:"htmx"`
+	Alpine     []mappingEntry `json:"alpine"`
+}
+
+// exportMappings collects the mappings built into the converter
+func exportMappings() mappingExport {
+	value := []string{"value"}
+	named := func(name string) []string { return []string{name, "value"} }
+
+	var elements []mappingEntry
+	for tag, funcName := range elementFuncs {
+		elements = append(elements, mappingEntry{Name: tag, Func: funcName})
+	}
+	elements = append(elements,
+		mappingEntry{Name: "title", Context: "inside head", Func: "HeadTitle"},
+		mappingEntry{Name: "title", Context: "outside head", Func: "Title"},
+		mappingEntry{Name: "label", Context: "inside form", Func: "FormLabel"},
+		mappingEntry{Name: "label", Context: "outside form", Func: "Label"},
+		mappingEntry{Name: "*", Context: "custom and unknown elements", Func: "Element", Args: []string{"tag"}},
+	)
+
+	var attributes []mappingEntry
+	for key, funcName := range attributeFuncs {
+		attributes = append(attributes, mappingEntry{Name: key, Func: funcName, Args: value})
+	}
+	for key, funcs := range elementAttributeFuncs {
+		for tag, funcName := range funcs {
+			attributes = append(attributes, mappingEntry{Name: key, Element: tag, Func: funcName, Args: value})
+		}
+	}
+	for key, funcName := range booleanAttributeFuncs {
+		attributes = append(attributes, mappingEntry{Name: key, Func: funcName})
+	}
+	for key, funcName := range eventHandlerFuncs {
+		attributes = append(attributes, mappingEntry{Name: key, Context: "with --events", Func: funcName, Args: []string{"script"}})
+	}
+	attributes = append(attributes,
+		mappingEntry{Name: "nonce", Context: "with --strip-nonce", Func: "Nonce", Args: []string{"nonce"}},
+		mappingEntry{Name: "data-*", Func: "Data", Args: named("name")},
+		mappingEntry{Name: "aria-*", Func: "Aria", Args: named("name")},
+		mappingEntry{Name: "*", Context: "unknown attributes", Func: "Custom", Args: named("attribute")},
+	)
+
+	var htmx []mappingEntry
+	for key, funcName := range htmxAttributes {
+		entry := mappingEntry{Name: key, Func: "htmx." + funcName, Args: value}
+		if htmxBooleans[key] {
+			entry.Args = []string{"bool"}
+		}
+		htmx = append(htmx, entry)
+	}
+	htmx = append(htmx, mappingEntry{Name: "hx-*", Context: "unknown hx- attributes", Func: "Custom", Args: named("attribute")})
+
+	var alpine []mappingEntry
+	for key, funcName := range alpineAttributes {
+		entry := mappingEntry{Name: key, Func: "alpine." + funcName, Args: value}
+		if alpineFlags[key] {
+			entry.Args = nil
+		}
+		alpine = append(alpine, entry)
+	}
+	for event, funcName := range alpineEvents {
+		alpine = append(alpine, mappingEntry{Name: "@" + event, Func: "alpine." + funcName, Args: value})
+	}
+	for combo, funcName := range alpineEventCombos {
+		alpine = append(alpine, mappingEntry{Name: "@" + combo, Func: "alpine." + funcName, Args: value})
+	}
+	for attr, funcName := range alpineBinds {
+		if funcName == "Colon" {
+			continue
+		}
+		alpine = append(alpine, mappingEntry{Name: ":" + attr, Func: "alpine." + funcName, Args: value})
+	}
+	alpine = append(alpine,
+		mappingEntry{Name: "x-on:*", Func: "alpine.XOn", Args: named("event")},
+		mappingEntry{Name: "x-bind:*", Func: "alpine.XBind", Args: named("attribute")},
+		mappingEntry{Name: "x-model.debounce.*", Func: "alpine.XModelDebounce", Args: []string{"value", "delay"}},
+		mappingEntry{Name: "x-*", Context: "unknown x- attributes", Func: "Custom", Args: named("attribute")},
+		mappingEntry{Name: "@*", Context: "other events", Func: "alpine.At", Args: named("event")},
+		mappingEntry{Name: "@*.*", Context: "other events with modifiers", Func: "Custom", Args: named("attribute")},
+		mappingEntry{Name: ":*", Context: "other bindings", Func: "alpine.Colon", Args: named("attribute")},
+	)
+
+	for _, entries := range [][]mappingEntry{elements, attributes, htmx, alpine} {
+		sortMappings(entries)
+	}
+	return mappingExport{Version: version, Elements: elements, Attributes: attributes, HTMX: htmx, Alpine: alpine}
+}
+
+// apply replaces the exported mappings with the overrides, so that the export
+// shows what conversions with --mappings generate
+func (e *mappingExport) apply(overrides MappingOverrides) {
+	replace := func(entries []mappingEntry, entry mappingEntry) []mappingEntry {
+		// An override without an element replaces the mappings of every element
+		kept := entries[:0]
+		for _, m := range entries {
+			if m.Name != entry.Name || (entry.Element != "" && m.Element != entry.Element) {
+				kept = append(kept, m)
+			}
+		}
+		kept = append(kept, entry)
+		sortMappings(kept)
+		return kept
+	}
+
+	for tag, funcName := range overrides.Elements {
+		e.Elements = replace(e.Elements, mappingEntry{Name: tag, Func: funcName})
+	}
+	for key, funcName := range overrides.Attributes {
+		entry := mappingEntry{Name: key, Func: funcName, Args: []string{"value"}}
+		if m := elementAttrPattern.FindStringSubmatch(key); m != nil {
+			entry.Element, entry.Name = m[1], m[2]
+		}
+		if name, ok := strings.CutSuffix(funcName, "()"); ok {
+			entry.Func, entry.Args = name, nil
+		}
+		switch {
+		case strings.HasPrefix(entry.Name, "hx-"):
+			e.HTMX = replace(e.HTMX, entry)
+		case strings.HasPrefix(entry.Name, "x-"), strings.HasPrefix(entry.Name, "@"), strings.HasPrefix(entry.Name, ":"):
+			e.Alpine = replace(e.Alpine, entry)
+		default:
+			e.Attributes = replace(e.Attributes, entry)
+		}
+	}
+}
+
+// sortMappings orders entries by name, element and context so that exports diff cleanly
+func sortMappings(entries []mappingEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Element != b.Element {
+			return a.Element < b.Element
+		}
+		return a.Context < b.Context
+	})
+}
+
+func init() {
+	mappingsExportCmd.Flags().StringVar(&mappingsFormat, "format", "json", "Output format (json)")
+	mappingsExportCmd.Flags().StringVar(&mappingsOverrides, "mappings", "", "YAML file of mapping overrides to apply to the export")
+	mappingsCmd.AddCommand(mappingsExportCmd)
+	rootCmd.AddCommand(mappingsCmd)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExportMappings(t *testing.T) {
+	export := exportMappings()
+
+	// Every concrete mapping is what the converter actually generates
+	c := NewConverter(true, true)
+	for _, m := range export.Attributes {
+		if strings.Contains(m.Name, "*") || m.Context != "" {
+			continue
+		}
+		tag := m.Element
+		if tag == "" {
+			tag = "div"
+		}
+		code := c.convertAttribute(html.Attribute{Key: m.Name, Val: "x"}, tag)
+		if !strings.HasPrefix(code, m.Func+"(") {
+			t.Errorf("Export maps %s on %s to %s, converter generates %s", m.Name, tag, m.Func, code)
+		}
+	}
+	for _, m := range append(export.HTMX, export.Alpine...) {
+		if strings.Contains(m.Name, "*") {
+			continue
+		}
+		code := c.convertAttribute(html.Attribute{Key: m.Name, Val: "true"}, "div")
+		if !strings.HasPrefix(code, m.Func+"(") {
+			t.Errorf("Export maps %s to %s, converter generates %s", m.Name, m.Func, code)
+		}
+	}
+
+	var found bool
+	for i, m := range export.Elements {
+		if i > 0 && export.Elements[i-1].Name > m.Name {
+			t.Fatalf("Expected elements sorted by name, got %s before %s", export.Elements[i-1].Name, m.Name)
+		}
+		if m.Name == "title" && m.Context == "inside head" && m.Func == "HeadTitle" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the context-dependent title mapping to be exported")
+	}
+}
+
+func TestExportMappingOverrides(t *testing.T) {
+	export := exportMappings()
+	export.apply(MappingOverrides{
+		Elements:   map[string]string{"title": "PageTitle"},
+		Attributes: map[string]string{"type": "Kind", "inert": "Inert()"},
+	})
+
+	var titles, types []mappingEntry
+	for _, m := range export.Elements {
+		if m.Name == "title" {
+			titles = append(titles, m)
+		}
+	}
+	for _, m := range export.Attributes {
+		if m.Name == "type" {
+			types = append(types, m)
+		}
+		if m.Name == "inert" && (m.Func != "Inert" || m.Args != nil) {
+			t.Errorf("Expected inert to take no value, got %+v", m)
+		}
+	}
+	if len(titles) != 1 || titles[0].Func != "PageTitle" || titles[0].Context != "" {
+		t.Errorf("Expected the override to replace the contextual title mappings, got %+v", titles)
+	}
+	if len(types) != 1 || types[0].Func != "Kind" {
+		t.Errorf("Expected the override to replace the type mappings of every element, got %+v", types)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// pageMetaField is a field of the scraped page metadata and the tags it is read from, by priority
+type pageMetaField struct {
+	name string
+	// sources are meta properties or names, "title" for the title element
+	// and "canonical" for the canonical link
+	sources []string
+}
+
+// pageMetaFields are the fields of the generated metadata value
+var pageMetaFields = []pageMetaField{
+	{"Title", []string{"og:title", "twitter:title", "title"}},
+	{"Description", []string{"og:description", "twitter:description", "description"}},
+	{"Image", []string{"og:image", "og:image:url", "twitter:image"}},
+	{"URL", []string{"og:url", "canonical"}},
+	{"SiteName", []string{"og:site_name"}},
+	{"Type", []string{"og:type"}},
+}
+
+// collectPageMeta scrapes the title, description, OpenGraph image and other
+// SEO metadata of a full page for the generated metadata value
+func (c *Converter) collectPageMeta(nodes []*html.Node) {
+	if !c.opts.PageMeta || len(nodes) != 1 || nodes[0].Data != "html" || c.pageMeta != nil {
+		return
+	}
+	found := make(map[string]string)
+	forEachElement(nodes, func(n *html.Node) {
+		var key, val string
+		switch {
+		case n.Data == "title":
+			key, val = "title", textContent(n)
+		case n.Data == "link" && containsString(strings.Fields(strings.ToLower(attrValue(n, "rel"))), "canonical"):
+			key, val = "canonical", attrValue(n, "href")
+		case n.Data == "meta":
+			key = strings.ToLower(attrValue(n, "property"))
+			if key == "" {
+				key = strings.ToLower(attrValue(n, "name"))
+			}
+			val = attrValue(n, "content")
+		}
+		if _, ok := found[key]; key != "" && !ok {
+			found[key] = strings.TrimSpace(val)
+		}
+	})
+
+	c.pageMeta = make(map[string]string)
+	for _, field := range pageMetaFields {
+		for _, source := range field.sources {
+			if val := found[source]; val != "" {
+				c.pageMeta[field.name] = val
+				break
+			}
+		}
+	}
+	if c.pageMeta["Image"] == "" {
+		c.report(nil, SeverityInfo, "page-meta-image", "the page has no og:image; links to it are shared without a preview image")
+	}
+}
+
+// writePageMeta declares the scraped metadata of the page next to its function
+func (c *Converter) writePageMeta(buf codeWriter) {
+	if c.pageMeta == nil {
+		return
+	}
+	name := c.mainFunc.name + "Meta"
+	fmt.Fprintf(buf, "// %s is the SEO metadata of the source page of %s\n", name, c.mainFunc.name)
+	fmt.Fprintf(buf, "var %s = struct {\n", name)
+	width := 0
+	for _, field := range pageMetaFields {
+		width = max(width, len(field.name))
+	}
+	for _, field := range pageMetaFields {
+		fmt.Fprintf(buf, "\t%-*s string\n", width, field.name)
+	}
+	buf.WriteString("}{\n")
+	width = 0
+	for _, field := range pageMetaFields {
+		if c.pageMeta[field.name] != "" {
+			width = max(width, len(field.name))
+		}
+	}
+	for _, field := range pageMetaFields {
+		if val := c.pageMeta[field.name]; val != "" {
+			fmt.Fprintf(buf, "\t%-*s %s,\n", width+1, field.name+":", c.quoteValue(val))
+		}
+	}
+	buf.WriteString("}\n\n")
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// mimePart is a decoded leaf part of a MIME message
+type mimePart struct {
+	mediaType string
+	params    map[string]string
+	header    textproto.MIMEHeader
+	body      []byte
+}
+
+// extractMIMEHTML returns the text/html part of an email or MHTML archive,
+// with the images it references by cid: URL or Content-Location inlined as
+// data URLs
+func (c *Converter) extractMIMEHTML(src string) (string, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(src))
+	if err != nil {
+		return "", fmt.Errorf("failed to read MIME message: %w", err)
+	}
+	var parts []mimePart
+	if err := readMIMEParts(textproto.MIMEHeader(msg.Header), msg.Body, &parts); err != nil {
+		return "", fmt.Errorf("failed to read MIME message: %w", err)
+	}
+
+	var page *mimePart
+	for i := range parts {
+		if parts[i].mediaType == "text/html" {
+			page = &parts[i]
+			break
+		}
+	}
+	if page == nil {
+		return "", fmt.Errorf("the MIME message has no text/html part")
+	}
+	content := c.decodeCharset(page.body, page.params["charset"])
+
+	inlined := 0
+	for _, part := range parts {
+		if !strings.HasPrefix(part.mediaType, "image/") {
+			continue
+		}
+		dataURL := "data:" + part.mediaType + ";base64," + base64.StdEncoding.EncodeToString(part.body)
+		var refs []string
+		if id := strings.Trim(part.header.Get("Content-ID"), "<> "); id != "" {
+			refs = append(refs, "cid:"+id)
+		}
+		if location := part.header.Get("Content-Location"); location != "" {
+			refs = append(refs, location)
+		}
+		for _, ref := range refs {
+			// References are replaced where they are a whole attribute or url() value
+			for _, delims := range [][2]string{{`"`, `"`}, {"'", "'"}, {"(", ")"}} {
+				quoted := delims[0] + ref + delims[1]
+				if n := strings.Count(content, quoted); n > 0 {
+					content = strings.ReplaceAll(content, quoted, delims[0]+dataURL+delims[1])
+					inlined += n
+				}
+			}
+		}
+	}
+	if inlined > 0 {
+		c.report(nil, SeverityInfo, "mime-inlined", "inlined %d image reference(s) of the MIME message as data URLs", inlined)
+	}
+	return content, nil
+}
+
+// readMIMEParts appends the decoded leaf parts of a MIME entity to parts,
+// descending into multipart entities
+func readMIMEParts(header textproto.MIMEHeader, body io.Reader, parts *[]mimePart) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := readMIMEParts(part.Header, part, parts); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	*parts = append(*parts, mimePart{mediaType: mediaType, params: params, header: header, body: data})
+	return nil
+}
+
+// decodeCharset converts the body of a text part to UTF-8. Latin-1 is
+// converted; other charsets besides UTF-8 and ASCII are reported and read as UTF-8.
+func (c *Converter) decodeCharset(body []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii":
+		return string(body)
+	case "iso-8859-1", "latin1":
+		var buf strings.Builder
+		for _, b := range body {
+			buf.WriteRune(rune(b))
+		}
+		return buf.String()
+	}
+	c.report(nil, SeverityWarning, "mime-charset", "the HTML part is in %s, which is read as UTF-8", charset)
+	return string(bytes.ToValidUTF8(body, []byte("�")))
+}
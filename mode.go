@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Modes select whether the input is converted as a full page or a fragment
+const (
+	// ModeAuto detects full pages from their doctype and document tags
+	ModeAuto = "auto"
+	// ModePage converts the input as a document with a Page function
+	ModePage = "page"
+	// ModeFragment converts the input as a fragment, dropping any html,
+	// head and body tags
+	ModeFragment = "fragment"
+)
+
+// validateMode checks a mode name
+func validateMode(mode string) error {
+	switch mode {
+	case "", ModeAuto, ModePage, ModeFragment:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q (available: auto, page, fragment)", mode)
+}
+
+// isFullPage reports whether HTML is a full document: it has a doctype, an
+// html tag, or both head and body tags. Tags mentioned in text, comments,
+// attribute values or scripts do not count.
+func isFullPage(src string) bool {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var head, body bool
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return head && body
+		case html.DoctypeToken:
+			return true
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch strings.ToLower(string(name)) {
+			case "html":
+				return true
+			case "head":
+				head = true
+			case "body":
+				body = true
+			}
+		}
+	}
+}
+
+// isPage reports whether the input is converted as a full page in the configured mode
+func (c *Converter) isPage(src string) bool {
+	switch c.opts.Mode {
+	case ModePage:
+		return true
+	case ModeFragment:
+		return false
+	}
+	return isFullPage(src)
+}
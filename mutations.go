@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// documentElements are created and merged by the parser whatever the source
+// says, so they are left out of the mutation report
+var documentElements = map[string]bool{"html": true, "head": true, "body": true, "tbody": true}
+
+// sourceElement is an element as written in the source
+type sourceElement struct {
+	tag    string
+	parent string
+	line   int
+}
+
+// tokenizeElements builds a lenient view of the source: elements in source
+// order with the parent they were written in. End tags without an open
+// element are returned as stray, and elements that implicitly close an open
+// paragraph as closing.
+func tokenizeElements(src string) (elements, stray, closing []sourceElement) {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var stack []string
+	line := 1
+	parent := func() string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if !documentElements[stack[i]] {
+				return stack[i]
+			}
+		}
+		return ""
+	}
+	open := func(tag string) int {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == tag {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return elements, stray, closing
+		}
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		name, _ := z.TagName()
+		tag := strings.ToLower(string(name))
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			foreign := open("svg") >= 0 || open("math") >= 0
+			if !foreign {
+				// Mirror the end tags the parser implies, which are not mutations
+				if paragraphClosers[tag] {
+					if i := open("p"); i >= 0 {
+						closing = append(closing, sourceElement{tag: tag, parent: "p", line: tokenLine})
+						stack = stack[:i]
+					}
+				}
+				for len(stack) > 0 && containsString(impliedEnds[tag], stack[len(stack)-1]) {
+					stack = stack[:len(stack)-1]
+				}
+			}
+			if !documentElements[tag] {
+				elements = append(elements, sourceElement{tag: tag, parent: parent(), line: tokenLine})
+			}
+			if tt == html.StartTagToken && (foreign || !voidElements[tag]) {
+				stack = append(stack, tag)
+			}
+		case html.EndTagToken:
+			if i := open(tag); i >= 0 {
+				stack = stack[:i]
+			} else if !documentElements[tag] && !voidElements[tag] {
+				stray = append(stray, sourceElement{tag: tag, parent: parent(), line: tokenLine})
+			}
+		}
+	}
+}
+
+// reportMutations compares the source with the parsed tree and reports the
+// elements the HTML parser moved, inserted or dropped, and the end tags it ignored
+func (c *Converter) reportMutations(src string, nodes []*html.Node) {
+	elements, stray, closing := tokenizeElements(src)
+	for _, s := range closing {
+		c.reportLine(s.line+c.lineOffset, SeverityWarning, "parser-closed", "<%s> closed the open <%s>, so it is not inside it", s.tag, s.parent)
+	}
+	for _, s := range stray {
+		if s.tag == "p" {
+			c.reportLine(s.line+c.lineOffset, SeverityWarning, "parser-inserted", "stray </p> has no open paragraph; the parser inserted an empty <p>")
+			continue
+		}
+		c.reportLine(s.line+c.lineOffset, SeverityWarning, "parser-dropped", "stray </%s> has no open element and was ignored", s.tag)
+	}
+
+	// Pair the n-th source occurrence of each tag with its n-th parsed occurrence
+	bySource := make(map[string][]sourceElement)
+	for _, e := range elements {
+		bySource[e.tag] = append(bySource[e.tag], e)
+	}
+	byTree := make(map[string][]*html.Node)
+	var order []string
+	forEachElement(nodes, func(n *html.Node) {
+		tag := strings.ToLower(n.Data)
+		if documentElements[tag] {
+			return
+		}
+		if len(byTree[tag]) == 0 {
+			order = append(order, tag)
+		}
+		byTree[tag] = append(byTree[tag], n)
+	})
+
+	inserted := make(map[*html.Node]bool)
+	for _, tag := range order {
+		for _, n := range byTree[tag][min(len(byTree[tag]), len(bySource[tag])):] {
+			inserted[n] = true
+			// Empty paragraphs for stray </p> were reported with their line
+			if tag != "p" {
+				c.report(n, SeverityInfo, "parser-inserted", "the parser inserted a <%s> that is not in the source", tag)
+			}
+		}
+	}
+	seen := make(map[string]int)
+	for _, e := range elements {
+		i := seen[e.tag]
+		seen[e.tag]++
+		parsed := byTree[e.tag]
+		if i >= len(parsed) {
+			c.reportLine(e.line+c.lineOffset, SeverityWarning, "parser-dropped", "<%s> was dropped by the parser", e.tag)
+			continue
+		}
+		if got := parsedParent(parsed[i], inserted); got != e.parent {
+			c.reportLine(e.line+c.lineOffset, SeverityWarning, "parser-moved", "<%s> was moved from %s to %s", e.tag, describeParent(e.parent), describeParent(got))
+		}
+	}
+}
+
+// parsedParent returns the tag of the nearest ancestor that comes from the source
+func parsedParent(n *html.Node, inserted map[*html.Node]bool) string {
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if !documentElements[strings.ToLower(p.Data)] && !inserted[p] {
+			return strings.ToLower(p.Data)
+		}
+	}
+	return ""
+}
+
+// describeParent names a parent element for a message
+func describeParent(tag string) string {
+	if tag == "" {
+		return "the top level"
+	}
+	return "<" + tag + ">"
+}
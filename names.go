@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"unicode"
+	"unicode/utf8"
+)
+
+// validateNames checks the configured package and function names
+func validateNames(pkg, funcName string) error {
+	if pkg != "" && !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q", pkg)
+	}
+	if funcName != "" && !token.IsIdentifier(funcName) {
+		return fmt.Errorf("invalid function name %q", funcName)
+	}
+	return nil
+}
+
+// packageName returns the package clause name of the generated code
+func (c *Converter) packageName() string {
+	if c.opts.Package == "" {
+		return "main"
+	}
+	return c.opts.Package
+}
+
+// mainFuncName returns the name of a main function in place of the default
+// name: the configured function name if any, exported or unexported as
+// configured. Names given by directives and patches are used as they are.
+func (c *Converter) mainFuncName(name string) string {
+	if c.opts.FuncName != "" {
+		name = c.opts.FuncName
+	}
+	return withExport(name, !c.opts.Unexported)
+}
+
+// withExport changes the case of the first letter of an identifier so that
+// it is exported or not
+func withExport(name string, exported bool) string {
+	r, size := utf8.DecodeRuneInString(name)
+	if exported {
+		r = unicode.ToUpper(r)
+	} else {
+		r = unicode.ToLower(r)
+	}
+	name = string(r) + name[size:]
+	if goKeywords[name] {
+		name += "_"
+	}
+	return name
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// hxVerbs maps the htmx request attributes to their methods
+var hxVerbs = []struct{ attr, method string }{
+	{"hx-get", "GET"},
+	{"hx-post", "POST"},
+	{"hx-put", "PUT"},
+	{"hx-patch", "PATCH"},
+	{"hx-delete", "DELETE"},
+}
+
+// HxRequest is a request sent by an element of a converted page through its
+// htmx attributes
+type HxRequest struct {
+	Method string `json:"method"`
+	// URL is the value of the request attribute, empty for the page itself
+	URL string `json:"url"`
+	// PushURL is the hx-push-url value in effect on the element, inherited
+	// from its ancestors: "true" pushes the request URL into the history,
+	// empty when nothing is pushed
+	PushURL string `json:"pushUrl,omitempty"`
+	// Node is a selector-like path to the element sending the request
+	Node string `json:"node"`
+}
+
+// HxRequests returns the htmx requests of the last converted input
+func (c *Converter) HxRequests() []HxRequest {
+	return c.hxRequests
+}
+
+// collectHxRequests records the htmx requests of the input for HxRequests
+func (c *Converter) collectHxRequests(nodes []*html.Node) {
+	c.hxRequests = nil
+	forEachElement(nodes, func(n *html.Node) {
+		for _, verb := range hxVerbs {
+			if !hasAttr(n, verb.attr) {
+				continue
+			}
+			c.hxRequests = append(c.hxRequests, HxRequest{
+				Method:  verb.method,
+				URL:     strings.TrimSpace(attrValue(n, verb.attr)),
+				PushURL: pushURL(n),
+				Node:    nodePath(n),
+			})
+		}
+	})
+}
+
+// pushURL returns the hx-push-url value of an element or of its closest
+// ancestor setting one that its descendants inherit, empty for "false"
+func pushURL(n *html.Node) string {
+	for p := n; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if p != n && disinherits(p, "hx-push-url") {
+			return ""
+		}
+		if hasAttr(p, "hx-push-url") {
+			if value := strings.TrimSpace(attrValue(p, "hx-push-url")); value != "false" {
+				return value
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// disinherits reports whether an element keeps its descendants from
+// inheriting an htmx attribute through hx-disinherit
+func disinherits(n *html.Node, attr string) bool {
+	for _, name := range strings.Fields(attrValue(n, "hx-disinherit")) {
+		if name == "*" || name == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// navNode is a path of the navigation graph: a converted page or an
+// endpoint its htmx requests are sent to
+type navNode struct {
+	ID string `json:"id"`
+	// Component is the function rendering the page, empty for endpoints
+	Component string `json:"component,omitempty"`
+}
+
+// navEdge is a request of a page to an endpoint, or a URL it pushes into the
+// browser history
+type navEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Kind is "request" or "push"
+	Kind   string `json:"kind"`
+	Method string `json:"method,omitempty"`
+}
+
+// hxTarget resolves the URL of a request sent from the page at page to the
+// path it is sent to. URLs of other sites are kept whole.
+func hxTarget(page, rawURL string) string {
+	if rawURL == "" {
+		return routePath(page)
+	}
+	if u, err := url.Parse(rawURL); err == nil && (u.Scheme != "" || u.Host != "") {
+		return rawURL
+	}
+	return routePath(linkTarget(page, rawURL))
+}
+
+// buildNavGraph collects the pages of a batch and the endpoints their htmx
+// requests are sent to, linked by the requests and the URLs they push
+func buildNavGraph(components []convertedComponent) ([]navNode, []navEdge) {
+	nodes := make(map[string]*navNode)
+	node := func(id string) *navNode {
+		n, ok := nodes[id]
+		if !ok {
+			n = &navNode{ID: id}
+			nodes[id] = n
+		}
+		return n
+	}
+	seen := make(map[navEdge]bool)
+	edges := []navEdge{}
+	addEdge := func(e navEdge) {
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+
+	for _, comp := range components {
+		page := pagePath(comp)
+		from := routePath(page)
+		node(from).Component = comp.Func.Name
+		for _, req := range comp.Requests {
+			to := hxTarget(page, req.URL)
+			node(to)
+			addEdge(navEdge{From: from, To: to, Kind: "request", Method: req.Method})
+			switch req.PushURL {
+			case "":
+			case "true":
+				addEdge(navEdge{From: from, To: to, Kind: "push"})
+			default:
+				pushed := hxTarget(page, req.PushURL)
+				node(pushed)
+				addEdge(navEdge{From: from, To: pushed, Kind: "push"})
+			}
+		}
+	}
+
+	graphNodes := make([]navNode, 0, len(nodes))
+	for _, id := range sortedKeys(nodes) {
+		graphNodes = append(graphNodes, *nodes[id])
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.Method < b.Method
+	})
+	return graphNodes, edges
+}
+
+// navGraphDOT renders the navigation graph in the DOT language of Graphviz:
+// pages are boxes, endpoints ellipses and pushed URLs dashed edges
+func navGraphDOT(nodes []navNode, edges []navEdge) string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph navigation {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	for _, n := range nodes {
+		if n.Component != "" {
+			fmt.Fprintf(&buf, "\t%s [shape=box, label=%s];\n", strconv.Quote(n.ID), strconv.Quote(n.ID+"\n"+n.Component))
+		} else {
+			fmt.Fprintf(&buf, "\t%s [shape=ellipse];\n", strconv.Quote(n.ID))
+		}
+	}
+	for _, e := range edges {
+		if e.Kind == "push" {
+			fmt.Fprintf(&buf, "\t%s -> %s [label=\"push\", style=dashed];\n", strconv.Quote(e.From), strconv.Quote(e.To))
+		} else {
+			fmt.Fprintf(&buf, "\t%s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Method))
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// writeNavGraph writes the navigation graph, in DOT when the file name ends
+// in .dot or .gv and as JSON otherwise
+func writeNavGraph(filename string, components []convertedComponent) error {
+	nodes, edges := buildNavGraph(components)
+	var data []byte
+	switch filepath.Ext(filename) {
+	case ".dot", ".gv":
+		data = []byte(navGraphDOT(nodes, edges))
+	default:
+		encoded, err := json.MarshalIndent(struct {
+			Nodes []navNode `json:"nodes"`
+			Edges []navEdge `json:"edges"`
+		}{nodes, edges}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode navigation graph: %w", err)
+		}
+		data = append(encoded, '\n')
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write navigation graph: %w", err)
+	}
+	return nil
+}
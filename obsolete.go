@@ -0,0 +1,45 @@
+package main
+
+import "golang.org/x/net/html"
+
+// obsoleteElements maps obsolete HTML elements to their modern replacements
+var obsoleteElements = map[string]string{
+	"acronym":   "use abbr",
+	"applet":    "use object or embed",
+	"basefont":  "use CSS font properties",
+	"bgsound":   "use audio",
+	"big":       "use CSS font-size",
+	"blink":     "use a CSS animation",
+	"center":    "use CSS text-align or margin: auto",
+	"dir":       "use ul",
+	"font":      "use CSS font properties on a span",
+	"frame":     "use iframe or CSS layout",
+	"frameset":  "use iframe or CSS layout",
+	"noframes":  "remove it together with the frameset",
+	"isindex":   "use a form with a search input",
+	"keygen":    "use the Web Crypto API",
+	"listing":   "use pre with escaped code",
+	"marquee":   "use a CSS animation",
+	"menuitem":  "use button inside menu",
+	"multicol":  "use CSS columns",
+	"nextid":    "remove it",
+	"nobr":      "use CSS white-space: nowrap",
+	"noembed":   "use fallback content inside object",
+	"plaintext": "use pre with escaped text",
+	"rb":        "put the base text directly in ruby",
+	"rtc":       "use rt",
+	"spacer":    "use CSS margin or padding",
+	"strike":    "use s or del",
+	"tt":        "use code, kbd or samp",
+	"xmp":       "use pre with escaped text",
+}
+
+// checkObsolete reports obsolete elements. They are converted through the
+// Element fallback since Plain has no constructors for them.
+func (c *Converter) checkObsolete(nodes []*html.Node) {
+	forEachElement(nodes, func(n *html.Node) {
+		if replacement, ok := obsoleteElements[n.Data]; ok {
+			c.report(n, SeverityWarning, "obsolete-element", "<%s> is obsolete; %s", n.Data, replacement)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MappingOverrides add to or replace the built-in element and attribute
+// mappings, for example to use functions of a newer plainkit/html
+type MappingOverrides struct {
+	// Elements maps tags to constructors, e.g. search: Search
+	Elements map[string]string `yaml:"elements"`
+	// Attributes maps attributes to functions taking their value, e.g.
+	// popover: Popover. A function written as Inert() takes no value, and a
+	// key of the form input[list] only applies to that element.
+	Attributes map[string]string `yaml:"attributes"`
+}
+
+var (
+	// mappingFuncPattern matches a function, optionally qualified by its package
+	mappingFuncPattern = regexp.MustCompile(`^(?:[A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*(?:\(\))?$`)
+	// elementAttrPattern matches an attribute key limited to an element, e.g. input[list]
+	elementAttrPattern = regexp.MustCompile(`^([^\[\]]+)\[([^\[\]]+)\]$`)
+)
+
+// loadMappings reads a YAML file of mapping overrides
+func loadMappings(path string) (MappingOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MappingOverrides{}, fmt.Errorf("failed to read mappings: %w", err)
+	}
+	var overrides MappingOverrides
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return MappingOverrides{}, fmt.Errorf("failed to parse mappings %s: %w", path, err)
+	}
+	if err := overrides.normalize(); err != nil {
+		return MappingOverrides{}, fmt.Errorf("mappings %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// normalize lowercases the keys, as the parser does with tag and attribute
+// names, and checks that every mapping names a function
+func (m *MappingOverrides) normalize() error {
+	normalized := func(kind string, mappings map[string]string) (map[string]string, error) {
+		out := make(map[string]string, len(mappings))
+		for key, funcName := range mappings {
+			funcName = strings.TrimSpace(funcName)
+			if !mappingFuncPattern.MatchString(funcName) {
+				return nil, fmt.Errorf("%s %s: %q is not a function name", kind, key, funcName)
+			}
+			out[strings.ToLower(strings.TrimSpace(key))] = funcName
+		}
+		return out, nil
+	}
+
+	var err error
+	if m.Elements, err = normalized("element", m.Elements); err != nil {
+		return err
+	}
+	for tag, funcName := range m.Elements {
+		if strings.HasSuffix(funcName, "()") {
+			return fmt.Errorf("element %s: constructor %s cannot be written with ()", tag, funcName)
+		}
+	}
+	m.Attributes, err = normalized("attribute", m.Attributes)
+	return err
+}
+
+// elementOverride returns the constructor configured for a tag
+func (c *Converter) elementOverride(tag string) (string, bool) {
+	funcName, ok := c.opts.Mappings.Elements[tag]
+	if ok {
+		c.useMappingQualifier(funcName)
+	}
+	return funcName, ok
+}
+
+// attributeOverride converts an attribute with the function configured for
+// it on this element or, failing that, on any element
+func (c *Converter) attributeOverride(key, val, tagName string) (string, bool) {
+	funcName, ok := c.opts.Mappings.Attributes[tagName+"["+key+"]"]
+	if !ok {
+		funcName, ok = c.opts.Mappings.Attributes[key]
+	}
+	if !ok {
+		return "", false
+	}
+	c.useMappingQualifier(funcName)
+	if strings.HasSuffix(funcName, "()") {
+		return funcName, true
+	}
+	return fmt.Sprintf("%s(%s)", funcName, c.attrValue(key, val)), true
+}
+
+// useMappingQualifier marks the package of a qualified override function as imported
+func (c *Converter) useMappingQualifier(funcName string) {
+	m := qualifierPattern.FindStringSubmatch(funcName)
+	if m == nil {
+		return
+	}
+	switch m[1] {
+	case "htmx":
+		c.imports["github.com/plainkit/htmx"] = true
+	case "alpine":
+		c.imports["github.com/plainkit/alpine"] = true
+	default:
+		c.useQualifier(funcName)
+	}
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// pagination is a pagination control whose numbered page links are generated
+// by a helper taking the current page and the page count
+type pagination struct {
+	container *html.Node
+	// list is the parent of the page items
+	list  *html.Node
+	items []pageItem
+	// href is the fmt pattern of the page links, empty when they do not
+	// carry the page number
+	href           string
+	current, total int
+}
+
+// pageItem is the child of a pagination list showing one page number
+type pageItem struct {
+	node   *html.Node
+	number int
+	text   *html.Node
+	// link is the a element of the item, nil when the page is not linked
+	link    *html.Node
+	current bool
+}
+
+// pageEnd is a previous or next link of a pagination control, rendered only
+// while there is a page in its direction
+type pageEnd struct {
+	link *html.Node
+	// node is the link, or the list item holding nothing but the link
+	node *html.Node
+	// name is the variable holding the link in the helper, shown when cond
+	// holds and linking to the page expression page
+	name, cond, page string
+}
+
+// breadcrumb is a breadcrumb trail generated by a helper from its labels and links
+type breadcrumb struct {
+	container *html.Node
+	list      *html.Node
+	items     []*html.Node
+}
+
+// currentClasses are classes marking the current page or breadcrumb
+var currentClasses = []string{"active", "current", "is-active", "is-current", "selected"}
+
+// substitution replaces a text or attribute value of the tree with a Go
+// expression while converting
+type substitution struct {
+	value *string
+	expr  string
+}
+
+// findNavigation finds pagination controls and breadcrumb trails, whose page
+// numbers and links are parameters of a helper in parameterize mode
+func (c *Converter) findNavigation(nodes []*html.Node) {
+	c.paginations = make(map[*html.Node]*pagination)
+	c.breadcrumbs = make(map[*html.Node]*breadcrumb)
+	if !c.opts.Parameterize {
+		return
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if isBreadcrumbContainer(n) {
+				if b, ok := parseBreadcrumb(n); ok {
+					c.breadcrumbs[n] = b
+					return
+				}
+			}
+			if isPaginationContainer(n) {
+				if p, ok := parsePagination(n); ok {
+					c.paginations[n] = p
+					return
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+// navigationLabel returns the aria-label and class of n, lower-cased
+func navigationLabel(n *html.Node) string {
+	return strings.ToLower(attrValue(n, "aria-label") + " " + attrValue(n, "class"))
+}
+
+// isBreadcrumbContainer reports whether n is labelled or styled as a breadcrumb
+func isBreadcrumbContainer(n *html.Node) bool {
+	return strings.Contains(navigationLabel(n), "breadcrumb")
+}
+
+// isPaginationContainer reports whether n is labelled or styled as a
+// pagination control, or is a nav holding previous and next links
+func isPaginationContainer(n *html.Node) bool {
+	label := navigationLabel(n)
+	if strings.Contains(label, "pagination") || strings.Contains(label, "pager") {
+		return true
+	}
+	if n.Data != "nav" {
+		return false
+	}
+	found := false
+	forEachElement([]*html.Node{n}, func(a *html.Node) {
+		found = found || (a.Data == "a" && pageDirection(a) != "")
+	})
+	return found
+}
+
+// pageDirection returns "prev" or "next" for links to the neighbouring pages,
+// recognised by their rel, or by their aria-label or text such as "Previous",
+// "Next page" or "»"
+func pageDirection(a *html.Node) string {
+	if rel := relLink(a); rel != "" {
+		return rel
+	}
+	label := strings.ToLower(strings.TrimSpace(attrValue(a, "aria-label")))
+	if label == "" {
+		label = strings.ToLower(strings.TrimSpace(textContent(a)))
+	}
+	switch word := strings.TrimSpace(strings.Trim(label, "«‹←»›→<>")); {
+	case strings.HasPrefix(word, "prev"):
+		return "prev"
+	case strings.HasPrefix(word, "next"):
+		return "next"
+	case word == "" && strings.ContainsAny(label, "«‹←"):
+		return "prev"
+	case word == "" && strings.ContainsAny(label, "»›→"):
+		return "next"
+	}
+	return ""
+}
+
+// relLink returns "prev" or "next" for links to the neighbouring pages
+func relLink(a *html.Node) string {
+	for _, rel := range strings.Fields(strings.ToLower(attrValue(a, "rel"))) {
+		switch rel {
+		case "prev", "previous":
+			return "prev"
+		case "next":
+			return "next"
+		}
+	}
+	return ""
+}
+
+// isCurrent reports whether n is marked as the current page
+func isCurrent(n *html.Node) bool {
+	if hasAttr(n, "aria-current") {
+		return true
+	}
+	for _, class := range strings.Fields(attrValue(n, "class")) {
+		if slices.Contains(currentClasses, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePagination finds the numbered page items of a pagination control
+func parsePagination(container *html.Node) (*pagination, bool) {
+	var texts []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch child.Type {
+			case html.TextNode:
+				if _, err := strconv.Atoi(strings.TrimSpace(child.Data)); err == nil {
+					texts = append(texts, child)
+				}
+			case html.ElementNode:
+				walk(child)
+			}
+		}
+	}
+	walk(container)
+	if len(texts) < 2 {
+		return nil, false
+	}
+
+	// The items are the children of the closest element holding two page numbers
+	p := &pagination{container: container, list: commonAncestor(texts[0], texts[1])}
+	for _, text := range texts {
+		item := pageItem{text: text}
+		item.number, _ = strconv.Atoi(strings.TrimSpace(text.Data))
+		for n := text.Parent; n != nil; n = n.Parent {
+			if n.Data == "a" && item.link == nil {
+				item.link = n
+			}
+			item.current = item.current || isCurrent(n)
+			if n.Parent == p.list {
+				item.node = n
+				break
+			}
+			if n == container {
+				return nil, false
+			}
+		}
+		if item.node == nil || slices.ContainsFunc(p.items, func(other pageItem) bool { return other.node == item.node }) {
+			return nil, false
+		}
+		item.current = item.current || item.link == nil || !hasAttr(item.link, "href")
+		if item.current {
+			p.current = item.number
+		}
+		p.total = max(p.total, item.number)
+		p.items = append(p.items, item)
+	}
+	if slices.IndexFunc(p.items, func(item pageItem) bool { return !item.current }) < 0 {
+		return nil, false
+	}
+	if p.current == 0 {
+		p.current = 1
+	}
+
+	// Prefer a link past the first page, which is often linked without a number
+	for _, item := range p.items {
+		if item.link == nil || item.current {
+			continue
+		}
+		href := attrValue(item.link, "href")
+		number := strconv.Itoa(item.number)
+		if i := strings.LastIndex(href, number); i >= 0 {
+			p.href = strings.ReplaceAll(href[:i], "%", "%%") + "%d" + strings.ReplaceAll(href[i+len(number):], "%", "%%")
+			if item.number > 1 {
+				break
+			}
+		}
+	}
+	return p, true
+}
+
+// commonAncestor returns the closest element containing both a and b
+func commonAncestor(a, b *html.Node) *html.Node {
+	ancestors := make(map[*html.Node]bool)
+	for n := a.Parent; n != nil; n = n.Parent {
+		ancestors[n] = true
+	}
+	for n := b.Parent; n != nil; n = n.Parent {
+		if ancestors[n] {
+			return n
+		}
+	}
+	return nil
+}
+
+// parseBreadcrumb finds the items of a breadcrumb trail: every item but the
+// last has to link to its page
+func parseBreadcrumb(container *html.Node) (*breadcrumb, bool) {
+	b := &breadcrumb{container: container, list: container}
+	forEachElement([]*html.Node{container}, func(n *html.Node) {
+		if b.list == container && (n.Data == "ol" || n.Data == "ul") {
+			b.list = n
+		}
+	})
+	for child := b.list.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			b.items = append(b.items, child)
+		}
+	}
+	if len(b.items) < 2 {
+		return nil, false
+	}
+	for _, item := range b.items[:len(b.items)-1] {
+		if link := descendant(item, "a"); link == nil || !hasAttr(link, "href") || crumbLabel(item) == nil {
+			return nil, false
+		}
+	}
+	if crumbLabel(b.items[len(b.items)-1]) == nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// descendant returns the first element named tag in n, including n itself
+func descendant(n *html.Node, tag string) *html.Node {
+	var found *html.Node
+	forEachElement([]*html.Node{n}, func(el *html.Node) {
+		if found == nil && el.Data == tag {
+			found = el
+		}
+	})
+	return found
+}
+
+// crumbLabel returns the last non-blank text node of a breadcrumb item, its label
+func crumbLabel(n *html.Node) *html.Node {
+	var label *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode && strings.TrimSpace(child.Data) != "" {
+				label = child
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return label
+}
+
+// substitute runs convert with the substituted values replaced by their expressions
+func (c *Converter) substitute(subs []substitution, convert func() string) string {
+	saved := make([]string, len(subs))
+	for i, s := range subs {
+		saved[i] = *s.value
+		*s.value = fmt.Sprintf("\x00plainkit-substitution-%d\x00", i)
+	}
+	code := convert()
+	for i, s := range subs {
+		code = strings.ReplaceAll(code, c.quoteValue(*s.value), s.expr)
+		*s.value = saved[i]
+	}
+	return code
+}
+
+// attrRef returns a pointer to the value of an attribute of n, or nil
+func attrRef(n *html.Node, key string) *string {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			return &n.Attr[i].Val
+		}
+	}
+	return nil
+}
+
+// withItems converts the container of a list whose children from first to
+// last are replaced by the nodes slice of a generated helper
+func (c *Converter) withItems(container, list, first, last *html.Node, slice string) string {
+	var removed []*html.Node
+	for n := first; ; n = n.NextSibling {
+		removed = append(removed, n)
+		if n == last {
+			break
+		}
+	}
+	next := last.NextSibling
+	for _, n := range removed {
+		list.RemoveChild(n)
+	}
+	marker := &html.Node{Type: html.TextNode, Data: "\x00plainkit-items\x00"}
+	list.InsertBefore(marker, next)
+
+	code := c.convertElement(container, 1)
+
+	list.RemoveChild(marker)
+	for _, n := range removed {
+		list.InsertBefore(n, next)
+	}
+	return strings.Replace(code, "T("+c.quoteValue(marker.Data)+")", "Fragment("+slice+"...)", 1)
+}
+
+// paginationCall returns the call of the Pagination helper, declaring it on first use
+func (c *Converter) paginationCall(p *pagination) string {
+	if c.paginationFunc == nil {
+		c.paginationFunc = &funcDecl{name: c.uniqueFuncName("Pagination"), result: "Node"}
+		c.paginationFunc.addParam("current", "int")
+		c.paginationFunc.addParam("total", "int")
+		c.funcs = append(c.funcs, c.paginationFunc)
+		c.paginationFunc.raw = c.paginationHelper(p)
+		c.report(p.container, SeverityInfo, "pagination-helper", "pagination generated by %s from %d page links", c.paginationFunc.name, len(p.items))
+	}
+	return fmt.Sprintf("%s(%s, %s)", c.paginationFunc.name, c.param("currentPage", "int"), c.param("totalPages", "int"))
+}
+
+// paginationHelper returns the declaration of the Pagination helper, which
+// repeats the markup of the page items for every page
+func (c *Converter) paginationHelper(p *pagination) string {
+	parent := c.scope
+	c.scope = c.paginationFunc
+	defer func() { c.scope = parent }()
+
+	c.stdImports["strconv"] = true
+	pageHref := func(link *html.Node, expr string) []substitution {
+		if link == nil || p.href == "" {
+			return nil
+		}
+		if href := attrRef(link, "href"); href != nil {
+			c.stdImports["fmt"] = true
+			return []substitution{{value: href, expr: fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(p.href), expr)}}
+		}
+		return nil
+	}
+	page := func(item pageItem, depth int) string {
+		subs := append([]substitution{{value: &item.text.Data, expr: "strconv.Itoa(page)"}}, pageHref(item.link, "page")...)
+		return c.substitute(subs, func() string { return c.convertNode(item.node, depth) })
+	}
+
+	linked := p.items[slices.IndexFunc(p.items, func(item pageItem) bool { return !item.current })]
+	current := linked
+	if i := slices.IndexFunc(p.items, func(item pageItem) bool { return item.current }); i >= 0 {
+		current = p.items[i]
+	}
+
+	// Previous and next links point next to the current page, and are left
+	// out on the first and last pages
+	ends := paginationEnds(p)
+	container := c.withEnds(ends, func() string {
+		return c.withItems(p.container, p.list, p.items[0].node, p.items[len(p.items)-1].node, "pages")
+	})
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// %s links to the pages from 1 to total, marking the current one\n", c.paginationFunc.name)
+	fmt.Fprintf(&buf, "func %s(%s) Node {\n", c.paginationFunc.name, c.paginationFunc.signature())
+	buf.WriteString("\tpages := make([]Node, 0, total)\n")
+	buf.WriteString("\tfor page := 1; page <= total; page++ {\n")
+	buf.WriteString("\t\tif page == current {\n")
+	fmt.Fprintf(&buf, "\t\t\tpages = append(pages, %s)\n", page(current, 3))
+	buf.WriteString("\t\t\tcontinue\n\t\t}\n")
+	fmt.Fprintf(&buf, "\t\tpages = append(pages, %s)\n", page(linked, 2))
+	buf.WriteString("\t}\n")
+	for _, end := range ends {
+		link := c.substitute(pageHref(end.link, end.page), func() string { return c.convertNode(end.node, 2) })
+		fmt.Fprintf(&buf, "\tvar %s Node = Fragment()\n", end.name)
+		fmt.Fprintf(&buf, "\tif %s {\n\t\t%s = %s\n\t}\n", end.cond, end.name, link)
+	}
+	fmt.Fprintf(&buf, "\treturn %s\n}\n", container)
+	return buf.String()
+}
+
+// paginationEnds returns the previous and next links of a pagination control
+// whose page links carry the page number
+func paginationEnds(p *pagination) []pageEnd {
+	if p.href == "" {
+		return nil
+	}
+	var ends []pageEnd
+	names := make(map[string]int)
+	forEachElement([]*html.Node{p.container}, func(a *html.Node) {
+		if a.Data != "a" || !hasAttr(a, "href") || slices.ContainsFunc(p.items, func(item pageItem) bool { return item.link == a }) {
+			return
+		}
+		end := pageEnd{link: a, node: a}
+		switch pageDirection(a) {
+		case "prev":
+			end.name, end.cond, end.page = "prev", "current > 1", "current-1"
+		case "next":
+			end.name, end.cond, end.page = "next", "current < total", "current+1"
+		default:
+			return
+		}
+		if names[end.name]++; names[end.name] > 1 {
+			end.name += strconv.Itoa(names[end.name])
+		}
+		if parent := a.Parent; parent != nil && parent.Data == "li" && onlyElementChild(parent) == a && strings.TrimSpace(textContent(parent)) == strings.TrimSpace(textContent(a)) {
+			end.node = parent
+		}
+		ends = append(ends, end)
+	})
+	return ends
+}
+
+// onlyElementChild returns the single element child of n, or nil
+func onlyElementChild(n *html.Node) *html.Node {
+	var only *html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if only != nil {
+			return nil
+		}
+		only = child
+	}
+	return only
+}
+
+// withEnds runs convert with the previous and next links replaced by the
+// variables holding them
+func (c *Converter) withEnds(ends []pageEnd, convert func() string) string {
+	markers := make([]*html.Node, len(ends))
+	for i, end := range ends {
+		markers[i] = &html.Node{Type: html.TextNode, Data: fmt.Sprintf("\x00plainkit-page-end-%d\x00", i)}
+		end.node.Parent.InsertBefore(markers[i], end.node)
+		end.node.Parent.RemoveChild(end.node)
+	}
+	code := convert()
+	for i, end := range ends {
+		markers[i].Parent.InsertBefore(end.node, markers[i])
+		markers[i].Parent.RemoveChild(markers[i])
+		code = strings.Replace(code, "T("+c.quoteValue(markers[i].Data)+")", end.name, 1)
+	}
+	return code
+}
+
+// breadcrumbCall returns the call of the Breadcrumb helper with the items of
+// the trail, declaring the helper on first use
+func (c *Converter) breadcrumbCall(b *breadcrumb, depth int) string {
+	if c.breadcrumbFunc == nil {
+		c.breadcrumbFunc = &funcDecl{name: c.uniqueFuncName("Breadcrumb"), result: "Node"}
+		c.breadcrumbFunc.addParam("items", "[]"+c.breadcrumbFunc.name+"Item")
+		c.funcs = append(c.funcs, c.breadcrumbFunc)
+		c.breadcrumbFunc.raw = c.breadcrumbHelper(b)
+		c.report(b.container, SeverityInfo, "breadcrumb-helper", "breadcrumb generated by %s from %d items", c.breadcrumbFunc.name, len(b.items))
+	}
+	itemType := c.breadcrumbFunc.name + "Item"
+
+	indent := strings.Repeat("\t", depth+1)
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s([]%s{\n", c.breadcrumbFunc.name, itemType)
+	for _, item := range b.items {
+		fields := []string{"Label: " + c.quoteValue(strings.TrimSpace(crumbLabel(item).Data))}
+		if link := descendant(item, "a"); link != nil && hasAttr(link, "href") {
+			fields = append(fields, "Href: "+c.quoteValue(attrValue(link, "href")))
+		}
+		fmt.Fprintf(&buf, "%s{%s},\n", indent, strings.Join(fields, ", "))
+	}
+	fmt.Fprintf(&buf, "%s})", strings.Repeat("\t", depth))
+	return buf.String()
+}
+
+// breadcrumbHelper returns the declarations of the Breadcrumb helper and its
+// item type, which repeat the markup of a linked item and of the last item
+func (c *Converter) breadcrumbHelper(b *breadcrumb) string {
+	parent := c.scope
+	c.scope = c.breadcrumbFunc
+	defer func() { c.scope = parent }()
+
+	crumb := func(item *html.Node, depth int) string {
+		subs := []substitution{{value: &crumbLabel(item).Data, expr: "item.Label"}}
+		if link := descendant(item, "a"); link != nil {
+			if href := attrRef(link, "href"); href != nil {
+				subs = append(subs, substitution{value: href, expr: "item.Href"})
+			}
+		}
+		return c.substitute(subs, func() string { return c.convertNode(item, depth) })
+	}
+
+	name := c.breadcrumbFunc.name
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// %sItem is an entry of %s, linking to Href\n", name, name)
+	fmt.Fprintf(&buf, "type %sItem struct {\n\tLabel string\n\tHref  string\n}\n\n", name)
+	fmt.Fprintf(&buf, "// %s renders the trail of items, the last one being the current page\n", name)
+	fmt.Fprintf(&buf, "func %s(%s) Node {\n", name, c.breadcrumbFunc.signature())
+	buf.WriteString("\tcrumbs := make([]Node, 0, len(items))\n")
+	buf.WriteString("\tfor i, item := range items {\n")
+	buf.WriteString("\t\tif i == len(items)-1 {\n")
+	fmt.Fprintf(&buf, "\t\t\tcrumbs = append(crumbs, %s)\n", crumb(b.items[len(b.items)-1], 3))
+	buf.WriteString("\t\t\tcontinue\n\t\t}\n")
+	fmt.Fprintf(&buf, "\t\tcrumbs = append(crumbs, %s)\n", crumb(b.items[0], 2))
+	buf.WriteString("\t}\n")
+	fmt.Fprintf(&buf, "\treturn %s\n}\n", c.withItems(b.container, b.list, b.items[0], b.items[len(b.items)-1], "crumbs"))
+	return buf.String()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// metaParams maps meta names and properties to the page parameter carrying their content
+var metaParams = map[string]string{
+	"description":         "description",
+	"og:description":      "description",
+	"twitter:description": "description",
+	"og:title":            "title",
+	"twitter:title":       "title",
+}
+
+// Parameter naming strategies for values extracted in parameterize mode
+const (
+	// ParamNamesMerge passes values of the same name, such as the title and
+	// og:title, through one parameter
+	ParamNamesMerge = "merge"
+	// ParamNamesUnique gives values of the same name that differ numbered
+	// parameters, such as title and title2, while equal values share one
+	ParamNamesUnique = "unique"
+	// ParamNamesSource names parameters after where their value comes from,
+	// such as title, ogTitle and twitterDescription
+	ParamNamesSource = "source"
+)
+
+// validateParamNames checks a parameter naming strategy
+func validateParamNames(strategy string) error {
+	switch strategy {
+	case "", ParamNamesMerge, ParamNamesUnique, ParamNamesSource:
+		return nil
+	}
+	return fmt.Errorf("unknown parameter naming %q (available: merge, unique, source)", strategy)
+}
+
+// param declares a parameter on the function being generated and returns its name
+func (c *Converter) param(name, typ string) string {
+	if c.scope != nil {
+		c.scope.addParam(name, typ)
+	}
+	return name
+}
+
+// valueParam declares a string parameter for a value extracted from the
+// source and returns its name. Unless values are merged, the name is base, or
+// derived from source with the source strategy, followed by a number when a
+// different value already took it, so that names stay unique and only depend
+// on the order of the values.
+func (c *Converter) valueParam(base, source, value string) string {
+	switch c.opts.ParamNames {
+	case "", ParamNamesMerge:
+		return c.param(base, "string")
+	case ParamNamesSource:
+		base = goIdentifier(source, false)
+	}
+	name := base
+	for i := 2; ; i++ {
+		prev, taken := c.paramValues[name]
+		if !taken {
+			break
+		}
+		if prev == value {
+			return c.param(name, "string")
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	c.paramValues[name] = value
+	return c.param(name, "string")
+}
+
+// paramText returns the parameter replacing a text node in parameterize mode
+func (c *Converter) paramText(n *html.Node) (string, bool) {
+	if !c.opts.Parameterize || n.Parent == nil || n.Parent.Type != html.ElementNode {
+		return "", false
+	}
+	if n.Parent.Data == "title" && c.isInHeadContext(n.Parent) {
+		return fmt.Sprintf("T(%s)", c.valueParam("title", "title", strings.TrimSpace(n.Data))), true
+	}
+	return "", false
+}
+
+// paramAttribute returns the code replacing an attribute with a parameter in parameterize mode
+func (c *Converter) paramAttribute(n *html.Node, attr html.Attribute) (string, bool) {
+	if !c.opts.Parameterize {
+		return "", false
+	}
+	if n.Data == "meta" && attr.Key == "content" {
+		key := strings.ToLower(attrValue(n, "name"))
+		if key == "" {
+			key = strings.ToLower(attrValue(n, "property"))
+		}
+		if name, ok := metaParams[key]; ok {
+			return fmt.Sprintf("Content(%s)", c.valueParam(name, key, attr.Val)), true
+		}
+	}
+	return "", false
+}
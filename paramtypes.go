@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// countAttrs are attributes holding counts and dimensions, which become int
+// parameters when their saved value is a number
+var countAttrs = map[string]bool{
+	"width": true, "height": true, "colspan": true, "rowspan": true, "span": true,
+	"rows": true, "cols": true, "size": true, "maxlength": true, "minlength": true,
+	"start": true, "tabindex": true,
+}
+
+// urlAttrs are attributes holding a URL, whose parameters are named as such
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true,
+	"poster": true, "cite": true, "data": true,
+}
+
+// paramExpr declares the parameter replacing the value of the attribute key,
+// or the text of an element when key is empty, and returns the string
+// expression passing it on. In parameterize mode the type of the parameter
+// is inferred from the context and the saved value: numeric counts and
+// dimensions, including data attributes and text, are ints, true/false data
+// attributes are bools, and URL parameters get a URL suffix.
+func (c *Converter) paramExpr(name, key, value string) string {
+	name = goIdentifier(name, false)
+	if !c.opts.Parameterize {
+		return c.param(name, "string")
+	}
+
+	value = strings.TrimSpace(value)
+	data := strings.HasPrefix(key, "data-")
+	switch {
+	case isInteger(value) && (countAttrs[key] || data || key == ""):
+		c.stdImports["strconv"] = true
+		return fmt.Sprintf("strconv.Itoa(%s)", c.param(name, "int"))
+	case data && (value == "true" || value == "false"):
+		c.stdImports["strconv"] = true
+		return fmt.Sprintf("strconv.FormatBool(%s)", c.param(name, "bool"))
+	case urlAttrs[key] || (data && (strings.HasSuffix(key, "-url") || strings.HasSuffix(key, "-href"))):
+		if !strings.HasSuffix(strings.ToLower(name), "url") {
+			name += "URL"
+		}
+	}
+	return c.param(name, "string")
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// patchParamPlaceholder stands in for a patched attribute value while the attribute is converted
+const patchParamPlaceholder = "\x00plainkit-patch-param\x00"
+
+// Patch overrides the generated code for the elements matching a CSS selector
+type Patch struct {
+	Selector string `yaml:"-"`
+	// Func converts the element through a helper with this name; on the
+	// root element it renames the generated function
+	Func string `yaml:"func"`
+	// Text replaces the element's content with a string parameter of this name
+	Text string `yaml:"text"`
+	// Attrs maps attribute names to string parameters replacing their values
+	Attrs map[string]string `yaml:"attrs"`
+	// Call replaces the element with a call to an existing component, e.g. views.Footer()
+	Call string `yaml:"call"`
+	// TextMode overrides Options.TextMode for the text inside the element
+	TextMode string `yaml:"text-mode"`
+
+	match selector
+}
+
+// compile parses the patch selector
+func (p *Patch) compile() error {
+	if err := validateTextMode(p.TextMode); err != nil {
+		return fmt.Errorf("patch %q: %w", p.Selector, err)
+	}
+	sel, err := parseSelector(p.Selector)
+	if err != nil {
+		return err
+	}
+	p.match = sel
+	return nil
+}
+
+// parseReplacement parses a rule of the form "selector -> call" replacing the
+// matching elements with a call to an existing component
+func parseReplacement(rule string) (Patch, error) {
+	i := strings.LastIndex(rule, "->")
+	if i < 0 {
+		return Patch{}, fmt.Errorf("invalid replacement %q: expected \"selector -> call\"", rule)
+	}
+	patch := Patch{Selector: strings.TrimSpace(rule[:i]), Call: strings.TrimSpace(rule[i+2:])}
+	if patch.Call == "" {
+		return Patch{}, fmt.Errorf("invalid replacement %q: missing call", rule)
+	}
+	if err := patch.compile(); err != nil {
+		return Patch{}, err
+	}
+	return patch, nil
+}
+
+// loadPatches reads a YAML patch file mapping CSS selectors to overrides,
+// keeping the order of the file
+func loadPatches(path string) ([]Patch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse patch file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("patch file %s must map selectors to patches", path)
+	}
+
+	var patches []Patch
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		patch := Patch{Selector: root.Content[i].Value}
+		if err := root.Content[i+1].Decode(&patch); err != nil {
+			return nil, fmt.Errorf("patch %q: %w", patch.Selector, err)
+		}
+		if err := patch.compile(); err != nil {
+			return nil, fmt.Errorf("patch file %s: %w", path, err)
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// matchPatches assigns the patches to the elements they match. When several
+// patches match an element, the first one setting a field wins.
+func (c *Converter) matchPatches(nodes []*html.Node) {
+	c.patches = make(map[*html.Node]*Patch)
+	for i := range c.opts.Patches {
+		patch := &c.opts.Patches[i]
+		if patch.match == nil {
+			if err := patch.compile(); err != nil {
+				c.report(nil, SeverityWarning, "patch-invalid", "%v", err)
+				continue
+			}
+		}
+
+		matched := false
+		forEachElement(nodes, func(n *html.Node) {
+			if !patch.match.match(n) {
+				return
+			}
+			matched = true
+			existing, ok := c.patches[n]
+			if !ok {
+				copied := *patch
+				c.patches[n] = &copied
+				return
+			}
+			existing.merge(patch)
+		})
+
+		if !matched {
+			c.report(nil, SeverityWarning, "patch-unused", "patch %q matches no element", patch.Selector)
+		} else if patch.Call != "" && !c.hasQualifier(patch.Call) {
+			c.report(nil, SeverityWarning, "patch-import", "no import provides %s; add its package to the config imports", patch.Call)
+		}
+	}
+}
+
+// splitComponents gives every region matching Options.ComponentPer a function
+// of its own, named after its id, first class or tag unless a patch already
+// names one
+func (c *Converter) splitComponents(nodes []*html.Node) {
+	if strings.TrimSpace(c.opts.ComponentPer) == "" {
+		return
+	}
+	sel, err := parseSelector(c.opts.ComponentPer)
+	if err != nil {
+		c.report(nil, SeverityWarning, "component-per-invalid", "%v", err)
+		return
+	}
+
+	matched := false
+	forEachElement(nodes, func(n *html.Node) {
+		if !sel.match(n) {
+			return
+		}
+		matched = true
+		patch, ok := c.patches[n]
+		if !ok {
+			patch = &Patch{Selector: c.opts.ComponentPer}
+			c.patches[n] = patch
+		}
+		if patch.Func == "" && patch.Call == "" {
+			patch.Func = c.elementFuncName(n, "Section")
+		}
+	})
+	if !matched {
+		c.report(nil, SeverityWarning, "component-per-unused", "%q matches no element", c.opts.ComponentPer)
+	}
+}
+
+// splitLandmarks are the elements Options.Split always extracts, and
+// splitContainers those it extracts when they have an id
+var (
+	splitLandmarks  = map[string]bool{"header": true, "nav": true, "main": true, "aside": true, "footer": true}
+	splitContainers = map[string]bool{"section": true, "article": true, "div": true, "form": true}
+)
+
+// splitSections gives every structural section of the page a function of its
+// own with Options.Split: landmarks such as header and footer, and containers
+// with an id. Sections nested in other sections stay in them, except for
+// those of main, which would otherwise hold most of the page.
+func (c *Converter) splitSections(nodes []*html.Node) {
+	if !c.opts.Split {
+		return
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || child.Data == "head" {
+				continue
+			}
+			if !splitLandmarks[child.Data] && !(splitContainers[child.Data] && attrValue(child, "id") != "") {
+				walk(child)
+				continue
+			}
+			patch, ok := c.patches[child]
+			if !ok {
+				patch = &Patch{}
+				c.patches[child] = patch
+			}
+			if patch.Func == "" && patch.Call == "" {
+				patch.Func = c.elementFuncName(child, "Section")
+			}
+			if child.Data == "main" {
+				walk(child)
+			}
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+// merge fills the fields of p that are not set from other
+func (p *Patch) merge(other *Patch) {
+	if p.Func == "" {
+		p.Func = other.Func
+	}
+	if p.Text == "" {
+		p.Text = other.Text
+	}
+	if p.Call == "" {
+		p.Call = other.Call
+	}
+	if p.TextMode == "" {
+		p.TextMode = other.TextMode
+	}
+	for key, name := range other.Attrs {
+		if _, ok := p.Attrs[key]; ok {
+			continue
+		}
+		if p.Attrs == nil {
+			p.Attrs = make(map[string]string)
+		}
+		p.Attrs[key] = name
+	}
+}
+
+// rootPatchFunc returns the function name a patch gives the root element,
+// consuming it so the root is not extracted into a helper as well
+func (c *Converter) rootPatchFunc(n *html.Node) string {
+	patch, ok := c.patches[n]
+	if !ok || patch.Func == "" || patch.Call != "" {
+		return ""
+	}
+	rest := *patch
+	rest.Func = ""
+	c.patches[n] = &rest
+	return goIdentifier(patch.Func, true)
+}
+
+// convertPatched converts an element according to its patch
+func (c *Converter) convertPatched(n *html.Node, patch *Patch, depth int) string {
+	if patch.Call != "" {
+		c.useQualifier(patch.Call)
+		return patch.Call
+	}
+
+	convert := func(depth int) string {
+		children := c.convertChildren(n, depth+1)
+		if patch.Text != "" {
+			children = []string{fmt.Sprintf("T(%s)", c.paramExpr(patch.Text, "", textContent(n)))}
+		}
+		return c.convertElementWithChildren(n, depth, children)
+	}
+	if patch.Func != "" {
+		return c.extractFunc(goIdentifier(patch.Func, true), func() string {
+			return convert(1)
+		})
+	}
+	return convert(depth)
+}
+
+// patchAttribute returns the code for an attribute whose value a patch turns into a parameter
+func (c *Converter) patchAttribute(n *html.Node, attr html.Attribute) (string, bool) {
+	patch, ok := c.patches[n]
+	if !ok {
+		return "", false
+	}
+	name, ok := patch.Attrs[attr.Key]
+	if !ok {
+		return "", false
+	}
+
+	saved := attr.Val
+	attr.Val = patchParamPlaceholder
+	code := c.convertAttribute(attr, n.Data)
+	return strings.Replace(code, c.quoteValue(patchParamPlaceholder), c.paramExpr(name, attr.Key, saved), 1), true
+}
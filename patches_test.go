@@ -0,0 +1,254 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestSelectorMatch(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<main id="app"><nav class="menu top"><a href="/docs" class="link">Docs</a></nav><section><p><a href="https://x.dev">X</a></p></section></main>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var links []*html.Node
+	forEachElement([]*html.Node{doc}, func(n *html.Node) {
+		if n.Data == "a" {
+			links = append(links, n)
+		}
+	})
+
+	tests := []struct {
+		selector string
+		want     []bool
+	}{
+		{"a", []bool{true, true}},
+		{"nav.menu > a", []bool{true, false}},
+		{"#app a.link", []bool{true, false}},
+		{"main > a", []bool{false, false}},
+		{"section a, .top a", []bool{true, true}},
+		{`a[href^="https://"]`, []bool{false, true}},
+		{"[class~=link]", []bool{true, false}},
+	}
+	for _, tt := range tests {
+		sel, err := parseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("parseSelector(%q): %v", tt.selector, err)
+		}
+		for i, link := range links {
+			if got := sel.match(link); got != tt.want[i] {
+				t.Errorf("%q on link %d = %v, want %v", tt.selector, i, got, tt.want[i])
+			}
+		}
+	}
+
+	for _, invalid := range []string{"", "a >", "> a", "a[href", "a..b"} {
+		if _, err := parseSelector(invalid); err == nil {
+			t.Errorf("Expected an error for selector %q", invalid)
+		}
+	}
+}
+
+func TestPatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patches.yaml")
+	patches := `html:
+  func: LandingPage
+".hero h1":
+  text: heading
+a.cta:
+  attrs:
+    href: ctaURL
+.hero:
+  func: Hero
+footer:
+  call: views.Footer()
+`
+	if err := os.WriteFile(path, []byte(patches), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadPatches(path)
+	if err != nil {
+		t.Fatalf("Failed to load patches: %v", err)
+	}
+	if len(loaded) != 5 || loaded[1].Selector != ".hero h1" {
+		t.Fatalf("Expected patches in file order, got %+v", loaded)
+	}
+
+	converter := NewConverterWithOptions(Options{Patches: loaded, Imports: []string{"example.com/app/views"}})
+	result, err := converter.Convert(`<div class="hero"><h1>Welcome</h1><a class="cta" href="/signup">Join</a></div><footer><p>© 2024</p></footer>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		`"example.com/app/views"`,
+		"func Components(heading, ctaURL string) []Node",
+		"Hero(heading, ctaURL),",
+		"views.Footer(),",
+		"func Hero(heading, ctaURL string) Node",
+		"H1(T(heading))",
+		`A(Class("cta"), Href(ctaURL), T("Join"))`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "2024") {
+		t.Errorf("Expected the footer markup to be replaced.\nOutput:\n%s", result)
+	}
+
+	diags := converter.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != "patch-unused" || !strings.Contains(diags[0].Message, `"html"`) {
+		t.Errorf("Expected an unused patch warning, got %v", diags)
+	}
+
+	converter = NewConverterWithOptions(Options{Patches: loaded[:1]})
+	result, err = converter.Convert(`<!DOCTYPE html><html><body><p>Hi</p></body></html>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if !strings.Contains(result, "func LandingPage() Node") || strings.Contains(result, "func Page(") {
+		t.Errorf("Expected the page function to be renamed.\nOutput:\n%s", result)
+	}
+}
+
+func TestComponentPer(t *testing.T) {
+	input := `<!DOCTYPE html><html><body>` +
+		`<section id="hero"><h1>Welcome</h1></section>` +
+		`<section id="features"><p>Fast</p></section>` +
+		`<div class="faq-list"><p>Why?</p></div></body></html>`
+
+	converter := NewConverterWithOptions(Options{
+		ComponentPer: "#hero, #features, .faq-list",
+		Patches:      []Patch{{Selector: "#features", Func: "FeatureGrid"}},
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"func Page() Node",
+		"Body(Hero(), FeatureGrid(), FaqList())",
+		"func Hero() Node",
+		"func FeatureGrid() Node",
+		"func FaqList() Node",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	// Regions named like Plain elements get a suffix
+	converter = NewConverterWithOptions(Options{ComponentPer: "#header, #footer, section"})
+	result, err = converter.Convert(`<div id="header">Top</div><footer id="footer">Bottom</footer><section><p>x</p></section>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, exp := range []string{
+		"func HeaderSection() Node {\n\treturn Div(Id(\"header\")",
+		"func FooterSection() Node {\n\treturn Footer(Id(\"footer\")",
+		"func SectionSection() Node {\n\treturn Section(",
+	} {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+
+	converter = NewConverterWithOptions(Options{ComponentPer: "#missing"})
+	if _, err := converter.Convert(input); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if diags := converter.Diagnostics(); len(diags) != 1 || diags[0].Code != "component-per-unused" {
+		t.Errorf("Expected a component-per-unused warning, got %v", diags)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	input := `<!DOCTYPE html><html><head><title>Home</title></head><body>` +
+		`<header><nav><a href="/">Home</a></nav></header>` +
+		`<main><section id="hero"><h1>Welcome</h1></section><section id="faq"><p>Why?</p></section><p>More</p></main>` +
+		`<div class="wrapper"><aside id="promo">Sale</aside></div>` +
+		`<footer><p>Bye</p></footer></body></html>`
+
+	converter := NewConverterWithOptions(Options{
+		Split:   true,
+		Patches: []Patch{{Selector: "footer", Func: "SiteFooter"}},
+	})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"Body(\n\t\t\tHeaderSection(),\n\t\t\tMainSection(),\n\t\t\tDiv(Class(\"wrapper\"), Promo()),\n\t\t\tSiteFooter(),",
+		"func HeaderSection() Node {\n\treturn Header(Nav(",
+		"func MainSection() Node {\n\treturn Main(Hero(), Faq(), P(T(\"More\")))",
+		"func Hero() Node",
+		"func Promo() Node",
+		"func SiteFooter() Node",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "func NavSection") {
+		t.Errorf("Expected the nav to stay in the header.\nOutput:\n%s", result)
+	}
+}
+
+func TestPatchParamTypes(t *testing.T) {
+	patches := []Patch{
+		{Selector: "img", Attrs: map[string]string{"src": "avatar", "width": "size"}},
+		{Selector: "td", Attrs: map[string]string{"colspan": "span"}},
+		{Selector: "div.card", Attrs: map[string]string{"data-open": "open", "data-count": "items", "data-feed-url": "feed"}},
+		{Selector: "span.badge", Text: "unread"},
+		{Selector: "a", Attrs: map[string]string{"href": "profileURL"}, Text: "name"},
+	}
+	for i := range patches {
+		if err := patches[i].compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := `<div class="card" data-open="false" data-count="3" data-feed-url="/feed"><img src="/a.png" width="64"><a href="/u/1">Ada</a><span class="badge">12</span><table><tr><td colspan="2">x</td></tr></table></div>`
+
+	result, err := NewConverterWithOptions(Options{Patches: patches, Parameterize: true}).Convert(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"strconv"`,
+		`Data("open", strconv.FormatBool(open))`,
+		`Data("count", strconv.Itoa(items))`,
+		`Data("feed-url", feedURL)`,
+		`Src(avatarURL)`,
+		`Width(strconv.Itoa(size))`,
+		`Href(profileURL)`,
+		`T(name)`,
+		`T(strconv.Itoa(unread))`,
+		`ColSpan(strconv.Itoa(span))`,
+		"open bool",
+		"items int",
+		"feedURL",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %s in:\n%s", want, result)
+		}
+	}
+
+	// Without parameterize mode patch parameters stay strings
+	result, err = NewConverterWithOptions(Options{Patches: patches}).Convert(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(result, "strconv") || !strings.Contains(result, "Src(avatar)") {
+		t.Errorf("Expected string parameters without parameterize mode:\n%s", result)
+	}
+}
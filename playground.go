@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// playgroundShareURL is where programs are uploaded to be shared, and
+// playgroundLink the prefix of the links to them
+var (
+	playgroundShareURL = "https://play.golang.org/share"
+	playgroundLink     = "https://go.dev/play/p/"
+)
+
+// playgroundProgram turns generated code into a runnable program that prints
+// the rendered main function fn. Parameters are passed their name for
+// strings and the zero value otherwise.
+func playgroundProgram(code string, fn FuncInfo) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "generated.go", code, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse generated code: %w", err)
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	render := "Render"
+	hasFmt := false
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		switch {
+		case path == "fmt":
+			hasFmt = true
+		case path == htmlImportPath && imp.Name == nil:
+			render = "html.Render"
+		case path == htmlImportPath && imp.Name.Name != ".":
+			render = imp.Name.Name + ".Render"
+		}
+	}
+
+	// Only package main runs, whatever package the code was generated for
+	edits := []sourceEdit{{start: offset(f.Name.Pos()), end: offset(f.Name.End()), text: "main"}}
+	if !hasFmt && len(f.Imports) > 0 {
+		pos := offset(f.Imports[0].Pos())
+		edits = append(edits, sourceEdit{start: pos, end: pos, text: "\"fmt\"\n"})
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		code = code[:e.start] + e.text + code[e.end:]
+	}
+
+	args := make([]string, len(fn.Params))
+	for i, param := range fn.Params {
+		name, typ, _ := strings.Cut(param, " ")
+		switch typ {
+		case "string":
+			args[i] = strconv.Quote(name)
+		case "int":
+			args[i] = "0"
+		case "bool":
+			args[i] = "false"
+		default:
+			args[i] = fmt.Sprintf("*new(%s)", typ)
+		}
+	}
+	call := fmt.Sprintf("%s(%s)", fn.Name, strings.Join(args, ", "))
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(code, "\n"))
+	b.WriteString("\n\nfunc main() {\n")
+	if fn.Result == "[]Node" {
+		fmt.Fprintf(&b, "\tfor _, node := range %s {\n\t\tfmt.Println(%s(node))\n\t}\n", call, render)
+	} else {
+		fmt.Fprintf(&b, "\tfmt.Println(%s(%s))\n", render, call)
+	}
+	b.WriteString("}\n")
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format playground program: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// sharePlayground uploads a program to the Go Playground and returns the link to it
+func sharePlayground(ctx context.Context, program string, timeout time.Duration) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, playgroundShareURL, strings.NewReader(program))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", userAgent)
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to the Go Playground: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing Go Playground response: %v\n", err)
+		}
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Go Playground response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("upload to the Go Playground failed: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	id := string(bytes.TrimSpace(body))
+	if id == "" {
+		return "", fmt.Errorf("the Go Playground returned no snippet id")
+	}
+	return playgroundLink + id, nil
+}
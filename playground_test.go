@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlaygroundProgram(t *testing.T) {
+	converter := NewConverterWithOptions(Options{Package: "views", Parameterize: true, Qualified: true})
+	code, err := converter.Convert(`<html><head><title>Home</title></head><body><p>Hi</p></body></html>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	program, err := playgroundProgram(code, converter.Functions()[0])
+	if err != nil {
+		t.Fatalf("Playground program failed: %v", err)
+	}
+	expected := []string{
+		"package main\n",
+		"\t\"fmt\"\n",
+		"\t\"github.com/plainkit/html\"\n",
+		"func main() {\n\tfmt.Println(html.Render(Page(\"title\")))\n}",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(program, exp) {
+			t.Errorf("Expected program to contain %q.\nOutput:\n%s", exp, program)
+		}
+	}
+
+	program, err = playgroundProgram("package main\n\nimport (\n\t. \"github.com/plainkit/html\"\n)\n\nfunc Components() []Node {\n\treturn []Node{P(), Div()}\n}\n",
+		FuncInfo{Name: "Components", Result: "[]Node"})
+	if err != nil {
+		t.Fatalf("Playground program failed: %v", err)
+	}
+	if !strings.Contains(program, "for _, node := range Components() {\n\t\tfmt.Println(Render(node))\n\t}") {
+		t.Errorf("Expected every fragment to be printed.\nOutput:\n%s", program)
+	}
+}
+
+func TestSharePlayground(t *testing.T) {
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploaded = string(body)
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write([]byte("abc123\n"))
+	}))
+	defer server.Close()
+
+	shareURL := playgroundShareURL
+	playgroundShareURL = server.URL
+	defer func() { playgroundShareURL = shareURL }()
+
+	link, err := sharePlayground(context.Background(), "package main\n", time.Second)
+	if err != nil {
+		t.Fatalf("Share failed: %v", err)
+	}
+	if link != "https://go.dev/play/p/abc123" || uploaded != "package main\n" {
+		t.Errorf("Expected the program to be shared as abc123, got %q after uploading %q", link, uploaded)
+	}
+}
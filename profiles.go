@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// cleanupProfile bundles the strip and transform rules for a site builder export
+type cleanupProfile struct {
+	// classPattern matches builder-specific classes to remove
+	classPattern *regexp.Regexp
+	// idPattern matches generated ids to remove
+	idPattern *regexp.Regexp
+	// scriptPattern matches script sources of the builder runtime
+	scriptPattern *regexp.Regexp
+	// components maps structure classes to component helpers
+	components map[string]string
+	// stripArtifacts removes design tool attributes as
+	// --strip-design-artifacts does
+	stripArtifacts bool
+}
+
+// webflowClasses matches the classes of the Webflow runtime and its state
+// modifiers. The names are listed explicitly so that utility classes sharing
+// the w- prefix, such as Tailwind's w-full or w-64, are kept.
+var webflowClasses = regexp.MustCompile(`^(w-(` + strings.Join([]string{
+	`container`, `row`, `col(-[a-z0-9-]+)?`, `clearfix`, `inline-block`, `block`,
+	`embed`, `button`, `richtext(-[a-z0-9-]+)?`, `layout-[a-z0-9-]+`,
+	`nav(-[a-z-]+)?`, `dropdown(-[a-z-]+)?`, `form(-[a-z-]+)?`, `input`, `select`,
+	`checkbox(-[a-z-]+)?`, `radio(-[a-z-]+)?`, `slider(-[a-z-]+)?`, `slide`,
+	`tabs?(-[a-z-]+)?`, `lightbox(-[a-z-]+)?`, `background-video(-[a-z-]+)?`,
+	`video`, `widget(-[a-z-]+)?`, `dyn-[a-z-]+`, `hidden-[a-z]+`, `icon-[a-z0-9-]+`,
+	`list-unstyled`, `webflow-badge`,
+}, "|") + `)|w--[a-z-]+)$`)
+
+// cleanupProfiles are the named profiles available to --profile
+var cleanupProfiles = map[string]*cleanupProfile{
+	"webflow": {
+		classPattern:   webflowClasses,
+		idPattern:      regexp.MustCompile(`^w-node-`),
+		scriptPattern:  regexp.MustCompile(`(?i)(webflow[^/]*\.js|d3e54v103j8qbb\.cloudfront\.net)`),
+		stripArtifacts: true,
+	},
+	"framer": {
+		classPattern:   regexp.MustCompile(`^framer-[A-Za-z0-9-]+$`),
+		idPattern:      regexp.MustCompile(`^framer-`),
+		scriptPattern:  regexp.MustCompile(`(?i)(framerusercontent\.com|framer\.com/m/)`),
+		stripArtifacts: true,
+	},
+	"bootstrap": {
+		components:     bootstrapComponents,
+		stripArtifacts: true,
+	},
+}
+
+// profileNames returns the names of the available cleanup profiles
+func profileNames() []string {
+	names := make([]string, 0, len(cleanupProfiles))
+	for name := range cleanupProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupProfile returns the named cleanup profile
+func lookupProfile(name string) (*cleanupProfile, error) {
+	profile, ok := cleanupProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(profileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// matchesPattern reports whether an optional profile pattern matches s
+func matchesPattern(re *regexp.Regexp, s string) bool {
+	return re != nil && re.MatchString(s)
+}
+
+// applyProfile strips builder classes, ids and runtime scripts, and unwraps
+// elements that only existed to carry builder classes. Root nodes are never unwrapped.
+func (c *Converter) applyProfile(profile *cleanupProfile, roots []*html.Node) {
+	isRoot := make(map[*html.Node]bool, len(roots))
+	for _, n := range roots {
+		isRoot[n] = true
+	}
+
+	var classes, ids, scripts, unwrapped int
+	var removals, unwraps []*html.Node
+	forEachElement(roots, func(n *html.Node) {
+		if n.Data == "script" && matchesPattern(profile.scriptPattern, attrValue(n, "src")) {
+			removals = append(removals, n)
+			return
+		}
+
+		builderOnly := false
+		kept := n.Attr[:0]
+		for _, attr := range n.Attr {
+			switch attr.Key {
+			case "class":
+				var keep []string
+				for _, class := range strings.Fields(attr.Val) {
+					if matchesPattern(profile.classPattern, class) {
+						classes++
+						continue
+					}
+					keep = append(keep, class)
+				}
+				if len(keep) == 0 {
+					builderOnly = attr.Val != ""
+					continue
+				}
+				attr.Val = strings.Join(keep, " ")
+			case "id":
+				if matchesPattern(profile.idPattern, attr.Val) {
+					ids++
+					continue
+				}
+			}
+			kept = append(kept, attr)
+		}
+		n.Attr = kept
+
+		if builderOnly && n.Data == "div" && len(n.Attr) == 0 && !isRoot[n] {
+			unwraps = append(unwraps, n)
+		}
+	})
+
+	for _, n := range removals {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+			scripts++
+		}
+	}
+	for _, n := range unwraps {
+		if n.Parent == nil {
+			continue
+		}
+		for child := n.FirstChild; child != nil; child = n.FirstChild {
+			n.RemoveChild(child)
+			n.Parent.InsertBefore(child, n)
+		}
+		n.Parent.RemoveChild(n)
+		unwrapped++
+	}
+
+	if classes+ids+scripts+unwrapped > 0 {
+		c.report(nil, SeverityInfo, "profile-cleanup", "profile %s removed %d classes, %d ids and %d scripts, and unwrapped %d wrapper divs",
+			c.opts.Profile, classes, ids, scripts, unwrapped)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// propPattern matches {{name}} props in text and attribute values
+var propPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// propAttrPrefix marks attributes naming the prop that sets another
+// attribute: data-prop-href="url" sets href to the url parameter
+const propAttrPrefix = "data-prop-"
+
+// propKey identifies a prop set by a data-prop attribute by the attribute it
+// sets, empty for the content of its element, and the name of the prop
+type propKey struct {
+	attr, name string
+}
+
+// propIdent returns the Go parameter name of a prop
+func propIdent(name string) string {
+	return withExport(goIdentifier(name, false), false)
+}
+
+// applyPropAttributes rewrites the data-prop attributes of Options.Props into
+// {{name}} props: data-prop-NAME="prop" sets the NAME attribute to the prop,
+// replacing the sample value of the markup, and data-prop="prop" replaces the
+// content of its element with the prop. The sample values are kept to infer
+// the types of the props.
+func (c *Converter) applyPropAttributes(nodes []*html.Node) {
+	c.propSamples = make(map[propKey]string)
+	if !c.opts.Props {
+		return
+	}
+
+	forEachElement(nodes, func(n *html.Node) {
+		var attrs []html.Attribute
+		props := make(map[string]string)
+		var order []string
+		for _, attr := range n.Attr {
+			key, ok := strings.CutPrefix(attr.Key, "data-prop")
+			if !ok || key != "" && !strings.HasPrefix(key, "-") {
+				attrs = append(attrs, attr)
+				continue
+			}
+			name := strings.TrimSpace(attr.Val)
+			if !propPattern.MatchString("{{" + name + "}}") {
+				c.report(n, SeverityWarning, "invalid-prop", "%s=%q is not a valid prop name; the attribute is dropped", attr.Key, attr.Val)
+				continue
+			}
+			key = strings.TrimPrefix(key, "-")
+			if _, seen := props[key]; !seen {
+				order = append(order, key)
+			}
+			props[key] = "{{" + name + "}}"
+		}
+		n.Attr = attrs
+
+		for _, key := range order {
+			sample := propKey{key, strings.Trim(props[key], "{}")}
+			if _, seen := c.propSamples[sample]; !seen {
+				if key == "" {
+					c.propSamples[sample] = textContent(n)
+				} else {
+					c.propSamples[sample] = attrValue(n, key)
+				}
+			}
+			if key == "" {
+				for n.FirstChild != nil {
+					n.RemoveChild(n.FirstChild)
+				}
+				n.AppendChild(&html.Node{Type: html.TextNode, Data: props[key]})
+				continue
+			}
+			setAttr(n, key, props[key])
+		}
+	})
+}
+
+// hasProps reports whether a value references props with Options.Props
+func (c *Converter) hasProps(val string) bool {
+	return c.opts.Props && propPattern.MatchString(val)
+}
+
+// propParam declares the parameter of a prop in the value of attribute key,
+// or in text when key is empty, and returns the string expression passing it
+// on. A prop making up the whole value is typed by paramExpr from the sample
+// value its data-prop attribute replaced; a prop within a longer value is a
+// string.
+func (c *Converter) propParam(key, name string, whole bool) string {
+	ident := propIdent(name)
+	if !whole {
+		return c.param(ident, "string")
+	}
+	return c.paramExpr(ident, key, c.propSamples[propKey{key, name}])
+}
+
+// propText converts a text node referencing props into text nodes for the
+// literal parts and the props in between: "Hello {{user}}" becomes
+// T("Hello "), T(user)
+func (c *Converter) propText(n *html.Node, text string) (string, bool) {
+	if !c.hasProps(text) || isRawText(n.Parent) {
+		return "", false
+	}
+
+	var parts []string
+	last := 0
+	for _, m := range propPattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > last {
+			parts = append(parts, fmt.Sprintf("T(%s)", c.textValue(n, text[last:m[0]])))
+		}
+		parts = append(parts, fmt.Sprintf("T(%s)", c.propParam("", text[m[2]:m[3]], m[0] == 0 && m[1] == len(text))))
+		last = m[1]
+	}
+	if last < len(text) {
+		parts = append(parts, fmt.Sprintf("T(%s)", c.textValue(n, text[last:])))
+	}
+	return strings.Join(parts, ", "), true
+}
+
+// propExpr returns a string concatenation for the value of attribute key
+// referencing props: "/users/{{id}}" becomes "/users/" + id
+func (c *Converter) propExpr(key, val string) (string, bool) {
+	if !c.hasProps(val) {
+		return "", false
+	}
+
+	literal := func(s string) string {
+		if expr, ok := c.placeholderExpr(key, s); ok {
+			return expr
+		}
+		return c.quoteValue(s)
+	}
+	var parts []string
+	last := 0
+	for _, m := range propPattern.FindAllStringSubmatchIndex(val, -1) {
+		if m[0] > last {
+			parts = append(parts, literal(val[last:m[0]]))
+		}
+		parts = append(parts, c.propParam(key, val[m[2]:m[3]], m[0] == 0 && m[1] == len(val)))
+		last = m[1]
+	}
+	if last < len(val) {
+		parts = append(parts, literal(val[last:]))
+	}
+	return strings.Join(parts, " + "), true
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// htmlImportPath is the import path of the Plain html package
+const htmlImportPath = "github.com/plainkit/html"
+
+// validateAlias checks the package name qualified Plain identifiers are referenced by
+func validateAlias(alias string) error {
+	if alias != "" && (!token.IsIdentifier(alias) || alias == "_") {
+		return fmt.Errorf("invalid package alias %q", alias)
+	}
+	return nil
+}
+
+// htmlAlias returns the name Plain identifiers are qualified with
+func (c *Converter) htmlAlias() string {
+	if c.opts.HTMLAlias == "" {
+		return "html"
+	}
+	return c.opts.HTMLAlias
+}
+
+// htmlImportSpec returns the import declaration of the Plain html package:
+// a dot import, or a named one in qualified mode
+func (c *Converter) htmlImportSpec() string {
+	if !c.opts.Qualified {
+		return ". " + strconv.Quote(htmlImportPath)
+	}
+	if alias := c.htmlAlias(); alias != "html" {
+		return alias + " " + strconv.Quote(htmlImportPath)
+	}
+	return strconv.Quote(htmlImportPath)
+}
+
+// qualifyOutput qualifies generated code in qualified mode. Functions the
+// converter generated and calls given by patches or the consent stub are
+// local to the package and left alone.
+func (c *Converter) qualifyOutput(code string) string {
+	if !c.opts.Qualified {
+		return code
+	}
+	local := make(map[string]bool)
+	for _, f := range append(c.funcs, c.svgFuncs...) {
+		local[f.name] = true
+	}
+	calls := []string{c.opts.ConsentStub}
+	for _, patch := range c.patches {
+		calls = append(calls, patch.Call)
+	}
+	for _, call := range calls {
+		if name, _, ok := strings.Cut(call, "("); ok && !strings.Contains(name, ".") {
+			local[strings.TrimSpace(name)] = true
+		}
+	}
+
+	qualified, err := qualifyCode(code, c.htmlAlias(), local)
+	if err != nil {
+		// Left to formatCode to report
+		return code
+	}
+	return qualified
+}
+
+// qualifyCode replaces the dot import of the Plain html package with an
+// import named alias, and prefixes the identifiers it provided with alias.
+// Those are the identifiers the file uses without declaring or importing
+// them, other than predeclared ones and the local names, which are declared
+// elsewhere in the package.
+func qualifyCode(code, alias string, local map[string]bool) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "generated.go", code, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse generated code: %w", err)
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	spec := strconv.Quote(htmlImportPath)
+	if alias != "html" {
+		spec = alias + " " + spec
+	}
+	imported := make(map[string]bool)
+	var edits []sourceEdit
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if path == htmlImportPath {
+			edits = append(edits, sourceEdit{start: offset(imp.Pos()), end: offset(imp.End()), text: spec})
+			continue
+		}
+		if imp.Name != nil {
+			imported[imp.Name.Name] = true
+		} else {
+			imported[path[strings.LastIndex(path, "/")+1:]] = true
+		}
+	}
+
+	for _, ident := range f.Unresolved {
+		name := ident.Name
+		if imported[name] || local[name] || types.Universe.Lookup(name) != nil {
+			continue
+		}
+		pos := offset(ident.Pos())
+		edits = append(edits, sourceEdit{start: pos, end: pos, text: alias + "."})
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		code = code[:e.start] + e.text + code[e.end:]
+	}
+	return code, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// rawTextEnd matches the end tags that would close a script or style
+// element early, whatever their case
+var rawTextEnd = map[string]*regexp.Regexp{
+	"script": regexp.MustCompile(`(?i)</(script)`),
+	"style":  regexp.MustCompile(`(?i)</(style)`),
+}
+
+// rawText converts the source of a script or style element, JSON-LD and
+// other data blocks included, into a Raw node. The text is kept exactly as
+// written, as escaping it like text would break the code, and whitespace
+// may matter to it. Sources that are blank are dropped.
+func (c *Converter) rawText(n *html.Node) string {
+	if strings.TrimSpace(n.Data) == "" {
+		return ""
+	}
+	return fmt.Sprintf("Raw(%s)", c.quoteValue(escapeRawText(n.Parent.Data, n.Data)))
+}
+
+// escapeRawText escapes end tags in the source of a script or style element
+// as <\/script, which scripts and style sheets read the same, so that content
+// substituted into it cannot close the element
+func escapeRawText(tag, text string) string {
+	end, ok := rawTextEnd[tag]
+	if !ok {
+		return text
+	}
+	return end.ReplaceAllString(text, `<\/$1`)
+}
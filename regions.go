@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Editable region markers. User code between them survives regeneration.
+const (
+	editableBegin = "// plainkit:editable begin"
+	editableEnd   = "// plainkit:editable end"
+)
+
+// writeRegion writes an empty editable region
+func writeRegion(buf codeWriter, indent, name string) {
+	fmt.Fprintf(buf, "%s%s %s\n%s%s %s\n", indent, editableBegin, name, indent, editableEnd, name)
+}
+
+// editableRegion is the content of a region and where it sits in the code
+type editableRegion struct {
+	name    string
+	content string
+	start   int // offset of the first content byte
+	end     int // offset of the end marker line
+}
+
+// parseRegions returns the editable regions of generated code in order
+func parseRegions(code string) ([]editableRegion, error) {
+	var regions []editableRegion
+	var open *editableRegion
+	offset := 0
+	for _, line := range strings.SplitAfter(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, editableBegin):
+			if open != nil {
+				return nil, fmt.Errorf("editable region %q starts inside region %q", strings.TrimSpace(strings.TrimPrefix(trimmed, editableBegin)), open.name)
+			}
+			open = &editableRegion{name: strings.TrimSpace(strings.TrimPrefix(trimmed, editableBegin)), start: offset + len(line)}
+		case strings.HasPrefix(trimmed, editableEnd):
+			name := strings.TrimSpace(strings.TrimPrefix(trimmed, editableEnd))
+			if open == nil || name != open.name {
+				return nil, fmt.Errorf("unexpected end of editable region %q", name)
+			}
+			open.end = offset
+			open.content = code[open.start:offset]
+			regions = append(regions, *open)
+			open = nil
+		}
+		offset += len(line)
+	}
+	if open != nil {
+		return nil, fmt.Errorf("editable region %q is not closed", open.name)
+	}
+	return regions, nil
+}
+
+// mergeRegions copies the content of the editable regions of existing code
+// into the matching regions of freshly generated code. Regions with content
+// that no longer exist in the generated code are returned as errors so no
+// user code is lost silently.
+func mergeRegions(generated, existing string) (string, error) {
+	previous, err := parseRegions(existing)
+	if err != nil {
+		return "", err
+	}
+	regions, err := parseRegions(generated)
+	if err != nil {
+		return "", err
+	}
+
+	kept := make(map[string]string, len(previous))
+	for _, region := range previous {
+		kept[region.name] = region.content
+	}
+
+	var buf strings.Builder
+	last := 0
+	for _, region := range regions {
+		content, ok := kept[region.name]
+		if !ok {
+			continue
+		}
+		buf.WriteString(generated[last:region.start])
+		buf.WriteString(content)
+		last = region.end
+		delete(kept, region.name)
+	}
+	buf.WriteString(generated[last:])
+
+	for _, region := range previous {
+		if content, ok := kept[region.name]; ok && strings.TrimSpace(content) != "" {
+			return "", fmt.Errorf("editable region %q no longer exists in the generated code; move or delete its contents", region.name)
+		}
+	}
+	return buf.String(), nil
+}
+
+// preserveRegions merges the editable regions of an existing output file into generated code
+func preserveRegions(path, goCode string) (string, error) {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return goCode, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	merged, err := mergeRegions(goCode, string(existing))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return merged, nil
+}
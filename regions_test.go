@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeRegions(t *testing.T) {
+	converter := NewConverterWithOptions(Options{EditableRegions: true})
+	generated, err := converter.Convert(`<p>Hello</p>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	existing := strings.Replace(generated, "\t// plainkit:editable end Component\n",
+		"\tlog.Println(\"rendering\")\n\t// plainkit:editable end Component\n", 1)
+	existing = strings.Replace(existing, "\t// plainkit:editable end imports\n",
+		"\t\"log\"\n\t// plainkit:editable end imports\n", 1)
+	existing = strings.Replace(existing, `T("Hello")`, `T("Edited by hand")`, 1)
+
+	regenerated, err := converter.Convert(`<p>Hello again</p>`)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	merged, err := mergeRegions(regenerated, existing)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	expected := []string{
+		"\t\"log\"\n\t// plainkit:editable end imports",
+		"\tlog.Println(\"rendering\")\n\t// plainkit:editable end Component",
+		`T("Hello again")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(merged, exp) {
+			t.Errorf("Expected merged code to contain %q.\nMerged:\n%s", exp, merged)
+		}
+	}
+	if strings.Contains(merged, "Edited by hand") {
+		t.Errorf("Expected code outside regions to be regenerated.\nMerged:\n%s", merged)
+	}
+
+	renamed := strings.ReplaceAll(regenerated, "Component", "Greeting")
+	if _, err := mergeRegions(renamed, existing); err == nil || !strings.Contains(err.Error(), `"Component"`) {
+		t.Errorf("Expected an error for a dropped region with content, got %v", err)
+	}
+
+	if _, err := parseRegions("// plainkit:editable begin a\n"); err == nil {
+		t.Error("Expected an error for an unclosed region")
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// convertedComponent records the main function generated for an input file
+type convertedComponent struct {
+	File    string
+	Package string
+	Output  string
+	HTML    string
+	Func    FuncInfo
+	// Funcs counts the functions generated for the input, helpers included
+	Funcs int
+	// Rel is the path of a batch input relative to its input directory,
+	// Links are the internal links of the page, Requests its htmx requests
+	// and Alpine the client state its Alpine expressions use
+	Rel      string
+	Links    []LinkInfo
+	Requests []HxRequest
+	Alpine   AlpineUsage
+}
+
+// buildRegistry generates a Go file of package pkg with a Components map of
+// every converted component that takes no parameters and returns a single
+// Node, optionally followed by a Catalog page rendering all of them
+func buildRegistry(components []convertedComponent, pkg string, catalog bool) (string, []string) {
+	var warnings []string
+	var names []string
+	seen := make(map[string]string)
+	for _, comp := range components {
+		switch {
+		case len(comp.Func.Params) > 0:
+			warnings = append(warnings, fmt.Sprintf("%s: %s takes parameters and is not registered", comp.File, comp.Func.Name))
+		case comp.Func.Result != "Node":
+			warnings = append(warnings, fmt.Sprintf("%s: %s returns %s and is not registered", comp.File, comp.Func.Name, comp.Func.Result))
+		case seen[comp.Func.Name] != "":
+			warnings = append(warnings, fmt.Sprintf("%s: %s is also generated from %s", comp.File, comp.Func.Name, seen[comp.Func.Name]))
+		default:
+			seen[comp.Func.Name] = comp.File
+			names = append(names, comp.Func.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("import (\n\t. \"github.com/plainkit/html\"\n)\n\n")
+	buf.WriteString("// Components lists every converted component by name\n")
+	buf.WriteString("var Components = map[string]func() Node{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%s: %s,\n", strconv.Quote(name), name)
+	}
+	buf.WriteString("}\n")
+
+	if catalog {
+		buf.WriteString("\n// Catalog renders every converted component on a single page\n")
+		buf.WriteString("func Catalog() Node {\n")
+		buf.WriteString("\treturn Html(\n")
+		buf.WriteString("\t\tHead(HeadTitle(T(\"Component catalog\"))),\n")
+		buf.WriteString("\t\tBody(\n")
+		buf.WriteString("\t\t\tH1(T(\"Component catalog\")),\n")
+		for _, name := range names {
+			fmt.Fprintf(&buf, "\t\t\tSection(Id(%s), H2(T(%s)), %s()),\n", strconv.Quote("component-"+name), strconv.Quote(name), name)
+		}
+		buf.WriteString("\t\t),\n")
+		buf.WriteString("\t)\n")
+		buf.WriteString("}\n")
+	}
+	return buf.String(), warnings
+}
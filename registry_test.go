@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildRegistry(t *testing.T) {
+	code, warnings := buildRegistry([]convertedComponent{
+		{File: "ui/card.html", Func: FuncInfo{Name: "Card", Result: "Node"}},
+		{File: "ui/alert.html", Func: FuncInfo{Name: "Alert", Result: "Node"}},
+		{File: "ui/hero.html", Func: FuncInfo{Name: "Hero", Params: []string{"title string"}, Result: "Node"}},
+		{File: "ui/list.html", Func: FuncInfo{Name: "Items", Result: "[]Node"}},
+	}, "main", true)
+
+	expected := []string{
+		"var Components = map[string]func() Node{",
+		`"Alert": Alert,`,
+		`"Card": Card,`,
+		"func Catalog() Node {",
+		`Section(Id("component-Alert"), H2(T("Alert")), Alert()),`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(code, exp) {
+			t.Errorf("Expected registry to contain %q, but it doesn't.\nOutput:\n%s", exp, code)
+		}
+	}
+	if strings.Index(code, `"Alert"`) > strings.Index(code, `"Card"`) {
+		t.Error("Expected components to be sorted by name")
+	}
+	if len(warnings) != 2 {
+		t.Errorf("Expected warnings for Hero and Items, got %v", warnings)
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	entries := buildManifest([]convertedComponent{
+		{File: "ui/hero.html", Output: "views/hero.go", HTML: "<h1>Hi</h1>", Func: FuncInfo{Name: "Hero", Params: []string{"title string"}, Result: "Node"}},
+	})
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Name != "Hero" || e.Source != "ui/hero.html" || e.Output != "views/hero.go" || e.Preview != "<h1>Hi</h1>" {
+		t.Errorf("Unexpected manifest entry: %+v", e)
+	}
+	if len(e.Params) != 1 || e.Params[0] != "title string" {
+		t.Errorf("Unexpected params: %v", e.Params)
+	}
+}
+
+func TestRegistryPackage(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "registry.go")
+	registryFile, packageName = registry, "views"
+	defer func() { registryFile, packageName, converted = "", "", nil }()
+
+	if _, err := convertSource(context.Background(), Options{Package: packageName}, "hero.html", []byte(`<div class="hero">Hi</div>`)); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if err := writeReports(); err != nil {
+		t.Fatalf("Writing reports failed: %v", err)
+	}
+	code, err := os.ReadFile(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(code), "package views\n") {
+		t.Errorf("Expected the registry in the package of the converted files.\nOutput:\n%s", code)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// linkOnlyRels are rel tokens that only affect link elements
+	linkOnlyRels = []string{
+		"canonical", "compression-dictionary", "dns-prefetch", "expect", "icon", "manifest",
+		"modulepreload", "pingback", "preconnect", "prefetch", "preload", "stylesheet",
+		"shortcut", "apple-touch-icon", "apple-touch-icon-precomposed", "apple-touch-startup-image",
+		"mask-icon",
+	}
+	// hyperlinkOnlyRels are rel tokens that only affect a, area and form elements
+	hyperlinkOnlyRels = []string{
+		"bookmark", "external", "nofollow", "noopener", "noreferrer", "opener", "sponsored", "tag", "ugc",
+	}
+)
+
+// relTokenList splits a rel value into its lowercased tokens, dropping duplicates
+func relTokenList(val string) []string {
+	var tokens []string
+	for _, token := range strings.Fields(strings.ToLower(val)) {
+		if !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// relValue returns a rel value with its tokens separated by single spaces and
+// repeated tokens dropped, keeping the case of the first occurrence
+func relValue(val string) string {
+	var tokens []string
+	for _, token := range strings.Fields(val) {
+		if !slices.ContainsFunc(tokens, func(t string) bool { return strings.EqualFold(t, token) }) {
+			tokens = append(tokens, token)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// checkRel reports rel tokens that are repeated, contradict each other or have
+// no effect on their element. Unknown tokens are reported by checkEnums.
+func (c *Converter) checkRel(nodes []*html.Node) {
+	forEachElement(nodes, func(n *html.Node) {
+		if !hasAttr(n, "rel") {
+			return
+		}
+		val := attrValue(n, "rel")
+		tokens := relTokenList(val)
+		if len(tokens) < len(strings.Fields(val)) {
+			c.report(n, SeverityInfo, "rel-duplicate", "rel=%q repeats tokens; the generated list has each once", val)
+		}
+		if slices.Contains(tokens, "opener") && slices.Contains(tokens, "noopener") {
+			c.report(n, SeverityWarning, "rel-conflict", "rel=%q has both opener and noopener; noopener wins", val)
+		}
+
+		var ignored []string
+		for _, token := range tokens {
+			switch {
+			case n.Data == "link" && slices.Contains(hyperlinkOnlyRels, token):
+				ignored = append(ignored, token)
+			case n.Data != "link" && slices.Contains(linkOnlyRels, token):
+				ignored = append(ignored, token)
+			case n.Data == "form" && (token == "bookmark" || token == "tag"):
+				ignored = append(ignored, token)
+			}
+		}
+		if len(ignored) > 0 {
+			c.report(n, SeverityWarning, "rel-context", "rel tokens %s have no effect on <%s>", strings.Join(ignored, " "), n.Data)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var renameDir string
+
+var renameCmd = &cobra.Command{
+	Use:   "rename OldName NewName",
+	Short: "Rename a generated component function and its call sites",
+	Long: `Rename a package-level function in the Go files of a directory and update every
+reference to it, along with the editable region markers named after it.
+
+Example:
+  plainkit-converter rename Component PricingCard --dir ./views`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changed, err := renameFunc(renameDir, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Renamed %s → %s in %d file(s)\n", args[0], args[1], changed)
+		return nil
+	},
+}
+
+// renameFunc renames the package-level function oldName to newName in the Go
+// files of dir and returns the number of files changed
+func renameFunc(dir, oldName, newName string) (int, error) {
+	if !token.IsIdentifier(newName) {
+		return 0, fmt.Errorf("%q is not a valid Go identifier", newName)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return 0, err
+	}
+	fset := token.NewFileSet()
+	sources := make(map[*ast.File]string)
+	packages := make(map[string][]*ast.File)
+	var names []string
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		sources[f] = string(src)
+		if packages[f.Name.Name] == nil {
+			names = append(names, f.Name.Name)
+		}
+		packages[f.Name.Name] = append(packages[f.Name.Name], f)
+	}
+
+	for _, name := range names {
+		files := packages[name]
+		// Imports are not resolved; the function's own uses do not depend on them
+		conf := types.Config{
+			Importer: importerFunc(func(path string) (*types.Package, error) {
+				return nil, fmt.Errorf("not loaded")
+			}),
+			Error: func(error) {},
+		}
+		info := &types.Info{Defs: make(map[*ast.Ident]types.Object), Uses: make(map[*ast.Ident]types.Object)}
+		pkg, _ := conf.Check(name, fset, files, info)
+
+		fn, ok := pkg.Scope().Lookup(oldName).(*types.Func)
+		if !ok {
+			continue
+		}
+		if pkg.Scope().Lookup(newName) != nil {
+			return 0, fmt.Errorf("package %s already declares %s", name, newName)
+		}
+		// The Plain package is not loaded, so its dot-imported names are
+		// checked against the mappings instead
+		if dotImportsPlain(files) && plainName(newName) {
+			return 0, fmt.Errorf("%s is already declared by the dot-imported %s", newName, htmlImportPath)
+		}
+		return renameObject(fset, files, sources, info, fn, newName)
+	}
+	return 0, fmt.Errorf("no function %s found in %s", oldName, dir)
+}
+
+// importerFunc adapts a function to types.Importer
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// dotImportsPlain reports whether any of files dot-imports the Plain html package
+func dotImportsPlain(files []*ast.File) bool {
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			if imp.Name != nil && imp.Name.Name == "." && imp.Path.Value == strconv.Quote(htmlImportPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renameObject rewrites every identifier referring to obj, and the editable
+// region markers named after it, returning the number of files changed.
+// Nothing is written if newName would refer to something else at any use.
+func renameObject(fset *token.FileSet, files []*ast.File, sources map[*ast.File]string, info *types.Info, obj types.Object, newName string) (int, error) {
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	edits := make(map[*ast.File][]sourceEdit)
+	fileOf := func(p token.Pos) *ast.File {
+		for _, f := range files {
+			if f.FileStart <= p && p <= f.FileEnd {
+				return f
+			}
+		}
+		return nil
+	}
+	for _, refs := range []map[*ast.Ident]types.Object{info.Defs, info.Uses} {
+		for id, o := range refs {
+			if o == obj {
+				if scope := obj.Pkg().Scope().Innermost(id.Pos()); scope != nil {
+					if _, other := scope.LookupParent(newName, id.Pos()); other != nil && other != obj {
+						return 0, fmt.Errorf("%s is already declared in scope at %s", newName, fset.Position(id.Pos()))
+					}
+				}
+				f := fileOf(id.Pos())
+				edits[f] = append(edits[f], sourceEdit{start: offset(id.Pos()), end: offset(id.End()), text: newName})
+			}
+		}
+	}
+	for _, f := range files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				for _, marker := range []string{editableBegin, editableEnd} {
+					if c.Text == marker+" "+obj.Name() {
+						edits[f] = append(edits[f], sourceEdit{start: offset(c.Pos()), end: offset(c.End()), text: marker + " " + newName})
+					}
+				}
+			}
+		}
+	}
+
+	outputs := make(map[string][]byte)
+	var paths []string
+	for _, f := range files {
+		fileEdits := edits[f]
+		if len(fileEdits) == 0 {
+			continue
+		}
+		sort.Slice(fileEdits, func(i, j int) bool { return fileEdits[i].start > fileEdits[j].start })
+		src := sources[f]
+		for _, e := range fileEdits {
+			src = src[:e.start] + e.text + src[e.end:]
+		}
+		path := fset.Position(f.Pos()).Filename
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return 0, fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		outputs[path] = formatted
+		paths = append(paths, path)
+	}
+
+	for i, path := range paths {
+		if err := os.WriteFile(path, outputs[path], 0o644); err != nil {
+			return i, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return len(paths), nil
+}
+
+func init() {
+	renameCmd.Flags().StringVar(&renameDir, "dir", ".", "Directory of the Go package to rewrite")
+	rootCmd.AddCommand(renameCmd)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenameFunc(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"card.go": `package views
+
+import . "github.com/plainkit/html"
+
+// Component renders a pricing card
+func Component() Node {
+	// plainkit:editable begin Component
+	// plainkit:editable end Component
+	return Div(Class("card"))
+}
+`,
+		"page.go": `package views
+
+import . "github.com/plainkit/html"
+
+type card struct{ Component string }
+
+func Page() Node {
+	c := card{Component: "x"}
+	_ = c.Component
+	return Main(Component(), local())
+}
+
+func local() Node {
+	Component := Span()
+	return Component
+}
+`,
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changed, err := renameFunc(dir, "Component", "PricingCard")
+	if err != nil {
+		t.Fatalf("renameFunc failed: %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("Expected 2 changed files, got %d", changed)
+	}
+
+	card, _ := os.ReadFile(filepath.Join(dir, "card.go"))
+	page, _ := os.ReadFile(filepath.Join(dir, "page.go"))
+	expected := []string{
+		"func PricingCard() Node {",
+		"// plainkit:editable begin PricingCard",
+		"// plainkit:editable end PricingCard",
+		"Main(PricingCard(), local())",
+		"card{Component: \"x\"}",
+		"_ = c.Component",
+		"Component := Span()\n\treturn Component",
+	}
+	for _, exp := range expected {
+		if !strings.Contains(string(card)+string(page), exp) {
+			t.Errorf("Expected renamed code to contain %q.\ncard.go:\n%s\npage.go:\n%s", exp, card, page)
+		}
+	}
+
+	if _, err := renameFunc(dir, "PricingCard", "Page"); err == nil {
+		t.Error("Expected an error when the new name is taken")
+	}
+	if _, err := renameFunc(dir, "Missing", "Other"); err == nil {
+		t.Error("Expected an error for an unknown function")
+	}
+}
+
+func TestRenameFuncConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		newName string
+	}{
+		{
+			name: "dot-imported Plain function",
+			src: `package views
+
+import . "github.com/plainkit/html"
+
+func Card() Node {
+	return Div(Class("card"))
+}
+`,
+			newName: "Header",
+		},
+		{
+			name: "shadowed by a local variable",
+			src: `package views
+
+func Card() int { return 1 }
+
+func Total() int {
+	Item := 2
+	return Item + Card()
+}
+`,
+			newName: "Item",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "card.go")
+			if err := os.WriteFile(path, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := renameFunc(filepath.Dir(path), "Card", tt.newName); err == nil {
+				t.Errorf("Expected an error renaming Card to %s", tt.newName)
+			}
+			src, _ := os.ReadFile(path)
+			if string(src) != tt.src {
+				t.Errorf("Expected the file to be left unchanged, got:\n%s", src)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"html"
+	"strings"
+)
+
+// renderKind tells what evaluating a generated expression produced
+type renderKind int
+
+const (
+	renderString renderKind = iota // an unescaped string value
+	renderNode                     // markup
+	renderAttr                     // an attribute, with its leading space
+)
+
+// renderValue is the result of evaluating a generated expression
+type renderValue struct {
+	kind renderKind
+	text string
+}
+
+// renderFuncs indexes the Plain functions by name, as the mappings export
+// lists them, so that generated calls can be rendered back into HTML
+type renderFuncs struct {
+	elements   map[string]string
+	attributes map[string]mappingEntry
+}
+
+// newRenderFuncs builds the index from the built-in mappings
+func newRenderFuncs() *renderFuncs {
+	export := exportMappings()
+	funcs := &renderFuncs{elements: make(map[string]string), attributes: make(map[string]mappingEntry)}
+	for _, entry := range export.Elements {
+		if entry.Name != "*" {
+			if _, ok := funcs.elements[entry.Func]; !ok {
+				funcs.elements[entry.Func] = entry.Name
+			}
+		}
+	}
+	for _, entries := range [][]mappingEntry{export.Attributes, export.HTMX, export.Alpine} {
+		for _, entry := range entries {
+			if _, ok := funcs.attributes[entry.Func]; !ok {
+				funcs.attributes[entry.Func] = entry
+			}
+		}
+	}
+	return funcs
+}
+
+// renderFunc renders the HTML a function returns, with its parameters and any
+// value computed at runtime standing in as {{...}}. Functions doing more than
+// returning a single expression are not rendered.
+func (c *canonicalizer) renderFunc(d *ast.FuncDecl) (string, bool) {
+	if d.Body == nil || len(d.Body.List) != 1 {
+		return "", false
+	}
+	ret, ok := d.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", false
+	}
+	v := c.render(ret.Results[0])
+	if v.kind == renderString {
+		v.text = html.EscapeString(v.text)
+	}
+	return c.signature(d) + "\n" + v.text, true
+}
+
+// render evaluates an expression built from Plain calls
+func (c *canonicalizer) render(e ast.Expr) renderValue {
+	if val, ok := c.stringValue(e); ok {
+		return renderValue{renderString, val}
+	}
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return c.render(e.X)
+	case *ast.CallExpr:
+		return c.renderCall(e)
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return renderValue{renderString, e.Name}
+		}
+	}
+	return renderValue{renderString, "{{" + c.expr(e) + "}}"}
+}
+
+// renderCall renders a call to a Plain function, falling back to the call
+// itself with its arguments rendered for any other function
+func (c *canonicalizer) renderCall(call *ast.CallExpr) renderValue {
+	name := callName(call.Fun)
+	if name == "" {
+		return renderValue{renderString, "{{" + c.expr(call) + "}}"}
+	}
+	args := make([]renderValue, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = c.render(arg)
+	}
+	if call.Ellipsis.IsValid() && len(args) > 0 {
+		args[len(args)-1] = renderValue{renderString, "{{" + c.expr(call.Args[len(args)-1]) + "...}}"}
+	}
+	str := func(i int) string {
+		if i < len(args) {
+			return args[i].text
+		}
+		return ""
+	}
+
+	// Qualified output refers to the Plain functions through the html package
+	if c.qualifier != "" {
+		name = strings.TrimPrefix(name, c.qualifier+".")
+	}
+
+	switch name {
+	case "T":
+		return renderValue{renderNode, html.EscapeString(str(0))}
+	case "Raw":
+		return renderValue{renderNode, str(0)}
+	case "Comment":
+		return renderValue{renderNode, "<!--" + str(0) + "-->"}
+	case "Fragment":
+		return renderValue{renderNode, renderChildren(args)}
+	case "Element":
+		if len(args) > 0 {
+			return renderElement(str(0), args[1:])
+		}
+	}
+
+	tag, isElement := c.funcs.elements[name]
+	entry, isAttr := c.funcs.attributes[name]
+	// Title names both an element and an attribute, which takes nothing but
+	// strings
+	if isAttr && (!isElement || attributeArgs(entry, args)) {
+		return renderAttribute(entry, args)
+	}
+	if isElement {
+		return renderElement(tag, args)
+	}
+
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.text
+	}
+	return renderValue{renderNode, "{{" + name + "(" + strings.Join(parts, ", ") + ")}}"}
+}
+
+// attributeArgs reports whether args are the string arguments of an attribute
+func attributeArgs(entry mappingEntry, args []renderValue) bool {
+	if len(args) == 0 || len(args) != len(entry.Args) {
+		return false
+	}
+	for _, arg := range args {
+		if arg.kind != renderString {
+			return false
+		}
+	}
+	return true
+}
+
+// callName returns the name of a called function, qualified by its package
+func callName(fun ast.Expr) string {
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			return x.Name + "." + fun.Sel.Name
+		}
+	}
+	return ""
+}
+
+// renderElement renders an element from the attributes and children among args
+func renderElement(tag string, args []renderValue) renderValue {
+	var b strings.Builder
+	b.WriteString("<" + tag)
+	var children []renderValue
+	for _, arg := range args {
+		if arg.kind == renderAttr {
+			b.WriteString(arg.text)
+		} else {
+			children = append(children, arg)
+		}
+	}
+	b.WriteString(">")
+	if voidElements[tag] && len(children) == 0 {
+		return renderValue{renderNode, b.String()}
+	}
+	b.WriteString(renderChildren(children))
+	b.WriteString("</" + tag + ">")
+	return renderValue{renderNode, b.String()}
+}
+
+// renderChildren concatenates child nodes, escaping text passed as a node
+func renderChildren(args []renderValue) string {
+	var b strings.Builder
+	for _, arg := range args {
+		if arg.kind == renderString {
+			b.WriteString(html.EscapeString(arg.text))
+		} else {
+			b.WriteString(arg.text)
+		}
+	}
+	return b.String()
+}
+
+// renderAttribute renders an attribute call as the mapping describes its
+// arguments. A * in the mapped name is filled by the argument naming it.
+func renderAttribute(entry mappingEntry, args []renderValue) renderValue {
+	name, value, hasValue := entry.Name, "", false
+	for i, arg := range entry.Args {
+		if i >= len(args) {
+			break
+		}
+		switch arg {
+		case "value", "script", "nonce", "bool":
+			value, hasValue = args[i].text, true
+		default:
+			name = strings.Replace(name, "*", args[i].text, 1)
+		}
+	}
+	if !hasValue {
+		return renderValue{renderAttr, " " + name}
+	}
+	return renderValue{renderAttr, fmt.Sprintf(" %s=\"%s\"", name, html.EscapeString(value))}
+}
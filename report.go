@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportEntry summarizes the conversion of one input for the HTML report
+type reportEntry struct {
+	File      string
+	Output    string
+	Component string
+	Lines     int
+	Funcs     int
+	Params    int
+	Errors    int
+	Warnings  int
+	Infos     int
+}
+
+// Status classifies the input for the migration summary: inputs with errors
+// are blocked, inputs with warnings need review and the others are ready
+func (f reportEntry) Status() string {
+	switch {
+	case f.Errors > 0:
+		return "blocked"
+	case f.Warnings > 0:
+		return "review"
+	default:
+		return "ready"
+	}
+}
+
+// reportGroup lists the diagnostics of one severity
+type reportGroup struct {
+	Severity    Severity
+	Diagnostics []fileDiagnostic
+}
+
+// reportCode counts how often a diagnostic code was reported
+type reportCode struct {
+	Code     string
+	Severity Severity
+	Count    int
+}
+
+// conversionReport is the data rendered into the HTML report
+type conversionReport struct {
+	Generated string
+	Version   string
+	Files     []reportEntry
+	Groups    []reportGroup
+	Codes     []reportCode
+
+	Ready, Review, Blocked int
+}
+
+// Percent returns the share of inputs that are ready, rounded down
+func (r conversionReport) Percent() int {
+	if len(r.Files) == 0 {
+		return 0
+	}
+	return r.Ready * 100 / len(r.Files)
+}
+
+// severityOrder lists severities from the most to the least severe
+var severityOrder = []Severity{SeverityError, SeverityWarning, SeverityInfo}
+
+// buildReport gathers the per-file statistics and diagnostics of a run
+func buildReport(components []convertedComponent, diags []fileDiagnostic) conversionReport {
+	report := conversionReport{Version: version}
+
+	index := make(map[string]int)
+	for _, comp := range components {
+		index[comp.File] = len(report.Files)
+		report.Files = append(report.Files, reportEntry{
+			File:      filepath.ToSlash(comp.File),
+			Output:    filepath.ToSlash(comp.Output),
+			Component: comp.Func.Name,
+			Lines:     strings.Count(strings.TrimSuffix(comp.HTML, "\n"), "\n") + 1,
+			Funcs:     comp.Funcs,
+			Params:    len(comp.Func.Params),
+		})
+	}
+
+	bySeverity := make(map[Severity][]fileDiagnostic)
+	codes := make(map[string]*reportCode)
+	for _, d := range diags {
+		i, ok := index[d.File]
+		if !ok {
+			// Inputs that produced no function still show up in the statistics
+			i = len(report.Files)
+			index[d.File] = i
+			report.Files = append(report.Files, reportEntry{File: filepath.ToSlash(d.File)})
+		}
+		switch d.Severity {
+		case SeverityError:
+			report.Files[i].Errors++
+		case SeverityWarning:
+			report.Files[i].Warnings++
+		default:
+			report.Files[i].Infos++
+		}
+
+		bySeverity[d.Severity] = append(bySeverity[d.Severity], d)
+		if codes[d.Code] == nil {
+			codes[d.Code] = &reportCode{Code: d.Code, Severity: d.Severity}
+		}
+		codes[d.Code].Count++
+	}
+
+	for _, severity := range severityOrder {
+		if len(bySeverity[severity]) > 0 {
+			report.Groups = append(report.Groups, reportGroup{Severity: severity, Diagnostics: bySeverity[severity]})
+		}
+	}
+	for _, code := range codes {
+		report.Codes = append(report.Codes, *code)
+	}
+	sort.Slice(report.Codes, func(i, j int) bool {
+		if report.Codes[i].Count != report.Codes[j].Count {
+			return report.Codes[i].Count > report.Codes[j].Count
+		}
+		return report.Codes[i].Code < report.Codes[j].Code
+	})
+
+	for _, f := range report.Files {
+		switch f.Status() {
+		case "blocked":
+			report.Blocked++
+		case "review":
+			report.Review++
+		default:
+			report.Ready++
+		}
+	}
+	return report
+}
+
+// reportTemplate renders the conversion report as a standalone HTML page
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Plain conversion report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { border-bottom: 1px solid #ddd; padding: .4rem .6rem; text-align: left; vertical-align: top; }
+td.num { text-align: right; }
+code { font-size: .9em; }
+.progress { background: #eee; height: 1rem; border-radius: .5rem; overflow: hidden; max-width: 40rem; }
+.progress div { background: #2a7; height: 100%; }
+.ready { color: #2a7; } .review { color: #b80; } .blocked { color: #c33; }
+.error { color: #c33; } .warning { color: #b80; } .info { color: #57a; }
+</style>
+</head>
+<body>
+<h1>Plain conversion report</h1>
+<p>Generated {{.Generated}} by plainkit-converter v{{.Version}}.</p>
+
+<h2>Migration progress</h2>
+<div class="progress"><div style="width: {{.Percent}}%"></div></div>
+<p>{{.Ready}} of {{len .Files}} file(s) ready ({{.Percent}}%): <span class="review">{{.Review}} need review</span>, <span class="blocked">{{.Blocked}} blocked</span>.</p>
+<p>Files are ready when their conversion reported no warnings or errors, need review when it reported warnings and are blocked by errors.</p>
+
+<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Output</th><th>Component</th><th>Lines</th><th>Functions</th><th>Parameters</th><th>Errors</th><th>Warnings</th><th>Info</th><th>Status</th></tr>
+{{- range .Files}}
+<tr><td><code>{{.File}}</code></td><td>{{if .Output}}<code>{{.Output}}</code>{{else}}stdout{{end}}</td><td>{{.Component}}</td><td class="num">{{.Lines}}</td><td class="num">{{.Funcs}}</td><td class="num">{{.Params}}</td><td class="num">{{.Errors}}</td><td class="num">{{.Warnings}}</td><td class="num">{{.Infos}}</td><td class="{{.Status}}">{{.Status}}</td></tr>
+{{- end}}
+</table>
+{{- if .Codes}}
+
+<h2>Findings by code</h2>
+<table>
+<tr><th>Code</th><th>Severity</th><th>Count</th></tr>
+{{- range .Codes}}
+<tr><td><code>{{.Code}}</code></td><td class="{{.Severity}}">{{.Severity}}</td><td class="num">{{.Count}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Groups}}
+
+<h2 class="{{.Severity}}">{{.Severity}} ({{len .Diagnostics}})</h2>
+<table>
+<tr><th>File</th><th>Location</th><th>Message</th><th>Code</th></tr>
+{{- range .Diagnostics}}
+<tr><td><code>{{.File}}</code></td><td>{{if .Line}}line {{.Line}}{{else}}<code>{{.Node}}</code>{{end}}</td><td>{{.Message}}</td><td><code>{{.Code}}</code></td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeReport writes the conversion report as an HTML page
+func writeReport(path string, components []convertedComponent, diags []fileDiagnostic) error {
+	report := buildReport(components, diags)
+	report.Generated = time.Now().Format(time.RFC1123)
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, report); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildReport(t *testing.T) {
+	report := buildReport([]convertedComponent{
+		{File: "pages/index.html", Output: "views/index.go", HTML: "<h1>Hi</h1>\n<p>Text</p>", Func: FuncInfo{Name: "Index", Params: []string{"title string"}, Result: "Node"}, Funcs: 2},
+		{File: "pages/about.html", HTML: "<p>About</p>", Func: FuncInfo{Name: "About", Result: "Node"}, Funcs: 1},
+		{File: "pages/contact.html", HTML: "<form></form>", Func: FuncInfo{Name: "Contact", Result: "Node"}, Funcs: 1},
+	}, []fileDiagnostic{
+		{File: "pages/index.html", Diagnostic: Diagnostic{Severity: SeverityInfo, Code: "rel-duplicate", Message: "duplicate"}},
+		{File: "pages/about.html", Diagnostic: Diagnostic{Severity: SeverityWarning, Code: "csp-inline", Message: "<script> blocked", Node: "body > script"}},
+		{File: "pages/contact.html", Diagnostic: Diagnostic{Severity: SeverityError, Code: "nesting", Message: "form in form", Line: 3}},
+		{File: "pages/contact.html", Diagnostic: Diagnostic{Severity: SeverityWarning, Code: "csp-inline", Message: "style blocked"}},
+	})
+
+	if len(report.Files) != 3 {
+		t.Fatalf("Expected 3 files, got %+v", report.Files)
+	}
+	index := report.Files[0]
+	if index.Lines != 2 || index.Funcs != 2 || index.Params != 1 || index.Infos != 1 || index.Status() != "ready" {
+		t.Errorf("Unexpected statistics for index: %+v", index)
+	}
+	if report.Files[1].Status() != "review" || report.Files[2].Status() != "blocked" {
+		t.Errorf("Unexpected statuses: %+v", report.Files)
+	}
+	if report.Ready != 1 || report.Review != 1 || report.Blocked != 1 || report.Percent() != 33 {
+		t.Errorf("Unexpected progress: %d ready, %d review, %d blocked, %d%%", report.Ready, report.Review, report.Blocked, report.Percent())
+	}
+
+	if len(report.Groups) != 3 || report.Groups[0].Severity != SeverityError || report.Groups[2].Severity != SeverityInfo {
+		t.Errorf("Expected groups ordered by severity, got %+v", report.Groups)
+	}
+	if len(report.Groups[1].Diagnostics) != 2 {
+		t.Errorf("Expected 2 warnings, got %+v", report.Groups[1].Diagnostics)
+	}
+	if report.Codes[0].Code != "csp-inline" || report.Codes[0].Count != 2 {
+		t.Errorf("Expected the most frequent code first, got %+v", report.Codes)
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, report); err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	page := buf.String()
+	for _, want := range []string{"1 of 3 file(s) ready (33%)", "<code>views/index.go</code>", "&lt;script&gt; blocked", "line 3", "<code>body &gt; script</code>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected report to contain %q:\n%s", want, page)
+		}
+	}
+}
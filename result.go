@@ -0,0 +1,39 @@
+package main
+
+import "context"
+
+// Result is the outcome of a conversion, for callers that post-process the
+// generated code without parsing it again
+type Result struct {
+	Code string `json:"code"`
+	// Imports are the import declarations of the generated file, e.g.
+	// `. "github.com/plainkit/html"`
+	Imports []string `json:"imports"`
+	// Functions are the generated functions, the main function first
+	Functions []FuncInfo `json:"functions"`
+	// Doctype is the doctype declaration of a converted page, empty for
+	// fragments and pages without one
+	Doctype     string       `json:"doctype,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// ConvertResult converts HTML and returns the generated code along with its
+// imports, functions and diagnostics. When conversion fails the result still
+// holds the diagnostics reported so far.
+func (c *Converter) ConvertResult(ctx context.Context, htmlContent string) (Result, error) {
+	if err := c.convert(ctx, htmlContent); err != nil {
+		return Result{Diagnostics: c.Diagnostics()}, err
+	}
+	return Result{
+		Code:        c.render(),
+		Imports:     c.importSpecs(),
+		Functions:   c.Functions(),
+		Doctype:     c.doctype,
+		Diagnostics: c.Diagnostics(),
+	}, nil
+}
+
+// ConvertResult converts HTML with the given options, see Converter.ConvertResult
+func ConvertResult(ctx context.Context, htmlContent string, opts Options) (Result, error) {
+	return NewConverterWithOptions(opts).ConvertResult(ctx, htmlContent)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// siteRoute is a path of the converted site: a converted page, or a path
+// its links lead to or its forms submit to
+type siteRoute struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+	// Component is the function rendering the page served at the path,
+	// empty for paths the backend has to provide
+	Component string `json:"component,omitempty"`
+	// Referrers are the inputs linking or submitting to the path
+	Referrers []string `json:"referrers,omitempty"`
+}
+
+// routePath normalizes a site path into the route serving it, the way a
+// static server maps files: /about.html is /about and /docs/index.html /docs/
+func routePath(p string) string {
+	if ext := path.Ext(p); ext == ".html" || ext == ".htm" {
+		p = strings.TrimSuffix(p, ext)
+		if path.Base(p) == "index" {
+			p = strings.TrimSuffix(path.Dir(p), "/") + "/"
+		}
+	}
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// buildRoutes collects the routes of the converted pages, their links and
+// the actions of their forms, ordered by path
+func buildRoutes(components []convertedComponent) []siteRoute {
+	byPath := make(map[string]*siteRoute)
+	route := func(p string) *siteRoute {
+		p = routePath(p)
+		r, ok := byPath[p]
+		if !ok {
+			r = &siteRoute{Path: p}
+			byPath[p] = r
+		}
+		return r
+	}
+	addMethod := func(r *siteRoute, method string) {
+		for _, m := range r.Methods {
+			if m == method {
+				return
+			}
+		}
+		r.Methods = append(r.Methods, method)
+	}
+	addReferrer := func(r *siteRoute, file string) {
+		for _, f := range r.Referrers {
+			if f == file {
+				return
+			}
+		}
+		r.Referrers = append(r.Referrers, file)
+	}
+
+	for _, comp := range components {
+		r := route(pagePath(comp))
+		r.Component = comp.Func.Name
+		addMethod(r, "GET")
+	}
+	for _, comp := range components {
+		page := pagePath(comp)
+		file := strings.ReplaceAll(comp.File, "\\", "/")
+		for _, link := range comp.Links {
+			target := page
+			if link.Href != "" {
+				target = linkTarget(page, link.Href)
+			}
+			r := route(target)
+			method := "GET"
+			if link.Method != "" {
+				method = link.Method
+			}
+			addMethod(r, method)
+			addReferrer(r, file)
+		}
+	}
+
+	routes := make([]siteRoute, 0, len(byPath))
+	for _, p := range sortedKeys(byPath) {
+		r := byPath[p]
+		sort.Strings(r.Methods)
+		routes = append(routes, *r)
+	}
+	return routes
+}
+
+// routesCode generates a Go file of package pkg declaring the routes
+func routesCode(routes []siteRoute, pkg string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("// Route is a path of the converted site: a converted page, or a path its\n")
+	buf.WriteString("// links lead to or its forms submit to\n")
+	buf.WriteString("type Route struct {\n")
+	buf.WriteString("\tPath    string\n")
+	buf.WriteString("\tMethods []string\n")
+	buf.WriteString("\t// Component is the function rendering the page served at Path, empty\n")
+	buf.WriteString("\t// for paths the backend has to provide\n")
+	buf.WriteString("\tComponent string\n")
+	buf.WriteString("}\n\n")
+	buf.WriteString("// Routes lists the routes of the converted site by path\n")
+	buf.WriteString("var Routes = []Route{\n")
+	for _, r := range routes {
+		methods := make([]string, len(r.Methods))
+		for i, m := range r.Methods {
+			methods[i] = strconv.Quote(m)
+		}
+		fmt.Fprintf(&buf, "\t{Path: %s, Methods: []string{%s}", strconv.Quote(r.Path), strings.Join(methods, ", "))
+		if r.Component != "" {
+			fmt.Fprintf(&buf, ", Component: %s", strconv.Quote(r.Component))
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// writeRoutes writes the routes manifest, as a Go file when its name ends in
+// .go and as JSON otherwise
+func writeRoutes(filename string, components []convertedComponent, pkg string) error {
+	routes := buildRoutes(components)
+	if strings.HasSuffix(filename, ".go") {
+		return emitOutput("routes", filename, routesCode(routes, pkg))
+	}
+	if checkMode {
+		return nil
+	}
+	data, err := json.MarshalIndent(struct {
+		Routes []siteRoute `json:"routes"`
+	}{routes}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode routes: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write routes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// outputRoute maps input files matching a glob pattern to an output path template
+type outputRoute struct {
+	pattern  string
+	prefix   string
+	re       *regexp.Regexp
+	template string
+}
+
+// parseRoute parses a rule of the form "pattern -> template". The pattern
+// supports * (within a path segment) and ** (across segments); the template
+// may use {dir}, {base}, {name} and {ext}.
+func parseRoute(rule string) (*outputRoute, error) {
+	parts := strings.SplitN(rule, "->", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid route %q: expected \"pattern -> template\"", rule)
+	}
+	pattern := filepath.ToSlash(strings.TrimSpace(parts[0]))
+	template := strings.TrimSpace(parts[1])
+	if pattern == "" || template == "" {
+		return nil, fmt.Errorf("invalid route %q: pattern and template are required", rule)
+	}
+
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route pattern %q: %w", pattern, err)
+	}
+	return &outputRoute{
+		pattern:  pattern,
+		prefix:   globPrefix(pattern),
+		re:       re,
+		template: template,
+	}, nil
+}
+
+// globRegexp compiles a glob pattern with ** support into an anchored regexp
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case ch == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			// "**/" also matches no directory at all
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				sb.WriteString("(?:.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// globPrefix returns the literal directory prefix of a glob pattern
+func globPrefix(pattern string) string {
+	i := strings.IndexAny(pattern, "*?")
+	if i < 0 {
+		return path.Dir(pattern)
+	}
+	return path.Dir(pattern[:i] + "x")
+}
+
+// resolve returns the output path for an input, or false when it does not match
+func (r *outputRoute) resolve(input string) (string, bool) {
+	input = filepath.ToSlash(path.Clean(filepath.ToSlash(input)))
+	if !r.re.MatchString(input) {
+		return "", false
+	}
+
+	rel := input
+	if r.prefix != "." {
+		rel = strings.TrimPrefix(input, r.prefix+"/")
+	}
+	dir := path.Dir(rel)
+	if dir == "." {
+		dir = ""
+	}
+	ext := path.Ext(input)
+	base := strings.TrimSuffix(path.Base(input), ext)
+
+	out := strings.NewReplacer(
+		"{dir}", dir,
+		"{base}", base,
+		"{name}", funcNameFromFilename(input),
+		"{ext}", strings.TrimPrefix(ext, "."),
+	).Replace(r.template)
+	return filepath.FromSlash(path.Clean(out)), true
+}
+
+// outputRouter resolves output paths using the first matching route
+type outputRouter []*outputRoute
+
+// newOutputRouter parses a list of route rules
+func newOutputRouter(rules []string) (outputRouter, error) {
+	var router outputRouter
+	for _, rule := range rules {
+		route, err := parseRoute(rule)
+		if err != nil {
+			return nil, err
+		}
+		router = append(router, route)
+	}
+	return router, nil
+}
+
+// resolve returns the output path for an input using the first matching route
+func (router outputRouter) resolve(input string) (string, bool) {
+	for _, route := range router {
+		if out, ok := route.resolve(input); ok {
+			return out, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputRouter(t *testing.T) {
+	router, err := newOutputRouter([]string{
+		"pages/**.html -> internal/views/{dir}/{base}.go",
+		"partials/*.html -> internal/partials/{name}.go",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse routes: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"pages/home.html", "internal/views/home.go", true},
+		{"pages/blog/post.html", "internal/views/blog/post.go", true},
+		{"partials/site-footer.html", "internal/partials/SiteFooter.go", true},
+		{"partials/nested/card.html", "", false},
+		{"other/page.html", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			out, ok := router.resolve(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("Expected match %v, got %v", tt.ok, ok)
+			}
+			if ok && out != filepath.FromSlash(tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestParseRouteInvalid(t *testing.T) {
+	if _, err := parseRoute("pages/*.html"); err == nil {
+		t.Error("Expected error for route without ->")
+	}
+}
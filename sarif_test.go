@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestBuildSARIF(t *testing.T) {
+	log := buildSARIF([]fileDiagnostic{
+		{File: "pages/index.html", Diagnostic: Diagnostic{Severity: SeverityWarning, Code: "email-layout", Message: "use tables", Node: "body > div"}},
+		{File: "pages/index.html", Diagnostic: Diagnostic{Severity: SeverityInfo, Code: "email-table-role", Message: "add role"}},
+	})
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "email-layout" {
+		t.Errorf("Unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(run.Results))
+	}
+
+	first := run.Results[0]
+	if first.Level != "warning" || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "pages/index.html" {
+		t.Errorf("Unexpected result: %+v", first)
+	}
+	if first.Locations[0].LogicalLocations[0].FullyQualifiedName != "body > div" {
+		t.Errorf("Expected logical location for node, got %+v", first.Locations[0].LogicalLocations)
+	}
+	if run.Results[1].Level != "note" {
+		t.Errorf("Expected info diagnostics to map to note, got %q", run.Results[1].Level)
+	}
+}
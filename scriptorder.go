@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// headContent are the elements the parser keeps in the head; any other
+// element written there starts the body
+var headContent = map[string]bool{
+	"html": true, "head": true, "base": true, "link": true, "meta": true, "noscript": true,
+	"script": true, "style": true, "template": true, "title": true,
+}
+
+// orderedResource is a script or stylesheet, whose position decides when it
+// runs or applies
+type orderedResource struct {
+	key  string
+	head bool
+	line int
+}
+
+// resourceKey identifies a script or stylesheet element by its tag and URL,
+// returning "" for other elements
+func resourceKey(tag string, attr func(string) string) string {
+	switch tag {
+	case "script":
+		return "script " + attr("src")
+	case "style":
+		return "style"
+	case "link":
+		for _, rel := range strings.Fields(strings.ToLower(attr("rel"))) {
+			if rel == "stylesheet" {
+				return "link " + attr("href")
+			}
+		}
+	}
+	return ""
+}
+
+// describeResource names a script or stylesheet for a message
+func describeResource(key string) string {
+	tag, url, _ := strings.Cut(key, " ")
+	if url == "" {
+		return "<" + tag + ">"
+	}
+	return "<" + tag + "> " + url
+}
+
+// sourceResources returns the scripts and stylesheets of a page in source
+// order, with whether they were written in the head: between the head tags,
+// or before any body content when the head tags are left out
+func sourceResources(src string) []orderedResource {
+	var resources []orderedResource
+	z := html.NewTokenizer(strings.NewReader(src))
+	inHead, headTag, foreign := true, false, 0
+	// text is the head element whose content is being read
+	var text string
+	line := 1
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return resources
+		}
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		token := z.Token()
+		tag := strings.ToLower(token.Data)
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if tag == "svg" || tag == "math" {
+				if tt == html.StartTagToken {
+					foreign++
+				}
+				inHead = inHead && headTag
+				continue
+			}
+			if foreign > 0 {
+				continue
+			}
+			if tt == html.StartTagToken && (tag == "title" || tag == "script" || tag == "style" || tag == "noscript" || tag == "template") {
+				text = tag
+			}
+			switch {
+			case tag == "head":
+				headTag = true
+			case tag == "body":
+				inHead = false
+			case !headContent[tag] && !headTag:
+				inHead = false
+			}
+			key := resourceKey(tag, func(key string) string {
+				for _, attr := range token.Attr {
+					if attr.Key == key {
+						return attr.Val
+					}
+				}
+				return ""
+			})
+			if key != "" {
+				resources = append(resources, orderedResource{key: key, head: inHead, line: tokenLine})
+			}
+		case html.EndTagToken:
+			if (tag == "svg" || tag == "math") && foreign > 0 {
+				foreign--
+			}
+			if tag == text {
+				text = ""
+			}
+			if tag == "head" {
+				inHead = false
+			}
+		case html.TextToken:
+			if inHead && !headTag && foreign == 0 && text == "" && strings.TrimSpace(token.Data) != "" {
+				inHead = false
+			}
+		}
+	}
+}
+
+// parsedResources returns the scripts and stylesheets of the parsed tree in
+// document order, with whether they are in the head
+func parsedResources(nodes []*html.Node) ([]orderedResource, []*html.Node) {
+	var resources []orderedResource
+	var elements []*html.Node
+	forEachElement(nodes, func(n *html.Node) {
+		if n.Namespace != "" {
+			return
+		}
+		key := resourceKey(n.Data, func(key string) string { return attrValue(n, key) })
+		if key == "" {
+			return
+		}
+		head := false
+		for p := n.Parent; p != nil; p = p.Parent {
+			head = head || p.Data == "head"
+		}
+		resources = append(resources, orderedResource{key: key, head: head})
+		elements = append(elements, n)
+	})
+	return resources, elements
+}
+
+// checkScriptOrder reports scripts and stylesheets the parser put in a
+// different order, and scripts it moved from the head into the body, where
+// they run after the content before them. With Options.PinScripts such
+// scripts are moved back to the end of the head.
+func (c *Converter) checkScriptOrder(nodes []*html.Node) {
+	if len(nodes) != 1 || nodes[0].Data != "html" {
+		return
+	}
+	source := sourceResources(c.source)
+	parsed, elements := parsedResources(nodes)
+	if len(source) != len(parsed) {
+		// Dropped or inserted elements are reported as parser mutations
+		return
+	}
+
+	for i := range source {
+		if source[i].key != parsed[i].key {
+			c.reportLine(source[i].line+c.lineOffset, SeverityWarning, "script-reordered",
+				"the parser put %s before %s, changing the order scripts and stylesheets run and apply in", describeResource(parsed[i].key), describeResource(source[i].key))
+			return
+		}
+	}
+
+	head := childElement(nodes[0], "head")
+	for i, n := range elements {
+		if n.Data != "script" || !source[i].head || parsed[i].head {
+			continue
+		}
+		if c.opts.PinScripts && head != nil {
+			n.Parent.RemoveChild(n)
+			head.AppendChild(n)
+			c.reportLine(source[i].line+c.lineOffset, SeverityInfo, "script-pinned", "moved <script> back into the head, where the source wrote it")
+			continue
+		}
+		c.reportLine(source[i].line+c.lineOffset, SeverityWarning, "script-moved",
+			"<script> written in the head ends up in the body, as content before it started the body; it now runs after that content")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// selector is a parsed CSS selector list. It supports type, #id, .class and
+// [attr], [attr=val], [attr^=val], [attr$=val], [attr*=val], [attr~=val]
+// simple selectors combined with descendant and child (>) combinators.
+type selector [][]selectorStep
+
+// selectorStep is a compound selector and the combinator joining it to the previous step
+type selectorStep struct {
+	child   bool
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrMatcher
+}
+
+// attrMatcher is an attribute selector
+type attrMatcher struct {
+	key string
+	op  string
+	val string
+}
+
+// parseSelector parses a comma separated CSS selector list
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	for _, part := range strings.Split(s, ",") {
+		steps, err := parseComplexSelector(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+		}
+		sel = append(sel, steps)
+	}
+	return sel, nil
+}
+
+// parseComplexSelector parses compound selectors joined by combinators
+func parseComplexSelector(s string) ([]selectorStep, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	var steps []selectorStep
+	child := false
+	for _, token := range tokenizeSelector(s) {
+		if token == ">" {
+			if len(steps) == 0 || child {
+				return nil, fmt.Errorf("misplaced combinator")
+			}
+			child = true
+			continue
+		}
+		step, err := parseCompound(token)
+		if err != nil {
+			return nil, err
+		}
+		step.child = child
+		child = false
+		steps = append(steps, step)
+	}
+	if child || len(steps) == 0 {
+		return nil, fmt.Errorf("dangling combinator")
+	}
+	return steps, nil
+}
+
+// tokenizeSelector splits a complex selector into compound selectors and > combinators
+func tokenizeSelector(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	inBrackets := false
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '[':
+			inBrackets = true
+			current.WriteRune(r)
+		case r == ']':
+			inBrackets = false
+			current.WriteRune(r)
+		case inBrackets:
+			current.WriteRune(r)
+		case r == '>':
+			flush()
+			tokens = append(tokens, ">")
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parseCompound parses a compound selector such as a.button#cta[href]
+func parseCompound(s string) (selectorStep, error) {
+	var step selectorStep
+	name := func(i int) (string, int) {
+		start := i
+		for i < len(s) && !strings.ContainsRune(".#[", rune(s[i])) {
+			i++
+		}
+		return s[start:i], i
+	}
+
+	i := 0
+	if s[0] != '.' && s[0] != '#' && s[0] != '[' {
+		step.tag, i = name(0)
+		step.tag = strings.ToLower(step.tag)
+		if step.tag == "*" {
+			step.tag = ""
+		}
+	}
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			var class string
+			class, i = name(i + 1)
+			if class == "" {
+				return step, fmt.Errorf("empty class name")
+			}
+			step.classes = append(step.classes, class)
+		case '#':
+			var id string
+			id, i = name(i + 1)
+			if id == "" {
+				return step, fmt.Errorf("empty id")
+			}
+			step.id = id
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return step, fmt.Errorf("unterminated attribute selector")
+			}
+			matcher, err := parseAttrMatcher(s[i+1 : i+end])
+			if err != nil {
+				return step, err
+			}
+			step.attrs = append(step.attrs, matcher)
+			i += end + 1
+		default:
+			return step, fmt.Errorf("unexpected %q", s[i])
+		}
+	}
+	return step, nil
+}
+
+// parseAttrMatcher parses the inside of an attribute selector
+func parseAttrMatcher(s string) (attrMatcher, error) {
+	for _, op := range []string{"^=", "$=", "*=", "~=", "="} {
+		if key, val, ok := strings.Cut(s, op); ok {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				return attrMatcher{}, fmt.Errorf("empty attribute name")
+			}
+			val = strings.Trim(strings.TrimSpace(val), `"'`)
+			return attrMatcher{key: strings.ToLower(key), op: op, val: val}, nil
+		}
+	}
+	key := strings.TrimSpace(s)
+	if key == "" {
+		return attrMatcher{}, fmt.Errorf("empty attribute name")
+	}
+	return attrMatcher{key: strings.ToLower(key)}, nil
+}
+
+// match reports whether an element matches any selector of the list
+func (sel selector) match(n *html.Node) bool {
+	for _, steps := range sel {
+		if matchSteps(n, steps) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSteps matches a complex selector right to left
+func matchSteps(n *html.Node, steps []selectorStep) bool {
+	last := steps[len(steps)-1]
+	if !last.matches(n) {
+		return false
+	}
+	if len(steps) == 1 {
+		return true
+	}
+	rest := steps[:len(steps)-1]
+	for parent := n.Parent; parent != nil && parent.Type == html.ElementNode; parent = parent.Parent {
+		if matchSteps(parent, rest) {
+			return true
+		}
+		if last.child {
+			break
+		}
+	}
+	return false
+}
+
+// matches reports whether an element matches the compound selector
+func (step selectorStep) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || (step.tag != "" && n.Data != step.tag) {
+		return false
+	}
+	if step.id != "" && attrValue(n, "id") != step.id {
+		return false
+	}
+	classes := strings.Fields(attrValue(n, "class"))
+	for _, class := range step.classes {
+		if !containsString(classes, class) {
+			return false
+		}
+	}
+	for _, m := range step.attrs {
+		if !m.matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether an element satisfies the attribute selector
+func (m attrMatcher) matches(n *html.Node) bool {
+	if !hasAttr(n, m.key) {
+		return false
+	}
+	val := attrValue(n, m.key)
+	switch m.op {
+	case "=":
+		return val == m.val
+	case "^=":
+		return m.val != "" && strings.HasPrefix(val, m.val)
+	case "$=":
+		return m.val != "" && strings.HasSuffix(val, m.val)
+	case "*=":
+		return m.val != "" && strings.Contains(val, m.val)
+	case "~=":
+		return containsString(strings.Fields(val), m.val)
+	}
+	return true
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
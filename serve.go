@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr    string
+	serveTimeout time.Duration
+	serveOrigin  string
+)
+
+// maxServeBody limits the size of a conversion request
+const maxServeBody = 16 << 20
+
+// serveBoolOptions are the boolean options a request may set as query
+// parameters, named like the flags of the command line
+var serveBoolOptions = map[string]func(*Options, bool){
+	"htmx":              func(o *Options, v bool) { o.HTMX = v },
+	"alpine":            func(o *Options, v bool) { o.Alpine = v },
+	"unexported":        func(o *Options, v bool) { o.Unexported = v },
+	"fragment":          func(o *Options, v bool) { o.FragmentWrapper = v },
+	"fragment-funcs":    func(o *Options, v bool) { o.FragmentFuncs = v },
+	"qualified":         func(o *Options, v bool) { o.Qualified = v },
+	"standalone":        func(o *Options, v bool) { o.Standalone = v },
+	"no-format":         func(o *Options, v bool) { o.NoFormat = v },
+	"parameterize":      func(o *Options, v bool) { o.Parameterize = v },
+	"props":             func(o *Options, v bool) { o.Props = v },
+	"split":             func(o *Options, v bool) { o.Split = v },
+	"flatten":           func(o *Options, v bool) { o.Flatten = v },
+	"validate":          func(o *Options, v bool) { o.Validate = v },
+	"events":            func(o *Options, v bool) { o.Events = v },
+	"rewrite-handlers":  func(o *Options, v bool) { o.RewriteHandlers = v },
+	"class-conditions":  func(o *Options, v bool) { o.ClassConditions = v },
+	"alpine-data":       func(o *Options, v bool) { o.AlpineData = v },
+	"check-expressions": func(o *Options, v bool) { o.CheckExpressions = v },
+	"a11y-fix":          func(o *Options, v bool) { o.A11yFix = v },
+}
+
+// serveStringOptions are the string options a request may set as query
+// parameters
+var serveStringOptions = map[string]func(*Options, string){
+	"package":    func(o *Options, v string) { o.Package = v },
+	"func":       func(o *Options, v string) { o.FuncName = v },
+	"filename":   func(o *Options, v string) { o.Filename = v },
+	"mode":       func(o *Options, v string) { o.Mode = v },
+	"text-mode":  func(o *Options, v string) { o.TextMode = v },
+	"comments":   func(o *Options, v string) { o.Comments = v },
+	"html-alias": func(o *Options, v string) { o.HTMLAlias = v },
+	"profile":    func(o *Options, v string) { o.Profile = v },
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve conversion requests over HTTP",
+	Long: `Run an HTTP server converting HTML for playgrounds and editor extensions.
+
+POST /convert takes the HTML as the request body and options as query
+parameters named like the flags, e.g. /convert?htmx=1&package=views, and
+answers with the generated Go code as text/plain. Diagnostics are returned
+in X-Plainkit-Diagnostic headers. Requests accepting application/json get a
+JSON object like the daemon's instead, and a JSON request body takes the
+daemon's request format:
+  {"html": "<div>Hello</div>", "options": {"HTMX": true}}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server := &http.Server{
+			Addr:              serveAddr,
+			Handler:           newServeHandler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx := cmd.Context()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "Error shutting down server: %v\n", err)
+			}
+		}()
+
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", serveAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve on %s: %w", serveAddr, err)
+		}
+		return nil
+	},
+}
+
+// newServeHandler returns the handler of the HTTP API
+func newServeHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", handleConvert)
+	return mux
+}
+
+// handleConvert answers a conversion request
+func handleConvert(w http.ResponseWriter, r *http.Request) {
+	if serveOrigin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", serveOrigin)
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Plainkit-Diagnostic")
+	}
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		serveError(w, r, http.StatusMethodNotAllowed, "method %s not allowed, use POST", r.Method)
+		return
+	}
+
+	req, err := readServeRequest(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		serveError(w, r, status, "%v", err)
+		return
+	}
+
+	ctx := r.Context()
+	if serveTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, serveTimeout)
+		defer cancel()
+	}
+	result, err := ConvertResult(ctx, req.HTML, req.Options)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, context.DeadlineExceeded) {
+			status = http.StatusServiceUnavailable
+		}
+		serveError(w, r, status, "%v", err)
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, newDaemonResponse(result, nil))
+		return
+	}
+	for _, d := range result.Diagnostics {
+		w.Header().Add("X-Plainkit-Diagnostic", d.String())
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, result.Code)
+}
+
+// readServeRequest reads the HTML and options of a request: a JSON body in
+// the daemon's format, or an HTML body with options as query parameters
+func readServeRequest(w http.ResponseWriter, r *http.Request) (daemonRequest, error) {
+	var req daemonRequest
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxServeBody))
+	if err != nil {
+		return req, fmt.Errorf("failed to read request: %w", err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.Unmarshal(body, &req); err != nil {
+			return req, fmt.Errorf("invalid request: %w", err)
+		}
+	} else {
+		req.HTML = string(body)
+	}
+	if err := applyQueryOptions(&req.Options, r.URL.Query()); err != nil {
+		return req, err
+	}
+	if strings.TrimSpace(req.HTML) == "" {
+		return req, fmt.Errorf("no HTML to convert in the request body")
+	}
+	return req, nil
+}
+
+// applyQueryOptions sets the options given as query parameters
+func applyQueryOptions(opts *Options, query url.Values) error {
+	for name, values := range query {
+		value := values[len(values)-1]
+		if set, ok := serveBoolOptions[name]; ok {
+			v := true
+			if value != "" {
+				var err error
+				if v, err = strconv.ParseBool(value); err != nil {
+					return fmt.Errorf("option %s: %q is not a boolean", name, value)
+				}
+			}
+			set(opts, v)
+			continue
+		}
+		if set, ok := serveStringOptions[name]; ok {
+			set(opts, value)
+			continue
+		}
+		return fmt.Errorf("unknown option %q", name)
+	}
+	return nil
+}
+
+// wantsJSON reports whether a request asks for a JSON response
+func wantsJSON(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accepted)); mediaType == "application/json" {
+			return true
+		}
+	}
+	return false
+}
+
+// serveError answers a request with an error, as JSON when it asks for JSON
+func serveError(w http.ResponseWriter, r *http.Request, status int, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if wantsJSON(r) {
+		writeJSON(w, status, daemonResponse{Error: message})
+		return
+	}
+	http.Error(w, message, status)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+	}
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveTimeout, "timeout", 10*time.Second, "Abandon a request whose conversion takes longer than this (0 means no limit)")
+	serveCmd.Flags().StringVar(&serveOrigin, "cors-origin", "", "Allow browser pages from this origin, or * for any, to call the API")
+	rootCmd.AddCommand(serveCmd)
+}
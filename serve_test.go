@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeConvert(t *testing.T) {
+	server := httptest.NewServer(newServeHandler())
+	defer server.Close()
+
+	post := func(query, contentType, accept, body string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/convert"+query, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(data)
+	}
+
+	resp, body := post("?htmx=1&package=views&func=LoadButton", "text/html", "", `<button hx-get="/data">Load</button>`)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("Unexpected response %d %s: %s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+	for _, exp := range []string{"package views", "func LoadButton() Node", `htmx.HxGet("/data")`} {
+		if !strings.Contains(body, exp) {
+			t.Errorf("Expected code to contain %q, got:\n%s", exp, body)
+		}
+	}
+
+	resp, body = post("?validate=true", "application/json", "application/json", `{"html": "<p><div>x</div></p>", "options": {"Package": "views"}}`)
+	var result daemonResponse
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Invalid JSON response %q: %v", body, err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(result.Code, "package views") || len(result.Diagnostics) == 0 {
+		t.Errorf("Unexpected JSON response %d: %+v", resp.StatusCode, result)
+	}
+
+	resp, body = post("?colour=1", "text/html", "application/json", `<p>x</p>`)
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, `"error":"unknown option \"colour\""`) {
+		t.Errorf("Expected a JSON error for an unknown option, got %d: %s", resp.StatusCode, body)
+	}
+
+	resp, body = post("?package=1views", "text/html", "", `<p>x</p>`)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an invalid package name, got %d: %s", resp.StatusCode, body)
+	}
+
+	getResp, err := http.Get(server.URL + "/convert")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = getResp.Body.Close()
+	if getResp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", getResp.StatusCode)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// snippet recognizes a well-known third-party script by its signature
+type snippet struct {
+	name  string // config key
+	title string
+	// helper is called with the account id in place of the snippet
+	helper string
+	// tag, signature and id match the element, its src or content, and the account id
+	tag       string
+	signature *regexp.Regexp
+	id        *regexp.Regexp
+}
+
+// snippets are the recognized third-party snippets
+var snippets = []snippet{
+	{
+		name: "google-tag", title: "Google tag (gtag.js)", helper: "analytics.GoogleTag", tag: "script",
+		signature: regexp.MustCompile(`googletagmanager\.com/gtag/js|gtag\(\s*['"]config['"]`),
+		id:        regexp.MustCompile(`\b((?:G|AW|DC)-[A-Z0-9]+|UA-\d+-\d+)\b`),
+	},
+	{
+		name: "google-tag-manager", title: "Google Tag Manager", helper: "analytics.GoogleTagManager", tag: "script",
+		signature: regexp.MustCompile(`googletagmanager\.com/gtm\.js`),
+		id:        regexp.MustCompile(`\b(GTM-[A-Z0-9]+)\b`),
+	},
+	{
+		name: "google-tag-manager-noscript", title: "Google Tag Manager noscript fallback", helper: "analytics.GoogleTagManagerNoScript", tag: "noscript",
+		signature: regexp.MustCompile(`googletagmanager\.com/ns\.html`),
+		id:        regexp.MustCompile(`\b(GTM-[A-Z0-9]+)\b`),
+	},
+	{
+		name: "hotjar", title: "Hotjar", helper: "analytics.Hotjar", tag: "script",
+		signature: regexp.MustCompile(`static\.hotjar\.com`),
+		id:        regexp.MustCompile(`hjid\s*:\s*(\d+)`),
+	},
+	{
+		name: "intercom", title: "Intercom", helper: "analytics.Intercom", tag: "script",
+		signature: regexp.MustCompile(`widget\.intercom\.io|intercomSettings`),
+		id:        regexp.MustCompile(`(?:app_id["']?\s*:\s*["']|widget\.intercom\.io/widget/)([A-Za-z0-9]+)`),
+	},
+}
+
+// snippetSource returns what a snippet signature is matched against: the
+// src of a script, or the markup of its content
+func snippetSource(n *html.Node) string {
+	if src := attrValue(n, "src"); src != "" {
+		return src
+	}
+	var buf strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			buf.WriteString(child.Data)
+		} else {
+			_ = html.Render(&buf, child)
+		}
+	}
+	return buf.String()
+}
+
+// matchSnippet returns the snippet an element belongs to and its account id
+func matchSnippet(n *html.Node) (*snippet, string, bool) {
+	source := snippetSource(n)
+	for i := range snippets {
+		s := &snippets[i]
+		if n.Data != s.tag || !s.signature.MatchString(source) {
+			continue
+		}
+		if m := s.id.FindStringSubmatch(source); m != nil {
+			return s, m[1], true
+		}
+	}
+	return nil, "", false
+}
+
+// stubSnippets replaces recognized analytics and chat snippets with calls to
+// helpers, dropping the other parts of a snippet already replaced
+func (c *Converter) stubSnippets(nodes []*html.Node) {
+	c.snippetCalls = make(map[*html.Node]string)
+	if !c.opts.StubSnippets {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var removals []*html.Node
+	unresolved := make(map[string]bool)
+	forEachElement(nodes, func(n *html.Node) {
+		s, id, ok := matchSnippet(n)
+		if !ok {
+			return
+		}
+		helper := s.helper
+		if mapped, ok := c.opts.Snippets[s.name]; ok {
+			helper = mapped
+		}
+		if helper == "" {
+			return
+		}
+		key := s.name + " " + id
+		if seen[key] {
+			removals = append(removals, n)
+			return
+		}
+		seen[key] = true
+
+		code := fmt.Sprintf("%s(%s)", helper, c.quoteValue(id))
+		c.snippetCalls[n] = code
+		if !c.hasQualifier(helper) {
+			unresolved[helper] = true
+		}
+		c.report(n, SeverityInfo, "snippet-stubbed", "replaced the %s snippet with %s", s.title, code)
+	})
+	for _, n := range removals {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		helpers := make([]string, 0, len(unresolved))
+		for helper := range unresolved {
+			helpers = append(helpers, helper)
+		}
+		sort.Strings(helpers)
+		c.report(nil, SeverityWarning, "snippet-import", "no import provides %s; add the analytics package to the config imports", strings.Join(helpers, ", "))
+	}
+}
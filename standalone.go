@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// standaloneHeader marks standalone output as unfit for production
+const standaloneHeader = `// Standalone output of plainkit-converter: NOT FOR PRODUCTION.
+// The stubs at the end of this file stand in for github.com/plainkit/html and
+// the other packages the component uses, so that it compiles on its own for
+// quick experiments. They render nothing; convert without --standalone to
+// use the real packages.
+
+`
+
+// standaloneOutput replaces the imports of generated code by local stubs
+// with Options.Standalone
+func (c *Converter) standaloneOutput(code string) string {
+	if !c.opts.Standalone {
+		return code
+	}
+	standalone, err := standaloneCode(code)
+	if err != nil {
+		// Left to formatCode to report
+		return code
+	}
+	return standalone
+}
+
+// standaloneCode drops the imports that are not from the standard library
+// and declares stubs for what the code uses of them: a function for every
+// dot-imported identifier, Node as an empty interface, and a variable
+// holding stub functions for every named package
+func standaloneCode(code string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "generated.go", code, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse generated code: %w", err)
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	var edits []sourceEdit
+	dot := false
+	packages := make(map[string]string)
+	kept := make(map[string]bool)
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var dropped []ast.Spec
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			first, _, _ := strings.Cut(path, "/")
+			if !strings.Contains(first, ".") {
+				kept[name] = true
+				continue
+			}
+			if name == "." {
+				dot = true
+			} else {
+				packages[name] = path
+			}
+			dropped = append(dropped, spec)
+		}
+		if len(dropped) == len(gen.Specs) {
+			edits = append(edits, sourceEdit{start: offset(gen.Pos()), end: offset(gen.End())})
+			continue
+		}
+		for _, spec := range dropped {
+			edits = append(edits, sourceEdit{start: offset(spec.Pos()), end: offset(spec.End())})
+		}
+	}
+
+	// What the code uses of the dropped packages
+	funcs := make(map[string]bool)
+	if dot {
+		for _, ident := range f.Unresolved {
+			if !kept[ident.Name] && packages[ident.Name] == "" && types.Universe.Lookup(ident.Name) == nil {
+				funcs[ident.Name] = true
+			}
+		}
+	}
+	selected := make(map[string]map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil && packages[x.Name] != "" {
+			if selected[x.Name] == nil {
+				selected[x.Name] = make(map[string]bool)
+			}
+			selected[x.Name][sel.Sel.Name] = true
+		}
+		return true
+	})
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		code = code[:e.start] + e.text + code[e.end:]
+	}
+
+	var b bytes.Buffer
+	b.WriteString(standaloneHeader)
+	b.WriteString(strings.TrimRight(code, "\n"))
+	b.WriteString("\n\n// Stubs standing in for the packages the component uses\n")
+	b.WriteString("\ntype Node interface{}\n")
+	for _, name := range sortedKeys(funcs) {
+		if name != "Node" {
+			fmt.Fprintf(&b, "\nfunc %s(...Node) Node { return nil }\n", name)
+		}
+	}
+	for _, pkg := range sortedKeys(packages) {
+		fmt.Fprintf(&b, "\n// %s stands in for %s\nvar %s = struct {\n", pkg, packages[pkg], pkg)
+		names := sortedKeys(selected[pkg])
+		for _, name := range names {
+			fmt.Fprintf(&b, "\t%s func(...Node) Node\n", name)
+		}
+		b.WriteString("}{\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "\t%s: func(...Node) Node { return nil },\n", name)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String(), nil
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
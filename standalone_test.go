@@ -0,0 +1,81 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestStandalone(t *testing.T) {
+	input := `<!DOCTYPE html><html><head><title>Home</title></head><body>` +
+		`<div class="card" hx-get="/items" x-data="{open: false}"><input type="text" disabled>` +
+		`<img src="/a.png" width="64"></div></body></html>`
+	patches := []Patch{{Selector: "img", Attrs: map[string]string{"width": "size"}}}
+
+	converter := NewConverterWithOptions(Options{HTMX: true, Alpine: true, Standalone: true, Parameterize: true, Patches: patches})
+	result, err := converter.Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	expected := []string{
+		"NOT FOR PRODUCTION",
+		"type Node interface{}",
+		"func Div(...Node) Node { return nil }",
+		"var htmx = struct {\n\tHxGet func(...Node) Node\n}",
+		`"strconv"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "github.com/plainkit/html\"") {
+		t.Errorf("Expected the Plain imports to be dropped.\nOutput:\n%s", result)
+	}
+
+	// The file compiles with nothing but the standard library
+	checkStandalone(t, result)
+
+	if _, err := NewConverterWithOptions(Options{Standalone: true, Qualified: true}).Convert(input); err == nil {
+		t.Error("Expected standalone and qualified output to be exclusive")
+	}
+}
+
+func TestStandaloneHelpers(t *testing.T) {
+	// The generated helpers spread []Node into the stubs
+	input := `<div><details><summary>Q1</summary><p>A1</p></details><details><summary>Q2</summary><p>A2</p></details>` +
+		`<nav aria-label="breadcrumb"><ol><li><a href="/">Home</a></li><li>Docs</li></ol></nav>` +
+		`<nav class="pagination"><ul><li><a href="/posts?p=1">1</a></li>` +
+		`<li aria-current="page"><span>2</span></li><li><a href="/posts?p=3">3</a></li></ul></nav>` +
+		`<form id="signup"><input name="first-name"><input name="first_name"></form></div>`
+
+	result, err := NewConverterWithOptions(Options{Standalone: true, Parameterize: true, FormStructs: true}).Convert(input)
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	for _, exp := range []string{"Fragment(nodes...)", "Fragment(crumbs...)", "Fragment(pages...)"} {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain %q.\nOutput:\n%s", exp, result)
+		}
+	}
+	checkStandalone(t, result)
+}
+
+// checkStandalone type-checks standalone output against the standard library
+func checkStandalone(t *testing.T, result string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "standalone.go", result, 0)
+	if err != nil {
+		t.Fatalf("Standalone output does not parse: %v\nOutput:\n%s", err, result)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("main", fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("Standalone output does not compile: %v\nOutput:\n%s", err, result)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// stripPresets are named attribute lists accepted by --strip. A trailing *
+// matches any attribute starting with the rest of the pattern.
+var stripPresets = map[string][]string{
+	"testing":   {"data-test", "data-test-*", "data-testid", "data-qa", "data-qa-*", "data-cy", "data-e2e", "data-automation-id"},
+	"analytics": {"data-gtm", "data-gtm-*", "data-ga", "data-ga-*", "data-track", "data-track-*", "data-tracking-*", "data-analytics-*", "data-ph-*", "data-event-*"},
+}
+
+// expandStripPatterns replaces preset names in patterns with the attributes they stand for
+func expandStripPatterns(patterns []string) []string {
+	var expanded []string
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if preset, ok := stripPresets[p]; ok {
+			expanded = append(expanded, preset...)
+		} else if p != "" {
+			expanded = append(expanded, p)
+		}
+	}
+	return expanded
+}
+
+// matchesStripPattern reports whether an attribute is named by one of the patterns
+func matchesStripPattern(key string, patterns []string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == p {
+			return true
+		}
+	}
+	return false
+}
+
+// stripAttributes removes the attributes named by the StripAttributes patterns
+func (c *Converter) stripAttributes(nodes []*html.Node) {
+	patterns := expandStripPatterns(c.opts.StripAttributes)
+	if len(patterns) == 0 {
+		return
+	}
+
+	found := make(map[string]int)
+	forEachElement(nodes, func(n *html.Node) {
+		kept := n.Attr[:0]
+		for _, attr := range n.Attr {
+			if matchesStripPattern(attr.Key, patterns) {
+				found[attr.Key]++
+				continue
+			}
+			kept = append(kept, attr)
+		}
+		n.Attr = kept
+	})
+	if len(found) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(found))
+	total := 0
+	for key, count := range found {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Strings(keys)
+	c.report(nil, SeverityInfo, "attribute-stripped", "stripped %d attributes: %s", total, strings.Join(keys, ", "))
+}
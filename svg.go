@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// svgGlobalAttrs are the attributes of SVG elements that mean the same as on
+// HTML elements, and are converted like them
+var svgGlobalAttrs = map[string]bool{
+	"id":       true,
+	"class":    true,
+	"style":    true,
+	"role":     true,
+	"tabindex": true,
+	"lang":     true,
+}
+
+// isSVG reports whether an element belongs to inline SVG markup, foreignObject
+// content excepted
+func isSVG(n *html.Node) bool {
+	return n != nil && n.Type == html.ElementNode && n.Namespace == "svg"
+}
+
+// svgAttrKey returns the name of an attribute as written in the source. The
+// parser keeps the prefix of xlink:href, xml:space and xmlns:xlink apart.
+func svgAttrKey(attr html.Attribute) string {
+	if attr.Namespace == "" {
+		return attr.Key
+	}
+	return attr.Namespace + ":" + attr.Key
+}
+
+// svgAttribute converts an attribute of an SVG element. SVG attributes such
+// as viewBox, stroke-width or d have no Plain function, and those sharing the
+// name of an HTML attribute, such as width or href, have a different meaning,
+// so they are set with Custom under their case-sensitive name. Global
+// attributes, data-, aria-, event and framework attributes are converted as
+// on HTML elements.
+func (c *Converter) svgAttribute(n *html.Node, attr html.Attribute) (string, bool) {
+	if !isSVG(n) {
+		return "", false
+	}
+	key := svgAttrKey(attr)
+	if code, ok := c.attributeOverride(key, attr.Val, n.Data); ok {
+		return code, true
+	}
+	if attr.Namespace == "" && (svgGlobalAttrs[key] || strings.HasPrefix(key, "data-") || strings.HasPrefix(key, "aria-") ||
+		strings.HasPrefix(key, "hx-") || strings.HasPrefix(key, "x-") || strings.HasPrefix(key, "@") ||
+		strings.HasPrefix(key, ":") || isEventHandlerAttr(key)) {
+		return "", false
+	}
+	return fmt.Sprintf("Custom(%s, %s)", c.quoteValue(key), c.attrValue(key, attr.Val)), true
+}
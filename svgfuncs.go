@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// svgIconSize is the largest width or height, in user units or pixels, of an
+// SVG named as an icon rather than an illustration
+const svgIconSize = 64
+
+// hoistsSVG reports whether an svg element becomes a function of its own
+func (c *Converter) hoistsSVG(n *html.Node) bool {
+	if !c.opts.HoistSVG || n.Data != "svg" {
+		return false
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "svg" {
+			return false
+		}
+	}
+	return true
+}
+
+// hoistSVG moves an svg element into its own function and returns the call
+func (c *Converter) hoistSVG(n *html.Node) string {
+	// Functions extracted while converting the svg follow its own
+	decl := len(c.funcs)
+	call := c.extractFunc(svgFuncName(n), func() string {
+		return c.convertElement(n, 1)
+	})
+	c.svgFuncs = append(c.svgFuncs, c.funcs[decl])
+	return call
+}
+
+// svgFuncName names the function of an svg: IconLogo for small graphics,
+// IllustrationHero for larger ones, after the svg or its nearest named ancestor
+func svgFuncName(n *html.Node) string {
+	kind := "Illustration"
+	if size := svgSize(n); size > 0 && size <= svgIconSize {
+		kind = "Icon"
+	}
+
+	name := attrValue(n, "id")
+	if name == "" {
+		name = attrValue(n, "aria-label")
+	}
+	if title := childElement(n, "title"); name == "" && title != nil {
+		name = strings.TrimSpace(textContent(title))
+	}
+	if name == "" {
+		name = inlineIconName(n)
+		if name == "icon" {
+			name = ""
+		}
+	}
+	if name == "" {
+		name = svgClassName(n)
+	}
+	for p := n.Parent; name == "" && p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if name = attrValue(p, "id"); name == "" {
+			if classes := strings.Fields(attrValue(p, "class")); len(classes) > 0 {
+				name = classes[0]
+			}
+		}
+	}
+	if name == "" {
+		return kind
+	}
+	return kind + goIdentifier(name, true)
+}
+
+// svgKindWords name the kind of graphic in the classes of an svg
+var svgKindWords = map[string]bool{"icon": true, "illustration": true, "svg": true}
+
+// svgClassName returns the words of the first class of an svg naming its kind
+// besides the kind itself: "hero" for hero-illustration. Classes without a
+// kind word, such as utility classes, do not name the svg.
+func svgClassName(n *html.Node) string {
+	for _, class := range strings.Fields(attrValue(n, "class")) {
+		words := strings.FieldsFunc(strings.ToLower(class), func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		})
+		var rest []string
+		for _, word := range words {
+			if !svgKindWords[word] {
+				rest = append(rest, word)
+			}
+		}
+		if len(rest) > 0 && len(rest) < len(words) {
+			return strings.Join(rest, " ")
+		}
+	}
+	return ""
+}
+
+// svgSize returns the larger dimension of an svg, from its viewBox or its
+// width and height, or 0 when it has none
+func svgSize(n *html.Node) float64 {
+	var dims []string
+	if box := strings.Fields(strings.ReplaceAll(attrValue(n, "viewBox"), ",", " ")); len(box) == 4 {
+		dims = box[2:]
+	} else {
+		dims = []string{attrValue(n, "width"), attrValue(n, "height")}
+	}
+	size := 0.0
+	for _, d := range dims {
+		if v, err := strconv.ParseFloat(strings.TrimSuffix(d, "px"), 64); err == nil && v > size {
+			size = v
+		}
+	}
+	return size
+}
+
+// SVGCode returns a file holding the functions hoisted from inline SVGs when
+// they are generated separately, or "" when there are none
+func (c *Converter) SVGCode() string {
+	if !c.opts.SeparateSVG || len(c.svgFuncs) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n\t. \"github.com/plainkit/html\"\n)\n", c.packageName())
+	for _, f := range c.svgFuncs {
+		buf.WriteString("\n")
+		f.write(&buf, false)
+	}
+	return c.formatCode(c.qualifyOutput(buf.String()))
+}
+
+// isSeparateSVG reports whether a function is written to the SVG file instead of the main output
+func (c *Converter) isSeparateSVG(f *funcDecl) bool {
+	if !c.opts.SeparateSVG {
+		return false
+	}
+	for _, svg := range c.svgFuncs {
+		if svg == f {
+			return true
+		}
+	}
+	return false
+}
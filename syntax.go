@@ -0,0 +1,77 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// inputSyntax identifies the markup dialect of an input
+type inputSyntax int
+
+const (
+	syntaxHTML inputSyntax = iota
+	syntaxJSX
+	// syntaxMIME is an email or MHTML archive whose HTML part is converted
+	syntaxMIME
+)
+
+// detectSyntax determines the markup dialect from a file name
+func detectSyntax(filename string) inputSyntax {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsx", ".tsx":
+		return syntaxJSX
+	case ".eml", ".mhtml", ".mht":
+		return syntaxMIME
+	}
+	return syntaxHTML
+}
+
+// funcNameFromFilename derives an exported function name from a file name,
+// e.g. "login-form.html" becomes "LoginForm". Names the dot-imported Plain
+// package already declares get a suffix, so "footer.html" becomes
+// "FooterComponent".
+func funcNameFromFilename(filename string) string {
+	base := filepath.Base(filename)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if base == "" || base == "." || base == "stdin" {
+		return ""
+	}
+	name := goIdentifier(base, true)
+	if plainName(name) {
+		name += "Component"
+	}
+	return name
+}
+
+var (
+	jsxComment      = regexp.MustCompile(`\{\s*/\*.*?\*/\s*\}`)
+	jsxStringAttr   = regexp.MustCompile(`=\{\s*(?:"([^"]*)"|'([^']*)')\s*\}`)
+	jsxExprAttr     = regexp.MustCompile(`=\{([^{}]*)\}`)
+	jsxRenamedAttrs = map[string]string{
+		"className": "class",
+		"htmlFor":   "for",
+		"tabIndex":  "tabindex",
+		"readOnly":  "readonly",
+		"autoFocus": "autofocus",
+	}
+	jsxAttrName = regexp.MustCompile(`(\s)(className|htmlFor|tabIndex|readOnly|autoFocus)=`)
+)
+
+// normalizeJSX rewrites JSX markup into HTML the parser understands. String
+// literal attributes are unwrapped and JSX attribute names are renamed; other
+// expressions are kept verbatim as attribute values and reported.
+func (c *Converter) normalizeJSX(src string) string {
+	src = jsxComment.ReplaceAllString(src, "")
+	src = jsxAttrName.ReplaceAllStringFunc(src, func(m string) string {
+		name := strings.TrimSuffix(m[1:], "=")
+		return m[:1] + jsxRenamedAttrs[name] + "="
+	})
+	src = jsxStringAttr.ReplaceAllString(src, `="$1$2"`)
+	src = jsxExprAttr.ReplaceAllStringFunc(src, func(m string) string {
+		expr := strings.TrimSpace(m[2 : len(m)-1])
+		c.report(nil, SeverityWarning, "jsx-expression", "JSX expression {%s} is kept as a literal attribute value", expr)
+		return `="` + strings.ReplaceAll(expr, `"`, "&quot;") + `"`
+	})
+	return src
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Text modes select how text nodes are converted
+const (
+	// TextTrim trims surrounding whitespace and drops whitespace-only text
+	TextTrim = "trim"
+	// TextCollapse collapses whitespace runs into single spaces, keeping the
+	// spaces that separate inline content
+	TextCollapse = "collapse"
+	// TextVerbatim keeps text exactly as written
+	TextVerbatim = "verbatim"
+)
+
+// inlineElements are the elements that flow with the surrounding text
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "br": true, "button": true,
+	"cite": true, "code": true, "data": true, "del": true, "dfn": true, "em": true, "i": true,
+	"img": true, "input": true, "ins": true, "kbd": true, "label": true, "mark": true, "q": true,
+	"s": true, "samp": true, "select": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "textarea": true, "time": true, "u": true, "var": true,
+}
+
+// validateTextMode checks a text mode name
+func validateTextMode(mode string) error {
+	switch mode {
+	case "", TextTrim, TextCollapse, TextVerbatim:
+		return nil
+	}
+	return fmt.Errorf("unknown text mode %q (available: trim, collapse, verbatim)", mode)
+}
+
+// preformattedElements are the elements whose text is rendered with its
+// whitespace, or submitted with it for textarea
+var preformattedElements = map[string]bool{
+	"pre":      true,
+	"textarea": true,
+	"code":     true,
+	"listing":  true,
+}
+
+// preformatted reports whether a node is inside an element that keeps its
+// whitespace
+func preformatted(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && preformattedElements[p.Data] {
+			return true
+		}
+	}
+	return false
+}
+
+// textMode returns the text mode for a node: the one of the nearest patched
+// ancestor, verbatim inside preformatted elements, or Options.TextMode
+func (c *Converter) textMode(n *html.Node) string {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if patch, ok := c.patches[p]; ok && patch.TextMode != "" {
+			return patch.TextMode
+		}
+	}
+	if preformatted(n) {
+		return TextVerbatim
+	}
+	if c.opts.TextMode == "" {
+		return TextTrim
+	}
+	return c.opts.TextMode
+}
+
+// nodeText returns the text to emit for a text node, and false if the node
+// produces no code
+func (c *Converter) nodeText(n *html.Node) (string, bool) {
+	switch c.textMode(n) {
+	case TextVerbatim:
+		return n.Data, n.Data != ""
+	case TextCollapse:
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			// Whitespace only matters between two pieces of inline content
+			if n.Data != "" && flowsInline(n.PrevSibling) && flowsInline(n.NextSibling) {
+				return " ", true
+			}
+			return "", false
+		}
+		if startsWithSpace(n.Data) && n.PrevSibling != nil {
+			text = " " + text
+		}
+		if endsWithSpace(n.Data) && n.NextSibling != nil {
+			text += " "
+		}
+		return text, true
+	default:
+		// Spaces next to inline elements separate words, as in
+		// "Hello <strong>you</strong> there", and are kept as one
+		text := strings.TrimSpace(n.Data)
+		if text == "" {
+			if n.Data != "" && spacedInline(n.PrevSibling) && spacedInline(n.NextSibling) {
+				return " ", true
+			}
+			return "", false
+		}
+		if startsWithSpace(n.Data) && spacedInline(n.PrevSibling) {
+			text = " " + text
+		}
+		if endsWithSpace(n.Data) && spacedInline(n.NextSibling) {
+			text += " "
+		}
+		return text, true
+	}
+}
+
+// spacedInline reports whether a sibling is an inline element that spaces
+// around it separate from text; a space after a line break is not rendered
+func spacedInline(n *html.Node) bool {
+	return n != nil && n.Type == html.ElementNode && inlineElements[n.Data] && n.Data != "br"
+}
+
+// flowsInline reports whether a sibling is text or an inline element
+func flowsInline(n *html.Node) bool {
+	if n == nil {
+		return false
+	}
+	return n.Type == html.TextNode || n.Type == html.ElementNode && inlineElements[n.Data]
+}
+
+// startsWithSpace reports whether s starts with HTML whitespace
+func startsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\n\r\f", rune(s[0]))
+}
+
+// endsWithSpace reports whether s ends with HTML whitespace
+func endsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\n\r\f", rune(s[len(s)-1]))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// tocEntry is a heading listed in the table of contents, with the headings
+// nested below it
+type tocEntry struct {
+	level    int
+	id       string
+	text     string
+	children []*tocEntry
+}
+
+// buildTOC declares a TableOfContents helper with Options.TOC, linking to
+// the headings of the input nested by level. Headings without an id get one
+// from their text. A heading that is alone at the highest level, the title of
+// the page, is left out, as are the headings of navigation.
+func (c *Converter) buildTOC(nodes []*html.Node) {
+	if !c.opts.TOC {
+		return
+	}
+
+	var headings []*html.Node
+	top, topCount := 7, 0
+	forEachElement(nodes, func(n *html.Node) {
+		level := headingLevel(n)
+		if level == 0 || insideNav(n) || strings.TrimSpace(textContent(n)) == "" {
+			return
+		}
+		headings = append(headings, n)
+		switch {
+		case level < top:
+			top, topCount = level, 1
+		case level == top:
+			topCount++
+		}
+	})
+	if topCount == 1 && len(headings) > 1 {
+		var rest []*html.Node
+		for _, n := range headings {
+			if headingLevel(n) != top {
+				rest = append(rest, n)
+			}
+		}
+		headings = rest
+	}
+	if len(headings) == 0 {
+		c.report(nil, SeverityInfo, "toc-empty", "no headings to list in the table of contents")
+		return
+	}
+
+	if added := c.headingIDs(nodes, headings); added > 0 {
+		c.report(nil, SeverityInfo, "heading-id", "added ids to %d headings for the table of contents", added)
+	}
+
+	// Entries are nested below the closest preceding heading of a higher level
+	root := &tocEntry{}
+	stack := []*tocEntry{root}
+	for _, n := range headings {
+		entry := &tocEntry{
+			level: headingLevel(n),
+			id:    attrValue(n, "id"),
+			text:  strings.Join(strings.Fields(textContent(n)), " "),
+		}
+		for len(stack) > 1 && stack[len(stack)-1].level >= entry.level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, entry)
+		stack = append(stack, entry)
+	}
+
+	name := c.uniqueFuncName(withExport("TableOfContents", !c.opts.Unexported))
+	body := fmt.Sprintf("Nav(Class(\"toc\"), Aria(\"label\", \"Table of contents\"), %s)", c.tocList(root.children))
+	c.funcs = append(c.funcs, &funcDecl{name: name, result: "Node", body: body})
+}
+
+// tocList converts entries into a list of links, nesting their children
+func (c *Converter) tocList(entries []*tocEntry) string {
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		link := fmt.Sprintf("A(Href(%s), T(%s))", c.quoteValue("#"+entry.id), c.quoteValue(entry.text))
+		if len(entry.children) > 0 {
+			items[i] = fmt.Sprintf("Li(%s, %s)", link, c.tocList(entry.children))
+		} else {
+			items[i] = fmt.Sprintf("Li(%s)", link)
+		}
+	}
+	return fmt.Sprintf("Ul(\n%s,\n)", strings.Join(items, ",\n"))
+}
+
+// insideNav reports whether n has a nav ancestor
+func insideNav(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "nav" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// TypedAttr maps an attribute value to a typed helper call, such as
+// int -> views.DataUserId(%d)
+type TypedAttr struct {
+	// Type is one of string, int, float or bool
+	Type string
+	// Format is a fmt format with a single verb receiving the parsed value
+	Format string
+}
+
+// parseTypedAttr parses a mapping of the form "type -> Format(%verb)"
+func parseTypedAttr(spec string) (TypedAttr, error) {
+	parts := strings.SplitN(spec, "->", 2)
+	if len(parts) != 2 {
+		return TypedAttr{}, fmt.Errorf("invalid mapping %q: expected \"type -> Func(%%v)\"", spec)
+	}
+	mapping := TypedAttr{Type: strings.TrimSpace(parts[0]), Format: strings.TrimSpace(parts[1])}
+	switch mapping.Type {
+	case "string", "int", "float", "bool":
+	default:
+		return TypedAttr{}, fmt.Errorf("invalid mapping %q: unknown type %q", spec, mapping.Type)
+	}
+	verbs := formatVerbs(mapping.Format)
+	if len(verbs) != 1 {
+		return TypedAttr{}, fmt.Errorf("invalid mapping %q: format needs exactly one verb", spec)
+	}
+	if !strings.ContainsRune(typedVerbs[mapping.Type], verbs[0]) {
+		return TypedAttr{}, fmt.Errorf("invalid mapping %q: %%%c cannot format %s values; use one of %s", spec, verbs[0], mapping.Type, verbList(typedVerbs[mapping.Type]))
+	}
+	return mapping, nil
+}
+
+// typedVerbs lists the verbs that format each mapping type as Go source
+var typedVerbs = map[string]string{
+	"string": "sqv",
+	"int":    "dv",
+	"float":  "feEgGv",
+	"bool":   "tv",
+}
+
+// formatVerbs returns the verbs of a fmt format, skipping flags, width,
+// precision and escaped percent signs
+func formatVerbs(format string) []rune {
+	var verbs []rune
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && format[i] != '%' {
+			verbs = append(verbs, rune(format[i]))
+		}
+	}
+	return verbs
+}
+
+// verbList renders verbs as a readable list, such as %d or %v
+func verbList(verbs string) string {
+	parts := make([]string, len(verbs))
+	for i, v := range verbs {
+		parts[i] = "%" + string(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// typedValue parses an attribute value according to the mapping type
+func typedValue(mapping TypedAttr, val string) (any, error) {
+	switch mapping.Type {
+	case "int":
+		return strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+	case "float":
+		return strconv.ParseFloat(strings.TrimSpace(val), 64)
+	case "bool":
+		return strconv.ParseBool(strings.TrimSpace(val))
+	}
+	return val, nil
+}
+
+// typedAttr renders an attribute value through a typed mapping
+func (c *Converter) typedAttr(mapping TypedAttr, val string) (string, error) {
+	arg, err := typedValue(mapping, val)
+	if err != nil {
+		return "", err
+	}
+	// %q quotes the value itself
+	if verbs := formatVerbs(mapping.Format); mapping.Type == "string" && (len(verbs) != 1 || verbs[0] != 'q') {
+		arg = c.quoteValue(val)
+	}
+
+	code := fmt.Sprintf(mapping.Format, arg)
+	c.useQualifier(code)
+	return code, nil
+}
+
+// qualifierPattern matches the package qualifier at the start of a call expression
+var qualifierPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+// useQualifier marks the configured import referenced by an expression as used
+func (c *Converter) useQualifier(code string) {
+	m := qualifierPattern.FindStringSubmatch(code)
+	if m == nil {
+		return
+	}
+	for _, imp := range c.opts.Imports {
+		if importName(imp) == m[1] {
+			c.imports[imp] = true
+		}
+	}
+}
+
+// importName returns the identifier an import is referenced by
+func importName(imp string) string {
+	if alias, _, ok := strings.Cut(imp, " "); ok {
+		return alias
+	}
+	path := strings.Trim(imp, `"`)
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// formatImport renders an import spec line, quoting the path
+func formatImport(imp string) string {
+	if alias, path, ok := strings.Cut(imp, " "); ok {
+		return alias + " " + strconv.Quote(strings.Trim(strings.TrimSpace(path), `"`))
+	}
+	return strconv.Quote(strings.Trim(imp, `"`))
+}
+
+// checkTypedAttrs reports attribute values that do not match their typed mapping
+func (c *Converter) checkTypedAttrs(nodes []*html.Node) {
+	if len(c.opts.DataAttributes) == 0 {
+		return
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				mapping, ok := c.opts.DataAttributes[attr.Key]
+				if !ok {
+					continue
+				}
+				if _, err := typedValue(mapping, attr.Val); err != nil {
+					c.report(n, SeverityWarning, "typed-attr-mismatch", "%s=%q is not a valid %s; falling back to Data()", attr.Key, attr.Val, mapping.Type)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// voidElements never have content or an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "keygen": true, "link": true, "meta": true, "param": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// paragraphClosers are the elements whose start tag implicitly closes an open p
+var paragraphClosers = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true,
+	"dialog": true, "div": true, "dl": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hgroup": true, "hr": true,
+	"main": true, "menu": true, "nav": true, "ol": true, "pre": true, "search": true,
+	"section": true, "table": true, "ul": true,
+}
+
+// requiredParents lists the elements that must be direct children of one of the given parents
+var requiredParents = map[string][]string{
+	"li":       {"ul", "ol", "menu"},
+	"dt":       {"dl", "div"},
+	"dd":       {"dl", "div"},
+	"tr":       {"table", "thead", "tbody", "tfoot"},
+	"td":       {"tr"},
+	"th":       {"tr"},
+	"thead":    {"table"},
+	"tbody":    {"table"},
+	"tfoot":    {"table"},
+	"caption":  {"table"},
+	"colgroup": {"table"},
+	"option":   {"select", "datalist", "optgroup"},
+	"optgroup": {"select"},
+	"summary":  {"details"},
+	"legend":   {"fieldset"},
+}
+
+// impliedEnds lists, for an element, the open elements its start tag implicitly closes
+var impliedEnds = map[string][]string{
+	"p":        {"p"},
+	"li":       {"li"},
+	"dt":       {"dt", "dd"},
+	"dd":       {"dt", "dd"},
+	"tr":       {"tr", "td", "th"},
+	"td":       {"td", "th"},
+	"th":       {"td", "th"},
+	"option":   {"option"},
+	"optgroup": {"optgroup", "option"},
+}
+
+// uniqueElements may only appear once per document
+var uniqueElements = map[string]bool{"html": true, "head": true, "body": true, "title": true, "main": true}
+
+// noSelfNesting are the elements that must not contain themselves
+var noSelfNesting = map[string]bool{"a": true, "button": true, "form": true, "label": true}
+
+// validateStructure tokenizes the source and reports nesting problems that the
+// HTML parser would silently repair, with their line numbers
+func (c *Converter) validateStructure(src string) {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var stack []string
+	seen := make(map[string]int)
+	line := 1
+
+	contains := func(tag string) int {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == tag {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				c.reportLine(line, SeverityWarning, "tokenize-error", "%v", z.Err())
+			}
+			return
+		}
+		tokenLine := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+
+		name, _ := z.TagName()
+		tag := string(name)
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if contains("svg") >= 0 || contains("math") >= 0 {
+				if tt == html.StartTagToken {
+					stack = append(stack, tag)
+				}
+				continue
+			}
+
+			if uniqueElements[tag] {
+				seen[tag]++
+				if seen[tag] == 2 {
+					c.reportLine(tokenLine, SeverityWarning, "duplicate-element", "duplicate <%s>; a document may only have one", tag)
+				}
+			}
+			if noSelfNesting[tag] && contains(tag) >= 0 {
+				c.reportLine(tokenLine, SeverityWarning, "invalid-nesting", "<%s> inside <%s> is not allowed; the parser closes the outer one", tag, tag)
+			}
+			if paragraphClosers[tag] {
+				if i := contains("p"); i >= 0 {
+					c.reportLine(tokenLine, SeverityWarning, "invalid-nesting", "<%s> inside <p> closes the paragraph early", tag)
+					stack = stack[:i]
+				}
+			}
+			for len(stack) > 0 && containsString(impliedEnds[tag], stack[len(stack)-1]) {
+				stack = stack[:len(stack)-1]
+			}
+			if parents, ok := requiredParents[tag]; ok {
+				parent := ""
+				if len(stack) > 0 {
+					parent = stack[len(stack)-1]
+				}
+				if !containsString(parents, parent) && parent != "template" {
+					where := "at the top level"
+					if parent != "" {
+						where = "inside <" + parent + ">"
+					}
+					c.reportLine(tokenLine, SeverityWarning, "invalid-nesting", "<%s> %s; expected a parent %s", tag, where, formatTags(parents))
+				}
+			}
+
+			if tt == html.StartTagToken && !voidElements[tag] {
+				stack = append(stack, tag)
+			}
+		case html.EndTagToken:
+			if i := contains(tag); i >= 0 {
+				stack = stack[:i]
+			}
+		}
+	}
+}
+
+// formatTags formats tag names as "<a>, <b> or <c>"
+func formatTags(tags []string) string {
+	quoted := make([]string, len(tags))
+	for i, tag := range tags {
+		quoted[i] = "<" + tag + ">"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
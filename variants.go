@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// variantWords are class fragments that name a design-system variant
+var variantWords = []string{
+	"primary", "secondary", "tertiary", "destructive", "danger", "success", "warning",
+	"info", "outline", "ghost", "link", "muted", "accent", "light", "dark",
+	"default", "subtle", "solid", "soft",
+	"xs", "sm", "md", "lg", "xl",
+}
+
+// classBundle is a class attribute value repeated on elements of one tag
+type classBundle struct {
+	value   string
+	classes []string
+	count   int
+}
+
+// variantsDecl is a variants map holding the class bundles of one tag
+type variantsDecl struct {
+	name     string
+	variants []variantEntry
+}
+
+// variantEntry is a named class bundle in a variants map
+type variantEntry struct {
+	key   string
+	value string
+}
+
+// collectVariants finds class bundles repeated at least Options.ClassVariants
+// times. A tag with one repeated bundle gets a class constant, a tag with
+// several gets a variants map keyed by what distinguishes each bundle.
+func (c *Converter) collectVariants(nodes []*html.Node) {
+	c.variants = nil
+	if c.opts.ClassVariants <= 0 {
+		return
+	}
+
+	bundles := make(map[string]*classBundle)
+	tags := make(map[string]map[string]int)
+	var order []string
+	forEachElement(nodes, func(n *html.Node) {
+		classes := strings.Fields(attrValue(n, "class"))
+		if len(classes) == 0 {
+			return
+		}
+		value := attrValue(n, "class")
+		bundle, ok := bundles[value]
+		if !ok {
+			bundle = &classBundle{value: value, classes: classes}
+			bundles[value] = bundle
+			tags[value] = make(map[string]int)
+			order = append(order, value)
+		}
+		bundle.count++
+		tags[value][n.Data]++
+	})
+
+	// Each bundle belongs to the tag it is used on most
+	byTag := make(map[string][]*classBundle)
+	var tagOrder []string
+	for _, value := range order {
+		bundle := bundles[value]
+		if bundle.count < c.opts.ClassVariants {
+			continue
+		}
+		tag := mostUsedTag(tags[value])
+		if len(byTag[tag]) == 0 {
+			tagOrder = append(tagOrder, tag)
+		}
+		byTag[tag] = append(byTag[tag], bundle)
+	}
+
+	taken := make(map[string]bool)
+	for _, decl := range c.consts {
+		taken[decl.name] = true
+	}
+	uniqueName := func(base string) string {
+		name := base
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+		taken[name] = true
+		return name
+	}
+
+	hoisted := make(map[string]bool)
+	for _, tag := range tagOrder {
+		group := byTag[tag]
+		if len(group) == 1 {
+			name := uniqueName(goIdentifier(tag+" class", false))
+			c.constNames[constKey{"class", group[0].value}] = name
+			c.consts = append(c.consts, constDecl{name: name, value: group[0].value})
+			hoisted[group[0].value] = true
+			continue
+		}
+
+		decl := variantsDecl{name: uniqueName(goIdentifier(tag+" variants", false))}
+		keys := variantKeys(group)
+		for i, bundle := range group {
+			decl.variants = append(decl.variants, variantEntry{key: keys[i], value: bundle.value})
+			c.constNames[constKey{"class", bundle.value}] = fmt.Sprintf("%s[%s]", decl.name, c.quoteValue(keys[i]))
+			hoisted[bundle.value] = true
+		}
+		c.variants = append(c.variants, decl)
+	}
+
+	// Bundles take precedence over constants hoisted for the same value, so
+	// the other attributes sharing a dropped constant go back to literals
+	kept := c.consts[:0]
+	dropped := make(map[string]bool)
+	for _, decl := range c.consts {
+		if hoisted[decl.value] && c.constNames[constKey{"class", decl.value}] != decl.name {
+			dropped[decl.name] = true
+			continue
+		}
+		kept = append(kept, decl)
+	}
+	c.consts = kept
+	for key, name := range c.constNames {
+		if dropped[name] {
+			delete(c.constNames, key)
+		}
+	}
+}
+
+// mostUsedTag returns the tag with the highest count, preferring the first alphabetically on ties
+func mostUsedTag(counts map[string]int) string {
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	best := tags[0]
+	for _, tag := range tags[1:] {
+		if counts[tag] > counts[best] {
+			best = tag
+		}
+	}
+	return best
+}
+
+// variantKeys names each bundle of a group after the classes that set it
+// apart from the classes shared by the whole group
+func variantKeys(group []*classBundle) []string {
+	shared := make(map[string]int)
+	for _, bundle := range group {
+		seen := make(map[string]bool)
+		for _, class := range bundle.classes {
+			if !seen[class] {
+				seen[class] = true
+				shared[class]++
+			}
+		}
+	}
+
+	keys := make([]string, len(group))
+	used := make(map[string]bool)
+	for i, bundle := range group {
+		var distinct []string
+		for _, class := range bundle.classes {
+			if shared[class] < len(group) {
+				distinct = append(distinct, class)
+			}
+		}
+
+		key := variantKey(distinct)
+		base := key
+		for n := 2; used[key]; n++ {
+			key = fmt.Sprintf("%s%d", base, n)
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+// variantKey picks a name for a variant from its distinguishing classes
+func variantKey(distinct []string) string {
+	if len(distinct) == 0 {
+		return "default"
+	}
+	for _, class := range distinct {
+		// Drop modifiers such as hover: and md:
+		base := class[strings.LastIndex(class, ":")+1:]
+		for _, part := range strings.Split(base, "-") {
+			for _, word := range variantWords {
+				if part == word {
+					return word
+				}
+			}
+		}
+	}
+	return distinct[0][strings.LastIndex(distinct[0], ":")+1:]
+}
+
+// writeVariants renders the variants maps
+func (c *Converter) writeVariants(buf codeWriter) {
+	if len(c.variants) == 0 {
+		return
+	}
+	buf.WriteString("var (\n")
+	for _, decl := range c.variants {
+		fmt.Fprintf(buf, "\t%s = map[string]string{\n", decl.name)
+		for _, entry := range decl.variants {
+			fmt.Fprintf(buf, "\t\t%s: %s,\n", c.quoteValue(entry.key), c.quoteValue(entry.value))
+		}
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString(")\n\n")
+}
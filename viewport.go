@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// standardViewport is the viewport every responsive page declares
+const standardViewport = "width=device-width, initial-scale=1"
+
+// themeSchemes matches the media queries of light and dark theme-color pairs
+var themeSchemes = map[string]string{
+	"(prefers-color-scheme: light)": "light",
+	"(prefers-color-scheme: dark)":  "dark",
+}
+
+// metaName returns the lowercased name of a meta element, or "" for other elements
+func metaName(n *html.Node) string {
+	if n.Data != "meta" {
+		return ""
+	}
+	return strings.ToLower(attrValue(n, "name"))
+}
+
+// isStandardViewport reports whether a viewport content equals standardViewport,
+// ignoring spacing and a decimal initial scale
+func isStandardViewport(content string) bool {
+	var parts []string
+	for _, part := range strings.Split(content, ",") {
+		key, val, _ := strings.Cut(strings.ReplaceAll(part, " ", ""), "=")
+		if strings.EqualFold(key, "initial-scale") && val == "1.0" {
+			val = "1"
+		}
+		parts = append(parts, strings.ToLower(key)+"="+val)
+	}
+	return strings.Join(parts, ", ") == standardViewport
+}
+
+// findMetaHelpers maps the standard viewport meta and theme-color metas to
+// Viewport and ThemeColor helpers, and warns when a full page has no viewport
+func (c *Converter) findMetaHelpers(nodes []*html.Node) {
+	c.metaCalls = make(map[*html.Node]string)
+	if !c.opts.MetaHelpers {
+		return
+	}
+
+	var viewport bool
+	var themes []*html.Node
+	forEachElement(nodes, func(n *html.Node) {
+		switch metaName(n) {
+		case "viewport":
+			viewport = true
+			if c.patches[n] == nil && isStandardViewport(attrValue(n, "content")) {
+				c.metaCalls[n] = "viewport"
+			}
+		case "theme-color":
+			if c.patches[n] == nil && !c.faviconMembers[n] {
+				themes = append(themes, n)
+			}
+		}
+	})
+	if !viewport && len(nodes) == 1 && nodes[0].Data == "html" {
+		c.report(nil, SeverityWarning, "viewport-missing", "the page has no viewport meta; mobile browsers render it zoomed out. Add <meta name=\"viewport\" content=%q>", standardViewport)
+	}
+
+	// A light and dark pair becomes a single call
+	schemes := make(map[string]*html.Node)
+	for _, n := range themes {
+		if scheme, ok := themeSchemes[strings.ToLower(strings.TrimSpace(attrValue(n, "media")))]; ok && schemes[scheme] == nil {
+			schemes[scheme] = n
+		}
+	}
+	if light, dark := schemes["light"], schemes["dark"]; light != nil && dark != nil {
+		first, second := light, dark
+		if dark.Parent == light.Parent && precedes(dark, light) {
+			first, second = dark, light
+		}
+		c.metaCalls[first] = fmt.Sprintf("theme(%s, %s)", c.quoteValue(attrValue(light, "content")), c.quoteValue(attrValue(dark, "content")))
+		c.metaCalls[second] = ""
+	}
+	for _, n := range themes {
+		if _, ok := c.metaCalls[n]; !ok && !hasAttr(n, "media") {
+			c.metaCalls[n] = fmt.Sprintf("theme(%s, \"\")", c.quoteValue(attrValue(n, "content")))
+		}
+	}
+}
+
+// precedes reports whether sibling a comes before sibling b
+func precedes(a, b *html.Node) bool {
+	for n := a.NextSibling; n != nil; n = n.NextSibling {
+		if n == b {
+			return true
+		}
+	}
+	return false
+}
+
+// metaCall returns the helper call replacing a meta element, generating the
+// helper on first use. An empty call drops the second meta of a pair.
+func (c *Converter) metaCall(call string) string {
+	switch {
+	case call == "viewport":
+		if c.viewportFunc == nil {
+			c.viewportFunc = &funcDecl{name: c.uniqueFuncName("Viewport"), result: "Node"}
+			c.viewportFunc.body = c.metaCode(1, "viewport", "", standardViewport)
+			c.funcs = append(c.funcs, c.viewportFunc)
+		}
+		return c.viewportFunc.name + "()"
+	case strings.HasPrefix(call, "theme("):
+		if c.themeColorFunc == nil {
+			name := c.uniqueFuncName("ThemeColor")
+			c.themeColorFunc = &funcDecl{name: name, result: "Node", raw: fmt.Sprintf(`// %s sets the color of the browser interface, with a dark scheme
+// variant unless dark is empty
+func %s(light, dark string) Node {
+	if dark == "" {
+		return %s
+	}
+	return Fragment(
+		%s,
+		%s,
+	)
+}
+`, name, name, c.metaCode(1, "theme-color", "", "light"), c.metaCode(2, "theme-color", "(prefers-color-scheme: light)", "light"),
+				c.metaCode(2, "theme-color", "(prefers-color-scheme: dark)", "dark"))}
+			c.themeColorFunc.addParam("light", "string")
+			c.themeColorFunc.addParam("dark", "string")
+			c.funcs = append(c.funcs, c.themeColorFunc)
+		}
+		return c.themeColorFunc.name + strings.TrimPrefix(call, "theme")
+	}
+	return call
+}
+
+// metaCode converts a named meta element. Content values other than the
+// standard viewport are parameter names of the helper.
+func (c *Converter) metaCode(depth int, name, media, content string) string {
+	n := &html.Node{Type: html.ElementNode, Data: "meta", DataAtom: atom.Meta}
+	n.Attr = append(n.Attr, html.Attribute{Key: "name", Val: name})
+	if media != "" {
+		n.Attr = append(n.Attr, html.Attribute{Key: "media", Val: media})
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: "content", Val: content})
+	if content == standardViewport {
+		return c.convertElement(n, depth)
+	}
+	return c.substitute([]substitution{{value: &n.Attr[len(n.Attr)-1].Val, expr: content}}, func() string {
+		return c.convertElement(n, depth)
+	})
+}
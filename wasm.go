@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+)
+
+// main exposes the converter to the page loading the WebAssembly build as
+// plainkit.convert(html, options) and keeps running to answer its calls
+func main() {
+	js.Global().Set("plainkit", js.ValueOf(map[string]any{
+		"version": version,
+		"convert": js.FuncOf(jsConvert),
+	}))
+	select {}
+}
+
+// jsConvert converts the HTML string of its first argument with the options
+// object of the optional second one, named like the Options fields as in
+// daemon requests, e.g. {HTMX: true, Package: "views"}. It returns an object
+// like the daemon's responses, with the code or the error.
+func jsConvert(this js.Value, args []js.Value) any {
+	resp := convertJSArgs(args)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(daemonResponse{Error: fmt.Sprintf("failed to encode result: %v", err)})
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+// convertJSArgs converts the arguments of a plainkit.convert call
+func convertJSArgs(args []js.Value) daemonResponse {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return daemonResponse{Error: "convert takes the HTML to convert as a string"}
+	}
+	var opts Options
+	if len(args) > 1 && args[1].Truthy() {
+		if args[1].Type() != js.TypeObject {
+			return daemonResponse{Error: "convert takes its options as an object"}
+		}
+		options := js.Global().Get("JSON").Call("stringify", args[1]).String()
+		if err := json.Unmarshal([]byte(options), &opts); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("invalid options: %v", err)}
+		}
+	}
+	return newDaemonResponse(ConvertResult(context.Background(), args[0].String(), opts))
+}